@@ -19,18 +19,24 @@ func breakdownCmd(ctx *config.RunContext) *cobra.Command {
 
       terraform plan -out tfplan.binary
       terraform show -json tfplan.binary > plan.json
-      infracost breakdown --path plan.json`,
+      infracost breakdown --path plan.json
+
+  Show monthly costs grouped by the team tag:
+
+      infracost breakdown --path /path/to/code --group-by tag:team`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := checkAPIKey(ctx.Config.APIKey, ctx.Config.PricingAPIEndpoint, ctx.Config.DefaultPricingAPIEndpoint); err != nil {
-				return err
-			}
-
 			err := loadRunFlags(ctx.Config, cmd)
 			if err != nil {
 				return err
 			}
 
+			if !ctx.Config.IsOffline() {
+				if err := checkAPIKey(ctx.Config.APIKey, ctx.Config.PricingAPIEndpoint, ctx.Config.DefaultPricingAPIEndpoint); err != nil {
+					return err
+				}
+			}
+
 			ctx.SetContextValue("outputFormat", ctx.Config.Format)
 
 			err = checkRunConfig(ctx.Config)
@@ -45,11 +51,12 @@ func breakdownCmd(ctx *config.RunContext) *cobra.Command {
 	addRunFlags(cmd)
 
 	cmd.Flags().Bool("terraform-use-state", false, "Use Terraform state instead of generating a plan. Applicable when path is a Terraform directory")
-	cmd.Flags().String("format", "table", "Output format: json, table, html")
+	cmd.Flags().String("format", "table", "Output format: json, table, html, markdown, csv, junit, sarif")
 	cmd.Flags().StringSlice("fields", []string{"monthlyQuantity", "unit", "monthlyCost"}, "Comma separated list of output fields: price,monthlyQuantity,unit,hourlyCost,monthlyCost.\nSupported by table and html output formats")
+	cmd.Flags().String("group-by", "", "Show monthly costs grouped by: type, provider, module or tag:<key>, e.g. tag:team.\nSupported by table, html and json output formats")
 
 	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"table", "json", "html"}, cobra.ShellCompDirectiveDefault
+		return []string{"table", "json", "html", "markdown", "csv", "junit", "sarif"}, cobra.ShellCompDirectiveDefault
 	})
 
 	return cmd
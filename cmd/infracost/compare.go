@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func compareCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Show diff of monthly costs between two Infracost JSON files",
+		Long:  "Show diff of monthly costs between two Infracost JSON files, matching projects by name and resources by address",
+		Example: `  Compare the breakdowns of two branches:
+
+      git checkout main
+      infracost breakdown --path /path/to/code --format json > base.json
+      git checkout my-branch
+      infracost breakdown --path /path/to/code --format json > head.json
+      infracost compare --from base.json --to head.json`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromPath, _ := cmd.Flags().GetString("from")
+			from, err := loadOutputFile(fromPath)
+			if err != nil {
+				return err
+			}
+
+			toPath, _ := cmd.Flags().GetString("to")
+			to, err := loadOutputFile(toPath)
+			if err != nil {
+				return err
+			}
+
+			compared, err := output.Compare(from, to)
+			if err != nil {
+				return err
+			}
+
+			opts := output.Options{
+				DashboardEnabled: ctx.Config.EnableDashboard,
+				NoColor:          ctx.Config.NoColor,
+			}
+			opts.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
+
+			format, _ := cmd.Flags().GetString("format")
+
+			var b []byte
+			switch strings.ToLower(format) {
+			case "json":
+				b, err = output.ToJSON(compared, opts)
+			case "markdown":
+				b, err = output.ToMarkdown(compared, opts)
+			case "sarif":
+				b, err = output.ToSARIF(compared, opts)
+			default:
+				b, err = output.ToDiff(compared, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("from", "", "Path to the Infracost JSON file to compare from, e.g. the base branch")
+	cmd.Flags().String("to", "", "Path to the Infracost JSON file to compare to, e.g. the pull request branch")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagFilename("from", "json")
+	_ = cmd.MarkFlagFilename("to", "json")
+
+	cmd.Flags().String("format", "diff", "Output format: diff, json, markdown, sarif")
+	cmd.Flags().Bool("show-skipped", false, "Show unsupported resources, some of which might be free")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"diff", "json", "markdown", "sarif"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return cmd
+}
+
+func loadOutputFile(path string) (output.Root, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return output.Root{}, errors.Wrap(err, "Error reading JSON file")
+	}
+
+	out, err := output.Load(data)
+	if err != nil {
+		return out, errors.Wrap(err, "Error parsing JSON file")
+	}
+
+	if !checkOutputVersion(out.Version) {
+		return out, fmt.Errorf("Invalid Infracost JSON file version. Supported versions are %s ≤ x ≤ %s", minOutputVersion, maxOutputVersion)
+	}
+
+	return out, nil
+}
@@ -20,18 +20,25 @@ func diffCmd(ctx *config.RunContext) *cobra.Command {
 
       terraform plan -out tfplan.binary
       terraform show -json tfplan.binary > plan.json
-      infracost diff --path plan.json`,
+      infracost diff --path plan.json
+
+  Use CloudFormation template compared to the deployed stack:
+
+      aws cloudformation get-template --stack-name my-stack > stack.json
+      infracost diff --path template.yaml --cloudformation-compare-to stack.json`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := checkAPIKey(ctx.Config.APIKey, ctx.Config.PricingAPIEndpoint, ctx.Config.DefaultPricingAPIEndpoint); err != nil {
-				return err
-			}
-
 			err := loadRunFlags(ctx.Config, cmd)
 			if err != nil {
 				return err
 			}
 
+			if !ctx.Config.IsOffline() {
+				if err := checkAPIKey(ctx.Config.APIKey, ctx.Config.PricingAPIEndpoint, ctx.Config.DefaultPricingAPIEndpoint); err != nil {
+					return err
+				}
+			}
+
 			err = checkRunConfig(ctx.Config)
 			if err != nil {
 				ui.PrintUsageErrorAndExit(cmd, err.Error())
@@ -42,14 +49,18 @@ func diffCmd(ctx *config.RunContext) *cobra.Command {
 				ui.PrintUsageErrorAndExit(cmd, err.Error())
 			}
 
-			ctx.Config.Format = "diff"
-
 			return runMain(cmd, ctx)
 		},
 	}
 
 	addRunFlags(cmd)
 
+	cmd.Flags().String("format", "diff", "Output format: diff, json, markdown, junit, sarif")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"diff", "json", "markdown", "junit", "sarif"}, cobra.ShellCompDirectiveDefault
+	})
+
 	return cmd
 }
 
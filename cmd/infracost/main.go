@@ -7,6 +7,7 @@ import (
 	"runtime/debug"
 
 	"github.com/infracost/infracost/internal/apiclient"
+	"github.com/infracost/infracost/internal/clierror"
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/ui"
 	"github.com/infracost/infracost/internal/update"
@@ -34,19 +35,30 @@ func main() {
 	}
 
 	defer func() {
+		exitCode := 0
+
 		if appErr != nil {
-			handleCLIError(ctx, appErr)
+			exitCode = 1
+
+			var exitCodeErr *clierror.ExitCodeError
+			if errors.As(appErr, &exitCodeErr) {
+				exitCode = exitCodeErr.ExitCode()
+				ui.PrintError(exitCodeErr.Error())
+			} else {
+				handleCLIError(ctx, appErr)
+			}
 		}
 
 		unexpectedErr := recover()
 		if unexpectedErr != nil {
+			exitCode = 1
 			handleUnexpectedErr(ctx, unexpectedErr)
 		}
 
 		handleUpdateMessage(updateMessageChan)
 
-		if appErr != nil || unexpectedErr != nil {
-			os.Exit(1)
+		if exitCode != 0 {
+			os.Exit(exitCode)
 		}
 	}()
 
@@ -87,6 +99,8 @@ func main() {
 	rootCmd.AddCommand(diffCmd(ctx))
 	rootCmd.AddCommand(breakdownCmd(ctx))
 	rootCmd.AddCommand(outputCmd(ctx))
+	rootCmd.AddCommand(compareCmd(ctx))
+	rootCmd.AddCommand(usageCmd(ctx))
 	rootCmd.AddCommand(completionCmd())
 
 	rootCmd.SetUsageTemplate(fmt.Sprintf(`%s{{if .Runnable}}
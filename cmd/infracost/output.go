@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/infracost/infracost/internal/clierror"
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/output"
 	"github.com/infracost/infracost/internal/ui"
@@ -14,8 +15,8 @@ import (
 	"golang.org/x/mod/semver"
 )
 
-var minOutputVersion = "0.2"
-var maxOutputVersion = "0.2"
+var minOutputVersion = "0.1"
+var maxOutputVersion = "0.4"
 
 func outputCmd(ctx *config.RunContext) *cobra.Command {
 	cmd := &cobra.Command{
@@ -30,9 +31,21 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 
       infracost output --format html --path out*.json > output.html
 
+  Create a markdown pull request comment from multiple Infracost JSON files:
+
+      infracost output --format markdown --path out*.json > comment.md
+
   Merge multiple Infracost JSON files:
 
-      infracost output --format json --path out*.json`,
+      infracost output --format json --path out*.json
+
+  Export every cost component from multiple Infracost JSON files to a spreadsheet:
+
+      infracost output --format csv --path out*.json > costs.csv
+
+  Check Infracost JSON files against the JSON Schema:
+
+      infracost output --validate --path out*.json`,
 		ValidArgs: []string{"--", "-"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inputFiles := []string{}
@@ -43,6 +56,10 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				inputFiles = append(inputFiles, matches...)
 			}
 
+			if validate, _ := cmd.Flags().GetBool("validate"); validate {
+				return validateOutputFiles(inputFiles)
+			}
+
 			inputs := make([]output.ReportInput, 0, len(inputFiles))
 			for _, f := range inputFiles {
 				data, err := ioutil.ReadFile(f)
@@ -97,13 +114,18 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				Fields:           fields,
 			}
 			opts.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
+			opts.GroupBy, _ = cmd.Flags().GetString("group-by")
+
+			if err := checkGroupBy(opts.GroupBy, format); err != nil {
+				ui.PrintUsageErrorAndExit(cmd, err.Error())
+			}
 
-			combined := output.Combine(inputs, opts)
+			combined, err := output.Combine(inputs, opts)
+			if err != nil {
+				return err
+			}
 
-			var (
-				b   []byte
-				err error
-			)
+			var b []byte
 
 			validFieldsFormats := []string{"table", "html"}
 
@@ -117,6 +139,14 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 				b, err = output.ToHTML(combined, opts)
 			case "diff":
 				b, err = output.ToDiff(combined, opts)
+			case "markdown":
+				b, err = output.ToMarkdown(combined, opts)
+			case "csv":
+				b, err = output.ToCSV(combined, opts)
+			case "junit":
+				b, err = output.ToJUnit(combined, opts)
+			case "sarif":
+				b, err = output.ToSARIF(combined, opts)
 			default:
 				b, err = output.ToTable(combined, opts)
 			}
@@ -134,17 +164,47 @@ func outputCmd(ctx *config.RunContext) *cobra.Command {
 	_ = cmd.MarkFlagRequired("path")
 	_ = cmd.MarkFlagFilename("path", "json")
 
-	cmd.Flags().String("format", "table", "Output format: json, diff, table, html")
+	cmd.Flags().String("format", "table", "Output format: json, diff, table, html, markdown, csv, junit, sarif")
 	cmd.Flags().Bool("show-skipped", false, "Show unsupported resources, some of which might be free")
 	cmd.Flags().StringSlice("fields", []string{"monthlyQuantity", "unit", "monthlyCost"}, "Comma separated list of output fields: price,monthlyQuantity,unit,hourlyCost,monthlyCost.\nSupported by table and html output formats")
+	cmd.Flags().String("group-by", "", "Show monthly costs grouped by: type, provider, module or tag:<key>, e.g. tag:team.\nSupported by table, html and json output formats")
+	cmd.Flags().Bool("validate", false, "Validate the Infracost JSON files against the JSON Schema instead of outputting them")
 
 	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"table", "json", "html"}, cobra.ShellCompDirectiveDefault
+		return []string{"table", "json", "html", "diff", "markdown", "csv", "junit", "sarif"}, cobra.ShellCompDirectiveDefault
 	})
 
 	return cmd
 }
 
+// validateOutputFiles checks each of the files against the JSON Schema and prints the
+// problems found in any invalid files.
+func validateOutputFiles(paths []string) error {
+	invalid := 0
+
+	for _, f := range paths {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return errors.Wrap(err, "Error reading JSON file")
+		}
+
+		err = output.Validate(data)
+		if err != nil {
+			invalid++
+			ui.PrintErrorf("%s: %s", f, err)
+			continue
+		}
+
+		ui.PrintSuccessf("%s is valid", f)
+	}
+
+	if invalid > 0 {
+		return clierror.NewExitCodeError(fmt.Errorf("%d of %d Infracost JSON files are invalid", invalid, len(paths)), 1)
+	}
+
+	return nil
+}
+
 func checkOutputVersion(v string) bool {
 	if !strings.HasPrefix(v, "v") {
 		v = "v" + v
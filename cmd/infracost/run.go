@@ -3,12 +3,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/infracost/infracost/internal/apiclient"
+	"github.com/infracost/infracost/internal/budget"
 	"github.com/infracost/infracost/internal/clierror"
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/output"
+	"github.com/infracost/infracost/internal/policy"
 	"github.com/infracost/infracost/internal/prices"
 	"github.com/infracost/infracost/internal/providers"
 	"github.com/infracost/infracost/internal/schema"
@@ -20,28 +24,57 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var groupByFormats = []string{"table", "html", "json"}
+
 func addRunFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("path", "p", "", "Path to the Terraform directory or JSON/plan file")
 
 	cmd.Flags().String("config-file", "", "Path to Infracost config file. Cannot be used with path, terraform* or usage-file flags")
 	cmd.Flags().String("usage-file", "", "Path to Infracost usage file that specifies values for usage-based resources")
+	cmd.Flags().String("usage-profile", "", "Name of the profile in the usage file to use, e.g. dev or prod")
 
 	cmd.Flags().String("terraform-plan-flags", "", "Flags to pass to 'terraform plan'. Applicable when path is a Terraform directory")
 	cmd.Flags().String("terraform-workspace", "", "Terraform workspace to use. Applicable when path is a Terraform directory")
+	cmd.Flags().String("cloudformation-region", "", "AWS region the stack is deployed to, defaults to AWS_REGION or us-east-1. Applicable when path is a CloudFormation template")
+	cmd.Flags().String("cloudformation-parameters-file", "", "Path to a JSON file of stack parameter values. Applicable when path is a CloudFormation template")
+	cmd.Flags().String("cloudformation-compare-to", "", "Path to the previous CloudFormation template, or the output of 'aws cloudformation get-template' for the deployed stack, to diff against. Applicable when path is a CloudFormation template")
+	cmd.Flags().String("cloudformation-compare-to-parameters-file", "", "Path to a JSON file of stack parameter values for the compare to template, defaults to the cloudformation-parameters-file. Applicable when path is a CloudFormation template")
+	cmd.Flags().Bool("terraform-parse-hcl", false, "Parse the Terraform HCL files directly instead of running 'terraform plan' (experimental). Applicable when path is a Terraform directory")
 
 	cmd.Flags().Bool("show-skipped", false, "Show unsupported resources, some of which might be free")
+	cmd.Flags().Bool("source-locations", false, "Include the file and line of each resource in the output, e.g. so JSON output can later be converted to SARIF. Always on for SARIF output. Applicable when path is a Terraform directory")
 
 	cmd.Flags().Bool("sync-usage-file", false, "Sync usage-file with missing resources, needs usage-file too (experimental)")
 
+	cmd.Flags().String("currency", "", "Three-letter ISO 4217 code of the currency to show costs in, e.g. EUR. Defaults to INFRACOST_CURRENCY or USD")
+	cmd.Flags().String("pricing-snapshot", "", "Path to a pricing snapshot file to use instead of the Cloud Pricing API (offline mode)")
+	cmd.Flags().Bool("no-cache", false, "Do not read or write cached prices from the Cloud Pricing API")
+	cmd.Flags().Bool("refresh-prices", false, "Ignore cached prices and refresh them from the Cloud Pricing API")
+	cmd.Flags().Bool("strict-pricing", false, "Fail if any cost component does not match exactly one price")
+	cmd.Flags().StringArray("policy-path", []string{}, "Path to Rego policy files or directories to evaluate against the output. Policies in the infracost package can define deny and warn rules")
+
 	_ = cmd.MarkFlagFilename("path", "json", "tf")
 	_ = cmd.MarkFlagFilename("config-file", "yml")
 	_ = cmd.MarkFlagFilename("usage-file", "yml")
+	_ = cmd.MarkFlagFilename("cloudformation-compare-to", "json", "yaml", "yml", "template")
+	_ = cmd.MarkFlagFilename("pricing-snapshot", "json", "gz")
+	_ = cmd.MarkFlagFilename("policy-path", "rego")
 }
 
 func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 	projects := make([]*schema.Project, 0)
 	projectContexts := make([]*config.ProjectContext, 0)
 
+	// Usage files can be shared by projects so they are validated once all the projects
+	// have been loaded
+	var usageFiles usage.ValidationSet
+
+	// Usage files are synced once with the resources of all the projects that use them
+	syncUsageFiles := make([]string, 0)
+	syncProjects := make(map[string][]*schema.Project)
+
 	for _, projectCfg := range runCtx.Config.Projects {
 		ctx := config.NewProjectContext(runCtx, projectCfg)
 		runCtx.SetCurrentProjectContext(ctx)
@@ -75,7 +108,7 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 			fmt.Fprintln(os.Stderr, m)
 		}
 
-		u, err := usage.LoadFromFile(projectCfg.UsageFile, runCtx.Config.SyncUsageFile)
+		u, err := usage.LoadFromFile(projectCfg.UsageFile, projectCfg.UsageProfile, runCtx.Config.SyncUsageFile)
 		if err != nil {
 			return err
 		}
@@ -94,13 +127,18 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 			return err
 		}
 
+		if len(u) > 0 {
+			usageFiles.Add(projectCfg.UsageFile, projectCfg.UsageProfile, u, project.Resources)
+		}
+
 		projects = append(projects, project)
 
-		if runCtx.Config.SyncUsageFile {
-			err = usage.SyncUsageData(project, u, projectCfg.UsageFile)
-			if err != nil {
-				return err
+		if runCtx.Config.SyncUsageFile && projectCfg.UsageFile != "" {
+			usageFile := filepath.Clean(projectCfg.UsageFile)
+			if _, ok := syncProjects[usageFile]; !ok {
+				syncUsageFiles = append(syncUsageFiles, usageFile)
 			}
+			syncProjects[usageFile] = append(syncProjects[usageFile], project)
 		}
 
 		if !runCtx.Config.IsLogging() {
@@ -108,43 +146,70 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 		}
 	}
 
+	for _, usageFile := range syncUsageFiles {
+		result, err := usage.SyncProjectsUsageData(syncProjects[usageFile], usageFile, usage.SyncOptions{})
+		if err != nil {
+			return err
+		}
+
+		m := fmt.Sprintf("Synced usage file %s: %s", usageFile, result.Summary())
+		if result.UpgradedFrom != "" {
+			m += fmt.Sprintf(", upgraded from version %s", result.UpgradedFrom)
+		}
+		if runCtx.Config.IsLogging() {
+			log.Info(m)
+		} else {
+			fmt.Fprintln(os.Stderr, m)
+		}
+	}
+
+	if usageFileIssues, err := usageFiles.Validate(); err != nil {
+		log.Debugf("Error validating usage file: %s", err)
+	} else {
+		for _, f := range usageFileIssues {
+			if len(f.Issues) > 0 {
+				printUsageIssues(f.Path, f.Issues)
+			}
+		}
+	}
+
 	spinnerOpts := ui.SpinnerOptions{
 		EnableLogging: runCtx.Config.IsLogging(),
 		NoColor:       runCtx.Config.NoColor,
 	}
 	spinner := ui.NewSpinner("Calculating monthly cost estimate", spinnerOpts)
 
-	for _, project := range projects {
-		if err := prices.PopulatePrices(runCtx.Config, project); err != nil {
-			spinner.Fail()
-			fmt.Fprintln(os.Stderr, "")
-
-			if e := unwrapped(err); errors.Is(e, apiclient.ErrInvalidAPIKey) {
-				return errors.New(fmt.Sprintf("%v\n%s %s %s %s %s\n%s",
-					e.Error(),
-					"Please check your",
-					ui.PrimaryString(config.CredentialsFilePath()),
-					"file or",
-					ui.PrimaryString("INFRACOST_API_KEY"),
-					"environment variable.",
-					"If you continue having issues please email hello@infracost.io",
-				))
-			}
-
-			if e, ok := err.(*apiclient.APIError); ok {
-				return errors.New(fmt.Sprintf("%v\n%s", e.Error(), "We have been notified of this issue."))
-			}
+	if err := prices.PopulatePrices(runCtx.Config, projects); err != nil {
+		spinner.Fail()
+		fmt.Fprintln(os.Stderr, "")
+
+		if e := unwrapped(err); errors.Is(e, apiclient.ErrInvalidAPIKey) {
+			return errors.New(fmt.Sprintf("%v\n%s %s %s %s %s\n%s",
+				e.Error(),
+				"Please check your",
+				ui.PrimaryString(config.CredentialsFilePath()),
+				"file or",
+				ui.PrimaryString("INFRACOST_API_KEY"),
+				"environment variable.",
+				"If you continue having issues please email hello@infracost.io",
+			))
+		}
 
-			return err
+		if e, ok := err.(*apiclient.APIError); ok {
+			return errors.New(fmt.Sprintf("%v\n%s", e.Error(), "We have been notified of this issue."))
 		}
 
+		return err
+	}
+
+	for _, project := range projects {
 		schema.CalculateCosts(project)
 		project.CalculateDiff()
 	}
 
 	spinner.Success()
 
-	r := output.ToOutputFormat(projects)
+	r := output.ToOutputFormat(projects, runCtx.Config.Currency)
 
 	var err error
 
@@ -166,8 +231,22 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 		ShowSkipped:      runCtx.Config.ShowSkipped,
 		NoColor:          runCtx.Config.NoColor,
 		Fields:           runCtx.Config.Fields,
+		GroupBy:          runCtx.Config.GroupBy,
+	}
+
+	for _, b := range budget.Unmatched(runCtx.Config.Budgets, r) {
+		ui.PrintWarningf("Budget %s is not checked as its project '%s' does not match any projects", b.Name, b.Project)
 	}
 
+	violations := budget.Check(runCtx.Config.Budgets, r)
+
+	policyResult, err := evaluatePolicies(runCtx, r, opts)
+	if err != nil {
+		return err
+	}
+
+	opts.Checks = buildChecks(runCtx.Config.Budgets, violations, policyResult)
+
 	var (
 		b   []byte
 		out string
@@ -183,6 +262,18 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 	case "diff":
 		b, err = output.ToDiff(r, opts)
 		out = fmt.Sprintf("\n%s", string(b))
+	case "markdown":
+		b, err = output.ToMarkdown(r, opts)
+		out = string(b)
+	case "csv":
+		b, err = output.ToCSV(r, opts)
+		out = string(b)
+	case "junit":
+		b, err = output.ToJUnit(r, opts)
+		out = string(b)
+	case "sarif":
+		b, err = output.ToSARIF(r, opts)
+		out = string(b)
 	default:
 		b, err = output.ToTable(r, opts)
 		out = fmt.Sprintf("\n%s", string(b))
@@ -194,7 +285,130 @@ func runMain(cmd *cobra.Command, runCtx *config.RunContext) error {
 
 	fmt.Printf("%s\n", out)
 
-	return nil
+	budgetErr := budgetError(violations)
+
+	policyErr := policyError(policyResult)
+	if policyErr != nil {
+		if budgetErr != nil {
+			ui.PrintError(budgetErr.Error())
+		}
+
+		return policyErr
+	}
+
+	return budgetErr
+}
+
+// evaluatePolicies evaluates the Rego policies against the JSON output. It returns nil
+// if there are no policies.
+func evaluatePolicies(runCtx *config.RunContext, r output.Root, opts output.Options) (*policy.Result, error) {
+	if len(runCtx.Config.PolicyPaths) == 0 {
+		return nil, nil
+	}
+
+	input, err := output.ToJSON(r, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating policy input")
+	}
+
+	return policy.Evaluate(runCtx.Context(), runCtx.Config.PolicyPaths, input)
+}
+
+// policyError reports the policy results. An error with the policy exit code is
+// returned if any of the policies fail.
+func policyError(result *policy.Result) error {
+	if result == nil {
+		return nil
+	}
+
+	for _, w := range result.Warnings {
+		ui.PrintWarning(w)
+	}
+
+	if !result.Failed() {
+		ui.PrintSuccessf("Policy check passed with %d warnings", len(result.Warnings))
+		return nil
+	}
+
+	msg := fmt.Sprintf("Policy check failed with %d failures:", len(result.Failures))
+	for _, f := range result.Failures {
+		msg += "\n  " + f
+	}
+
+	return clierror.NewExitCodeError(errors.New(msg), policy.ExitCode)
+}
+
+// budgetError reports the budgets from the config file that are exceeded. An error
+// with the budget exit code is returned so CI pipelines can fail the build.
+func budgetError(violations []budget.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%d budget limits exceeded:", len(violations))
+	if len(violations) == 1 {
+		msg = "1 budget limit exceeded:"
+	}
+
+	for _, v := range violations {
+		msg += "\n  " + v.String()
+	}
+
+	return clierror.NewExitCodeError(errors.New(msg), budget.ExitCode)
+}
+
+// buildChecks returns the budget and policy checks so they can be included in the
+// outputs that report them, e.g. JUnit.
+func buildChecks(budgets []*config.Budget, violations []budget.Violation, policyResult *policy.Result) []output.Check {
+	checks := make([]output.Check, 0)
+
+	for _, b := range budgets {
+		check := output.Check{
+			Type: output.BudgetCheck,
+			Name: b.Name,
+		}
+
+		allProjects := false
+		for _, v := range violations {
+			if v.Budget != b {
+				continue
+			}
+
+			check.Failures = append(check.Failures, v.String())
+			if v.Project == "" {
+				allProjects = true
+			} else {
+				check.Projects = append(check.Projects, v.Project)
+			}
+		}
+
+		if allProjects {
+			check.Projects = nil
+		}
+
+		checks = append(checks, check)
+	}
+
+	if policyResult == nil {
+		return checks
+	}
+
+	if !policyResult.Failed() {
+		return append(checks, output.Check{
+			Type: output.PolicyCheck,
+			Name: "Policies",
+		})
+	}
+
+	for _, f := range policyResult.Failures {
+		checks = append(checks, output.Check{
+			Type:     output.PolicyCheck,
+			Name:     f,
+			Failures: []string{f},
+		})
+	}
+
+	return checks
 }
 
 func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
@@ -211,13 +425,19 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 
 	hasProjectFlags := (hasPathFlag ||
 		cmd.Flags().Changed("usage-file") ||
+		cmd.Flags().Changed("usage-profile") ||
 		cmd.Flags().Changed("terraform-plan-flags") ||
 		cmd.Flags().Changed("terraform-workspace") ||
-		cmd.Flags().Changed("terraform-use-state"))
+		cmd.Flags().Changed("terraform-use-state") ||
+		cmd.Flags().Changed("terraform-parse-hcl") ||
+		cmd.Flags().Changed("cloudformation-region") ||
+		cmd.Flags().Changed("cloudformation-parameters-file") ||
+		cmd.Flags().Changed("cloudformation-compare-to") ||
+		cmd.Flags().Changed("cloudformation-compare-to-parameters-file"))
 
 	if hasConfigFile && hasProjectFlags {
 		m := "--config-file flag cannot be used with the following flags: "
-		m += "--path, --terraform-*, --usage-file"
+		m += "--path, --terraform-*, --cloudformation-*, --usage-file, --usage-profile"
 		ui.PrintUsageErrorAndExit(cmd, m)
 	}
 
@@ -248,15 +468,46 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 	if hasProjectFlags {
 		projectCfg.Path, _ = cmd.Flags().GetString("path")
 		projectCfg.UsageFile, _ = cmd.Flags().GetString("usage-file")
+		if cmd.Flags().Changed("usage-profile") {
+			projectCfg.UsageProfile, _ = cmd.Flags().GetString("usage-profile")
+		}
 		projectCfg.TerraformPlanFlags, _ = cmd.Flags().GetString("terraform-plan-flags")
 		projectCfg.TerraformWorkspace, _ = cmd.Flags().GetString("terraform-workspace")
 		projectCfg.TerraformUseState, _ = cmd.Flags().GetBool("terraform-use-state")
+		projectCfg.TerraformParseHCL, _ = cmd.Flags().GetBool("terraform-parse-hcl")
+		if cmd.Flags().Changed("cloudformation-region") {
+			projectCfg.CloudFormationRegion, _ = cmd.Flags().GetString("cloudformation-region")
+		}
+		projectCfg.CloudFormationParametersFile, _ = cmd.Flags().GetString("cloudformation-parameters-file")
+		projectCfg.CloudFormationCompareTo, _ = cmd.Flags().GetString("cloudformation-compare-to")
+		projectCfg.CloudFormationCompareToParametersFile, _ = cmd.Flags().GetString("cloudformation-compare-to-parameters-file")
 	}
 
 	cfg.Format, _ = cmd.Flags().GetString("format")
 	cfg.ShowSkipped, _ = cmd.Flags().GetBool("show-skipped")
+	cfg.SourceLocations, _ = cmd.Flags().GetBool("source-locations")
+	cfg.GroupBy, _ = cmd.Flags().GetString("group-by")
 	cfg.SyncUsageFile, _ = cmd.Flags().GetBool("sync-usage-file")
 
+	if cmd.Flags().Changed("currency") {
+		currency, _ := cmd.Flags().GetString("currency")
+		cfg.Currency = strings.ToUpper(currency)
+	}
+
+	if cmd.Flags().Changed("pricing-snapshot") {
+		cfg.PricingSnapshotPath, _ = cmd.Flags().GetString("pricing-snapshot")
+	}
+
+	if cmd.Flags().Changed("no-cache") {
+		cfg.NoPriceCache, _ = cmd.Flags().GetBool("no-cache")
+	}
+	cfg.RefreshPrices, _ = cmd.Flags().GetBool("refresh-prices")
+	cfg.StrictPricing, _ = cmd.Flags().GetBool("strict-pricing")
+
+	if cmd.Flags().Changed("policy-path") {
+		cfg.PolicyPaths, _ = cmd.Flags().GetStringArray("policy-path")
+	}
+
 	validFields := []string{"price", "monthlyQuantity", "unit", "hourlyCost", "monthlyCost"}
 	validFieldsFormats := []string{"table", "html"}
 
@@ -282,7 +533,29 @@ func loadRunFlags(cfg *config.Config, cmd *cobra.Command) error {
 	return nil
 }
 
+// checkGroupBy returns an error if the group by option is invalid, and warns if the
+// output format doesn't show groups.
+func checkGroupBy(groupBy string, format string) error {
+	if err := output.ValidateGroupBy(groupBy); err != nil {
+		return err
+	}
+
+	if groupBy != "" && !contains(groupByFormats, format) {
+		ui.PrintWarning("group-by is only supported for table, html and json output formats")
+	}
+
+	return nil
+}
+
 func checkRunConfig(cfg *config.Config) error {
+	if !currencyCodeRegex.MatchString(cfg.Currency) {
+		return fmt.Errorf("Invalid currency '%s', it should be a three-letter ISO 4217 code such as USD, EUR or GBP", cfg.Currency)
+	}
+
+	if err := checkGroupBy(cfg.GroupBy, cfg.Format); err != nil {
+		return err
+	}
+
 	if cfg.Format == "json" && cfg.ShowSkipped {
 		ui.PrintWarning("show-skipped is not needed with JSON output format as that always includes them.\n")
 	}
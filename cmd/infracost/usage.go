@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/infracost/infracost/internal/clierror"
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/providers"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/infracost/infracost/internal/ui"
+	"github.com/infracost/infracost/internal/usage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func usageCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Manage Infracost usage files",
+		Long:  "Manage Infracost usage files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(usageValidateCmd(ctx), usageImportCmd(ctx), usageSyncCmd(ctx))
+
+	return cmd
+}
+
+func usageValidateCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check a usage file for mistakes that would cause usage to be ignored",
+		Long: `Check a usage file for mistakes that would cause usage to be ignored, such as unknown keys,
+values of the wrong type, unknown resource addresses and wildcards that don't match any resources.
+The resource addresses are only checked when a path is given.`,
+		Example: `  Check the keys and values in a usage file:
+
+      infracost usage validate --usage-file infracost-usage.yml
+
+  Also check the resource addresses against a Terraform directory:
+
+      infracost usage validate --usage-file infracost-usage.yml --path /path/to/code`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("path") || cmd.Flags().Changed("config-file") {
+				err := loadRunFlags(ctx.Config, cmd)
+				if err != nil {
+					return err
+				}
+			} else {
+				usageFile, _ := cmd.Flags().GetString("usage-file")
+				if usageFile == "" {
+					ui.PrintUsageErrorAndExit(cmd, "No usage file specified, use the --usage-file flag or --config-file")
+				}
+
+				usageProfile, _ := cmd.Flags().GetString("usage-profile")
+				ctx.Config.Projects = []*config.Project{{UsageFile: usageFile, UsageProfile: usageProfile}}
+			}
+
+			var usageFiles usage.ValidationSet
+
+			for _, projectCfg := range ctx.Config.Projects {
+				if projectCfg.UsageFile == "" {
+					continue
+				}
+
+				u, resources, err := loadUsageFileResources(ctx, projectCfg)
+				if err != nil {
+					return err
+				}
+
+				usageFiles.Add(projectCfg.UsageFile, projectCfg.UsageProfile, u, resources)
+			}
+
+			result, err := usageFiles.Validate()
+			if err != nil {
+				return err
+			}
+
+			invalid := 0
+			for _, f := range result {
+				if len(f.Issues) == 0 {
+					ui.PrintSuccessf("%s is valid", f.Path)
+					continue
+				}
+
+				invalid++
+				printUsageIssues(f.Path, f.Issues)
+			}
+
+			if invalid > 0 {
+				return clierror.NewExitCodeError(fmt.Errorf("%d of %d usage files have problems", invalid, len(result)), 1)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("usage-file", "", "Path to Infracost usage file to validate")
+	cmd.Flags().String("usage-profile", "", "Name of the profile in the usage file to validate with")
+	cmd.Flags().StringP("path", "p", "", "Path to the Terraform directory or JSON/plan file to check the resource addresses against")
+	cmd.Flags().String("config-file", "", "Path to Infracost config file. Cannot be used with path, terraform* or usage-file flags")
+	cmd.Flags().String("terraform-plan-flags", "", "Flags to pass to 'terraform plan'. Applicable when path is a Terraform directory")
+	cmd.Flags().String("terraform-workspace", "", "Terraform workspace to use. Applicable when path is a Terraform directory")
+	cmd.Flags().Bool("terraform-parse-hcl", false, "Parse the Terraform HCL files directly instead of running 'terraform plan' (experimental). Applicable when path is a Terraform directory")
+
+	_ = cmd.MarkFlagFilename("usage-file", "yml")
+	_ = cmd.MarkFlagFilename("path", "json", "tf")
+	_ = cmd.MarkFlagFilename("config-file", "yml")
+
+	return cmd
+}
+
+// loadUsageFileResources loads the project's usage file and the project's resources if
+// it has a path, so the resource addresses can be checked.
+func loadUsageFileResources(runCtx *config.RunContext, projectCfg *config.Project) (map[string]*schema.UsageData, []*schema.Resource, error) {
+	u, err := usage.LoadFromFile(projectCfg.UsageFile, projectCfg.UsageProfile, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if projectCfg.Path == "" {
+		return u, nil, nil
+	}
+
+	project, err := loadProjectResources(runCtx, projectCfg, u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return u, project.Resources, nil
+}
+
+// loadProjectResources detects the project's provider and loads its resources with the
+// usage data, without calculating their costs.
+func loadProjectResources(runCtx *config.RunContext, projectCfg *config.Project, u map[string]*schema.UsageData) (*schema.Project, error) {
+	ctx := config.NewProjectContext(runCtx, projectCfg)
+	runCtx.SetCurrentProjectContext(ctx)
+
+	provider, err := providers.Detect(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not detect path type for %s", projectCfg.Path)
+	}
+
+	metadata := config.DetectProjectMetadata(ctx)
+	metadata.Type = provider.Type()
+	project := schema.NewProject(schema.GenerateProjectName(metadata, false), metadata)
+
+	err = provider.LoadResources(project, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func usageImportCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import usage estimates from exported CloudWatch metrics or Cost and Usage Reports",
+		Long: `Import usage estimates from exported CloudWatch metrics or Cost and Usage Reports into a usage file.
+
+The files are read locally, so no AWS credentials are needed. Each file can be:
+  - a Cost and Usage Report CSV file
+  - CloudWatch metric stream records in JSON
+  - a CloudWatch CSV file with namespace, metric_name, dimensions, timestamp, sum and count columns
+
+The metrics are matched to resources by their name, ID or ARN, converted to monthly values
+and written to the usage file. If more than one file has a value for the same usage key of
+a resource, the value from the last file is used. Any other resources are synced as with
+--sync-usage-file.
+
+For CloudFormation templates, Lambda functions, DynamoDB tables and NAT gateways are matched
+by their logical ID or name property, e.g. FunctionName, so resources whose names are
+generated by CloudFormation can't be matched.`,
+		Example: `  Import a Cost and Usage Report for a Terraform directory:
+
+      infracost usage import --path /path/to/code --usage-file infracost-usage.yml --file cur.csv
+
+  Import several files:
+
+      infracost usage import --path /path/to/code --usage-file infracost-usage.yml --file cur.csv --file metrics.json`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("path") && !cmd.Flags().Changed("config-file") {
+				ui.PrintUsageErrorAndExit(cmd, "No path specified, use the --path flag or --config-file")
+			}
+
+			files, _ := cmd.Flags().GetStringArray("file")
+			if len(files) == 0 {
+				ui.PrintUsageErrorAndExit(cmd, "No files to import specified, use the --file flag")
+			}
+
+			err := loadRunFlags(ctx.Config, cmd)
+			if err != nil {
+				return err
+			}
+
+			for _, projectCfg := range ctx.Config.Projects {
+				if projectCfg.UsageFile == "" {
+					ui.PrintWarning(fmt.Sprintf("Skipping %s as no usage file is specified for it.\n", projectCfg.Path))
+					continue
+				}
+
+				err := importUsageFile(ctx, projectCfg, files)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArray("file", []string{}, "Path to an exported Cost and Usage Report or CloudWatch metrics file to import")
+	cmd.Flags().String("usage-file", "", "Path to Infracost usage file to write the usage to, it is created if it doesn't exist")
+	cmd.Flags().StringP("path", "p", "", "Path to the Terraform directory or JSON/plan file")
+	cmd.Flags().String("config-file", "", "Path to Infracost config file. Cannot be used with path, terraform* or usage-file flags")
+	cmd.Flags().String("terraform-plan-flags", "", "Flags to pass to 'terraform plan'. Applicable when path is a Terraform directory")
+	cmd.Flags().String("terraform-workspace", "", "Terraform workspace to use. Applicable when path is a Terraform directory")
+	cmd.Flags().Bool("terraform-parse-hcl", false, "Parse the Terraform HCL files directly instead of running 'terraform plan' (experimental). Applicable when path is a Terraform directory")
+
+	_ = cmd.MarkFlagFilename("file", "csv", "json")
+	_ = cmd.MarkFlagFilename("usage-file", "yml")
+	_ = cmd.MarkFlagFilename("path", "json", "tf")
+	_ = cmd.MarkFlagFilename("config-file", "yml")
+
+	return cmd
+}
+
+// importUsageFile imports the usage from the files into the project's usage file.
+func importUsageFile(runCtx *config.RunContext, projectCfg *config.Project, files []string) error {
+	u, err := usage.LoadFromFile(projectCfg.UsageFile, projectCfg.UsageProfile, true)
+	if err != nil {
+		return err
+	}
+
+	project, err := loadProjectResources(runCtx, projectCfg, u)
+	if err != nil {
+		return err
+	}
+
+	result, err := usage.ImportFromFiles(files, project.Resources)
+	if err != nil {
+		return err
+	}
+
+	err = usage.ImportUsageData(project, projectCfg.UsageFile, result.Usage)
+	if err != nil {
+		return err
+	}
+
+	values := 0
+	for _, v := range result.Usage {
+		values += len(v)
+	}
+
+	ui.PrintSuccessf("Imported %d usage values for %d resources into %s", values, len(result.Usage), projectCfg.UsageFile)
+
+	if len(result.UnmatchedIDs) > 0 {
+		msg := fmt.Sprintf("%d resources in the imported files did not match any resources in %s:", len(result.UnmatchedIDs), projectCfg.Path)
+		for _, id := range result.UnmatchedIDs {
+			msg += fmt.Sprintf("\n  - %s", id)
+		}
+
+		if project.Metadata.Type == "cloudformation_state_json" {
+			msg += "\nCloudFormation resources are matched by their logical ID or their name property, e.g. FunctionName or TableName, so resources with generated names can't be matched."
+		}
+
+		ui.PrintWarning(msg)
+	}
+
+	return nil
+}
+
+func usageSyncCmd(ctx *config.RunContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Add missing resources and usage keys to a usage file",
+		Long: `Add the resources and usage keys that are missing from a usage file, with their default values.
+
+The usage file is edited in place, so its comments, ordering and existing values are kept.
+Entries for resources that are no longer in the project are kept unless --prune is used.
+Projects in a config file that share a usage file are synced together, so pruning only
+removes the entries that none of them use.`,
+		Example: `  Sync a usage file with a Terraform directory:
+
+      infracost usage sync --path /path/to/code --usage-file infracost-usage.yml
+
+  Show what would change if the entries for removed resources were pruned, without writing the file:
+
+      infracost usage sync --path /path/to/code --usage-file infracost-usage.yml --prune --dry-run`,
+		ValidArgs: []string{"--", "-"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("path") && !cmd.Flags().Changed("config-file") {
+				ui.PrintUsageErrorAndExit(cmd, "No path specified, use the --path flag or --config-file")
+			}
+
+			err := loadRunFlags(ctx.Config, cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := usage.SyncOptions{}
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.Prune, _ = cmd.Flags().GetBool("prune")
+
+			usageFiles := make([]string, 0)
+			projectCfgs := make(map[string][]*config.Project)
+
+			for _, projectCfg := range ctx.Config.Projects {
+				if projectCfg.UsageFile == "" {
+					ui.PrintWarning(fmt.Sprintf("Skipping %s as no usage file is specified for it.\n", projectCfg.Path))
+					continue
+				}
+
+				usageFile := filepath.Clean(projectCfg.UsageFile)
+				if _, ok := projectCfgs[usageFile]; !ok {
+					usageFiles = append(usageFiles, usageFile)
+				}
+				projectCfgs[usageFile] = append(projectCfgs[usageFile], projectCfg)
+			}
+
+			for _, usageFile := range usageFiles {
+				result, err := syncProjectsUsageFile(ctx, usageFile, projectCfgs[usageFile], opts)
+				if err != nil {
+					return err
+				}
+
+				printSyncResult(cmd, usageFile, result, opts)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("usage-file", "", "Path to Infracost usage file to sync, it is created if it doesn't exist")
+	cmd.Flags().StringP("path", "p", "", "Path to the Terraform directory or JSON/plan file")
+	cmd.Flags().String("config-file", "", "Path to Infracost config file. Cannot be used with path, terraform* or usage-file flags")
+	cmd.Flags().String("terraform-plan-flags", "", "Flags to pass to 'terraform plan'. Applicable when path is a Terraform directory")
+	cmd.Flags().String("terraform-workspace", "", "Terraform workspace to use. Applicable when path is a Terraform directory")
+	cmd.Flags().Bool("terraform-parse-hcl", false, "Parse the Terraform HCL files directly instead of running 'terraform plan' (experimental). Applicable when path is a Terraform directory")
+	cmd.Flags().Bool("dry-run", false, "Show the entries that would be added, updated, removed and kept without writing the usage file")
+	cmd.Flags().Bool("prune", false, "Remove the entries for resources that are not in any of the projects using the usage file")
+
+	_ = cmd.MarkFlagFilename("usage-file", "yml")
+	_ = cmd.MarkFlagFilename("path", "json", "tf")
+	_ = cmd.MarkFlagFilename("config-file", "yml")
+
+	return cmd
+}
+
+// syncProjectsUsageFile syncs the usage file with the resources of all the projects
+// that use it.
+func syncProjectsUsageFile(runCtx *config.RunContext, usageFile string, projectCfgs []*config.Project, opts usage.SyncOptions) (*usage.SyncResult, error) {
+	projects := make([]*schema.Project, 0, len(projectCfgs))
+
+	for _, projectCfg := range projectCfgs {
+		u := make(map[string]*schema.UsageData)
+
+		// Don't create the usage file on a dry run
+		if _, err := os.Stat(usageFile); err == nil || !opts.DryRun {
+			u, err = usage.LoadFromFile(usageFile, projectCfg.UsageProfile, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		p, err := loadProjectResources(runCtx, projectCfg, u)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, p)
+	}
+
+	return usage.SyncProjectsUsageData(projects, usageFile, opts)
+}
+
+func printSyncResult(cmd *cobra.Command, usageFile string, result *usage.SyncResult, opts usage.SyncOptions) {
+	if opts.DryRun {
+		msg := fmt.Sprintf("Dry run, no changes were written to %s\n\n", usageFile)
+		for _, addr := range result.Added {
+			msg += fmt.Sprintf("  %s %s\n", ui.SuccessString("+"), addr)
+		}
+		for _, addr := range result.Updated {
+			msg += fmt.Sprintf("  %s %s\n", ui.WarningString("~"), addr)
+		}
+		for _, addr := range result.Removed {
+			msg += fmt.Sprintf("  %s %s\n", ui.ErrorString("-"), addr)
+		}
+		for _, addr := range result.Kept {
+			msg += fmt.Sprintf("    %s\n", addr)
+		}
+		for _, addr := range result.Stale {
+			msg += fmt.Sprintf("    %s %s\n", addr, ui.FaintString("(not in the project)"))
+		}
+
+		msg += fmt.Sprintf("\nKey: %s added, %s updated, %s removed\n", ui.SuccessString("+"), ui.WarningString("~"), ui.ErrorString("-"))
+		msg += fmt.Sprintf("%s would be synced: %s\n", usageFile, result.Summary())
+		if result.UpgradedFrom != "" {
+			msg += fmt.Sprintf("%s would be upgraded from version %s to the latest version\n", usageFile, result.UpgradedFrom)
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), msg)
+	} else {
+		ui.PrintSuccessf("Synced %s: %s", usageFile, result.Summary())
+		if result.UpgradedFrom != "" {
+			ui.PrintWarningf("%s was upgraded from version %s to the latest version", usageFile, result.UpgradedFrom)
+		}
+	}
+
+	if len(result.Stale) > 0 {
+		msg := fmt.Sprintf("%d entries are for resources that are not in the project", len(result.Stale))
+		if len(result.Stale) == 1 {
+			msg = "1 entry is for a resource that is not in the project"
+		}
+
+		ui.PrintWarningf("%s, use --prune to remove them", msg)
+	}
+}
+
+func printUsageIssues(usageFile string, issues []usage.Issue) {
+	noun := "problems"
+	if len(issues) == 1 {
+		noun = "problem"
+	}
+
+	msg := fmt.Sprintf("Usage file %s has %d %s:\n", usageFile, len(issues), noun)
+	for _, i := range issues {
+		msg += fmt.Sprintf("  - %s\n", i)
+	}
+
+	fmt.Fprint(os.Stderr, ui.WarningString(msg))
+}
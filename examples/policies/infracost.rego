@@ -0,0 +1,26 @@
+# Example policies for `infracost breakdown --policy-path examples/policies`.
+# The input is the Infracost JSON output. Failed deny rules make infracost exit with code 3.
+package infracost
+
+deny[msg] {
+	r := input.projects[_].breakdown.resources[_]
+	r.resourceType == "aws_ebs_volume"
+	r.costComponents[_].name == "Storage (general purpose SSD, gp2)"
+	msg := sprintf("%s: use gp3 volumes instead of gp2", [r.name])
+}
+
+deny[msg] {
+	p := input.projects[_]
+	contains(p.name, "dev")
+	r := p.breakdown.resources[_]
+	r.resourceType == "aws_nat_gateway"
+	msg := sprintf("%s: NAT gateways are not allowed in dev projects", [r.name])
+}
+
+warn[msg] {
+	r := input.projects[_].breakdown.resources[_]
+	r.resourceType == "aws_db_instance"
+	to_number(r.monthlyCost) > 500
+	not r.tags.approved_by
+	msg := sprintf("%s: databases over $500/month need an approved_by tag", [r.name])
+}
@@ -0,0 +1,187 @@
+package apiclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// PriceCache is a content-addressed on-disk cache of Cloud Pricing API query results.
+// Entries are keyed by a hash of the namespace (the endpoint and currency) and the
+// product and price filters, so the same filters resolve to the same entry across runs.
+type PriceCache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	// refresh skips reading from the cache but still writes fresh results to it
+	refresh bool
+
+	pruneOnce sync.Once
+}
+
+func NewPriceCache(dir string, ttl time.Duration, maxBytes int64, refresh bool) *PriceCache {
+	return &PriceCache{
+		dir:      dir,
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		refresh:  refresh,
+	}
+}
+
+// prune prunes the cache the first time it is used, so it is only pruned once
+// per run however many queries are looked up.
+func (c *PriceCache) prune() {
+	c.pruneOnce.Do(func() {
+		err := c.Prune()
+		if err != nil {
+			log.Debugf("Error pruning price cache: %s", err)
+		}
+	})
+}
+
+// Get returns the cached result for the query if it exists and has not expired.
+func (c *PriceCache) Get(namespace string, query PriceQuery) (gjson.Result, bool) {
+	c.prune()
+
+	if c.refresh {
+		return gjson.Result{}, false
+	}
+
+	p, err := c.entryPath(namespace, query)
+	if err != nil {
+		log.Debugf("Error generating price cache key: %s", err)
+		return gjson.Result{}, false
+	}
+
+	info, err := os.Stat(p)
+	if err != nil || c.isExpired(info) {
+		return gjson.Result{}, false
+	}
+
+	data, err := ioutil.ReadFile(p)
+	if err != nil || !gjson.ValidBytes(data) {
+		return gjson.Result{}, false
+	}
+
+	return gjson.ParseBytes(data), true
+}
+
+// Set writes the result for the query to the cache. Results containing
+// errors are not cached so they are retried on the next run.
+func (c *PriceCache) Set(namespace string, query PriceQuery, result gjson.Result) error {
+	c.prune()
+
+	if result.Raw == "" || result.Get("errors").Exists() {
+		return nil
+	}
+
+	p, err := c.entryPath(namespace, query)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return errors.Wrap(err, "Error creating price cache directory")
+	}
+
+	// Write to a temp file first so concurrent runs never read a partial entry
+	f, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "Error creating price cache entry")
+	}
+
+	_, err = f.WriteString(result.Raw)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return errors.Wrap(err, "Error writing price cache entry")
+	}
+
+	return os.Rename(f.Name(), p)
+}
+
+// Prune removes expired entries and then the oldest entries until the
+// cache is under its size limit.
+func (c *PriceCache) Prune() error {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	entries := make([]os.FileInfo, 0, len(files))
+	var total int64
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+
+		if c.isExpired(f) {
+			err = os.Remove(filepath.Join(c.dir, f.Name()))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+
+		entries = append(entries, f)
+		total += f.Size()
+	}
+
+	if c.maxBytes <= 0 || total <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+
+	for _, f := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+
+		err = os.Remove(filepath.Join(c.dir, f.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= f.Size()
+	}
+
+	return nil
+}
+
+func (c *PriceCache) isExpired(info os.FileInfo) bool {
+	return c.ttl > 0 && time.Since(info.ModTime()) > c.ttl
+}
+
+func (c *PriceCache) entryPath(namespace string, query PriceQuery) (string, error) {
+	queryHash, err := query.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(queryHash))
+
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil))+".json"), nil
+}
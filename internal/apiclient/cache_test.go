@@ -0,0 +1,111 @@
+package apiclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestPriceCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-price-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &PriceCache{dir: dir, ttl: time.Hour}
+	q := PriceQuery{ProductFilter: &schema.ProductFilter{Sku: strPtr("SKU1")}}
+	res := gjson.Parse(`{"data":{"products":[{"prices":[{"priceHash":"hash1","USD":"0.1"}]}]}}`)
+
+	_, ok := c.Get("https://example.com", q)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set("https://example.com", q, res))
+
+	cached, ok := c.Get("https://example.com", q)
+	assert.True(t, ok)
+	assert.Equal(t, res.Raw, cached.Raw)
+
+	_, ok = c.Get("https://other.example.com", q)
+	assert.False(t, ok, "entries should be keyed by endpoint")
+
+	refresh := &PriceCache{dir: dir, ttl: time.Hour, refresh: true}
+	_, ok = refresh.Get("https://example.com", q)
+	assert.False(t, ok, "refresh should skip cached entries")
+
+	errQuery := PriceQuery{ProductFilter: &schema.ProductFilter{Sku: strPtr("SKU2")}}
+	require.NoError(t, c.Set("https://example.com", errQuery, gjson.Parse(`{"errors":[{"message":"boom"}]}`)))
+	_, ok = c.Get("https://example.com", errQuery)
+	assert.False(t, ok, "errors should not be cached")
+}
+
+func TestPriceCachePrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-price-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	files := map[string]time.Time{
+		"expired.json": now.Add(-2 * time.Hour),
+		"oldest.json":  now.Add(-30 * time.Minute),
+		"newest.json":  now.Add(-1 * time.Minute),
+	}
+
+	for name, modTime := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(p, []byte("0123456789"), 0600))
+		require.NoError(t, os.Chtimes(p, modTime, modTime))
+	}
+
+	c := &PriceCache{dir: dir, ttl: time.Hour, maxBytes: 15}
+	require.NoError(t, c.Prune())
+
+	remaining, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "newest.json", remaining[0].Name())
+}
+
+func TestPriceCachePrunesOnFirstUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-price-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeExpired := func(name string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(p, []byte("{}"), 0600))
+		modTime := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(p, modTime, modTime))
+		return p
+	}
+
+	first := writeExpired("first.json")
+
+	c := NewPriceCache(dir, time.Hour, 0, false)
+	_, err = os.Stat(first)
+	assert.NoError(t, err, "the cache should not be pruned until it is used")
+
+	_, _ = c.Get("https://example.com", PriceQuery{})
+	_, err = os.Stat(first)
+	assert.True(t, os.IsNotExist(err))
+
+	// The cache is only pruned once
+	second := writeExpired("second.json")
+	_, _ = c.Get("https://example.com", PriceQuery{})
+	_, err = os.Stat(second)
+	assert.NoError(t, err)
+
+	// A new cache prunes again
+	_, _ = NewPriceCache(dir, time.Hour, 0, false).Get("https://example.com", PriceQuery{})
+	_, err = os.Stat(second)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/version"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -17,6 +21,40 @@ type APIClient struct {
 	endpoint string
 	apiKey   string
 	runID    string
+
+	httpClient   *http.Client
+	maxRetries   int
+	retryMaxWait time.Duration
+}
+
+// retryBaseWait is the wait before the first retry, it doubles for each retry after that.
+var retryBaseWait = 500 * time.Millisecond
+
+// sleep is overridden in tests so retries don't slow them down.
+var sleep = time.Sleep
+
+// sharedTransport is used by all the API clients so connections are reused
+// across requests instead of opening a new connection for every request.
+var sharedTransport = newTransport()
+
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 16
+	return t
+}
+
+func newAPIClient(cfg *config.Config, endpoint string) APIClient {
+	return APIClient{
+		endpoint: endpoint,
+		apiKey:   cfg.APIKey,
+		httpClient: &http.Client{
+			Transport: sharedTransport,
+			Timeout:   cfg.APITimeout,
+		},
+		maxRetries:   cfg.APIMaxRetries,
+		retryMaxWait: cfg.APIRetryMaxWait,
+	}
 }
 
 type GraphQLQuery struct {
@@ -40,55 +78,160 @@ type APIErrorResponse struct {
 var ErrInvalidAPIKey = errors.New("Invalid API key")
 
 func (c *APIClient) doQueries(queries []GraphQLQuery) ([]gjson.Result, error) {
+	return c.sendQueries(queries, 0)
+}
+
+// doRetryableQueries is like doQueries but retries failed requests. It should only be
+// used for queries that are safe to send more than once, i.e. ones that don't change
+// anything.
+func (c *APIClient) doRetryableQueries(queries []GraphQLQuery) ([]gjson.Result, error) {
+	return c.sendQueries(queries, c.maxRetries)
+}
+
+func (c *APIClient) sendQueries(queries []GraphQLQuery, maxRetries int) ([]gjson.Result, error) {
 	if len(queries) == 0 {
 		log.Debug("Skipping GraphQL request as no queries have been specified")
 		return []gjson.Result{}, nil
 	}
 
-	respBody, err := c.doRequest("POST", "/graphql", queries)
+	respBody, err := c.sendRequest("POST", "/graphql", queries, maxRetries)
 	return gjson.ParseBytes(respBody).Array(), err
 }
 
 func (c *APIClient) doRequest(method string, path string, d interface{}) ([]byte, error) {
+	return c.sendRequest(method, path, d, 0)
+}
+
+// doRetryableRequest is like doRequest but retries transport errors, 429s and 5xxs. It
+// should only be used for requests that are safe to send more than once, since the API
+// may have handled a request even if the response was lost.
+func (c *APIClient) doRetryableRequest(method string, path string, d interface{}) ([]byte, error) {
+	return c.sendRequest(method, path, d, c.maxRetries)
+}
+
+func (c *APIClient) sendRequest(method string, path string, d interface{}, maxRetries int) ([]byte, error) {
 	reqBody, err := json.Marshal(d)
 	if err != nil {
 		return []byte{}, errors.Wrap(err, "Error generating request body")
 	}
 
-	req, err := http.NewRequest(method, c.endpoint+path, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return []byte{}, errors.Wrap(err, "Error generating request")
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: sharedTransport}
 	}
 
-	c.AddAuthHeaders(req)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, c.endpoint+path, bytes.NewReader(reqBody))
+		if err != nil {
+			return []byte{}, errors.Wrap(err, "Error generating request")
+		}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return []byte{}, errors.Wrap(err, "Error sending API request")
-	}
-	defer resp.Body.Close()
+		c.AddAuthHeaders(req)
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, &APIError{err, "Invalid API response"}
-	}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			// Retry any transport errors, e.g. timeouts and connection resets
+			if attempt < maxRetries {
+				wait := c.retryWait(attempt, "")
+				log.Debugf("Error sending API request, retrying in %s: %s", wait, err)
+				sleep(wait)
+				continue
+			}
 
-	if resp.StatusCode != 200 {
-		var r APIErrorResponse
+			return []byte{}, errors.Wrap(err, "Error sending API request")
+		}
 
-		err = json.Unmarshal(respBody, &r)
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
+			if attempt < maxRetries {
+				wait := c.retryWait(attempt, "")
+				log.Debugf("Error reading API response, retrying in %s: %s", wait, err)
+				sleep(wait)
+				continue
+			}
+
 			return []byte{}, &APIError{err, "Invalid API response"}
 		}
 
-		if r.Error == "Invalid API key" {
-			return []byte{}, ErrInvalidAPIKey
+		if isRetryableStatus(resp.StatusCode) && attempt < maxRetries {
+			wait := c.retryWait(attempt, resp.Header.Get("Retry-After"))
+			log.Debugf("Received %d from API, retrying in %s", resp.StatusCode, wait)
+			sleep(wait)
+			continue
+		}
+
+		if resp.StatusCode != 200 {
+			var r APIErrorResponse
+
+			err = json.Unmarshal(respBody, &r)
+			if err != nil {
+				return []byte{}, &APIError{err, "Invalid API response"}
+			}
+
+			if r.Error == "Invalid API key" {
+				return []byte{}, ErrInvalidAPIKey
+			}
+			return []byte{}, &APIError{errors.New(r.Error), "Received error from API"}
+		}
+
+		return respBody, nil
+	}
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryWait returns how long to wait before the next retry. If the API sent a
+// Retry-After header that is used, otherwise it uses exponential backoff with jitter.
+// The wait is never longer than retryMaxWait.
+func (c *APIClient) retryWait(attempt int, retryAfter string) time.Duration {
+	maxWait := c.retryMaxWait
+	if maxWait <= 0 {
+		maxWait = 30 * time.Second
+	}
+
+	if wait, ok := parseRetryAfter(retryAfter); ok {
+		if wait > maxWait {
+			return maxWait
+		}
+		return wait
+	}
+
+	wait := retryBaseWait << uint(attempt)
+	if wait <= 0 || wait > maxWait {
+		wait = maxWait
+	}
+
+	// Use a random wait between half and the full backoff so concurrent
+	// requests don't all retry at the same time
+	half := int64(wait / 2)
+	if half <= 0 {
+		return wait
+	}
+	return time.Duration(half + rand.Int63n(half)) // nolint:gosec
+}
+
+// parseRetryAfter parses a Retry-After header which can either be a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
 		}
-		return []byte{}, &APIError{errors.New(r.Error), "Received error from API"}
+		return wait, true
 	}
 
-	return respBody, nil
+	return 0, false
 }
 
 func (c *APIClient) AddDefaultHeaders(req *http.Request) {
@@ -0,0 +1,103 @@
+package apiclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoRequestRetries(t *testing.T) {
+	var waits []time.Duration
+	origSleep := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = origSleep }()
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := APIClient{
+		endpoint:     srv.URL,
+		httpClient:   srv.Client(),
+		maxRetries:   3,
+		retryMaxWait: 10 * time.Second,
+	}
+
+	body, err := c.doRetryableRequest("POST", "/graphql", map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(body))
+	assert.Equal(t, 3, attempts)
+	require.Len(t, waits, 2)
+	assert.True(t, waits[0] >= retryBaseWait/2 && waits[0] <= retryBaseWait)
+	assert.Equal(t, 2*time.Second, waits[1])
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	origSleep := sleep
+	sleep = func(d time.Duration) {}
+	defer func() { sleep = origSleep }()
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"error":"Bad gateway"}`))
+	}))
+	defer srv.Close()
+
+	c := APIClient{
+		endpoint:   srv.URL,
+		httpClient: srv.Client(),
+		maxRetries: 2,
+	}
+
+	_, err := c.doRetryableRequest("POST", "/graphql", map[string]string{})
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestDoRequestDoesNotRetry(t *testing.T) {
+	origSleep := sleep
+	sleep = func(d time.Duration) {}
+	defer func() { sleep = origSleep }()
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"Service unavailable"}`))
+	}))
+	defer srv.Close()
+
+	c := APIClient{
+		endpoint:   srv.URL,
+		httpClient: srv.Client(),
+		maxRetries: 3,
+	}
+
+	_, err := c.doRequest("POST", "/event", map[string]string{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryWaitIsCapped(t *testing.T) {
+	c := APIClient{retryMaxWait: time.Second}
+
+	assert.Equal(t, time.Second, c.retryWait(0, "120"))
+	assert.True(t, c.retryWait(20, "") <= time.Second)
+}
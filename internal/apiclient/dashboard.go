@@ -40,10 +40,7 @@ type projectResultInput struct {
 
 func NewDashboardAPIClient(ctx *config.RunContext) *DashboardAPIClient {
 	return &DashboardAPIClient{
-		APIClient: APIClient{
-			endpoint: ctx.Config.DashboardAPIEndpoint,
-			apiKey:   ctx.Config.APIKey,
-		},
+		APIClient:         newAPIClient(ctx.Config, ctx.Config.DashboardAPIEndpoint),
 		telemetryDisabled: ctx.Config.IsTelemetryDisabled(),
 		dashboardEnabled:  ctx.Config.EnableDashboard,
 	}
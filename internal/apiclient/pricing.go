@@ -1,15 +1,33 @@
 package apiclient
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime"
+
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
+// maxQueriesPerRequest bounds the number of queries sent in a single GraphQL request.
+const maxQueriesPerRequest = 100
+
 type PricingAPIClient struct {
 	APIClient
+	currency string
+	cache    *PriceCache
+}
+
+// PriceQuery is the product and price filter used to look up a single price.
+type PriceQuery struct {
+	ProductFilter *schema.ProductFilter `json:"productFilter"`
+	PriceFilter   *schema.PriceFilter   `json:"priceFilter"`
 }
 
 type PriceQueryKey struct {
@@ -17,36 +35,127 @@ type PriceQueryKey struct {
 	CostComponent *schema.CostComponent
 }
 
-type PriceQueryResult struct {
-	PriceQueryKey
-	Result gjson.Result
+func NewPricingAPIClient(cfg *config.Config) *PricingAPIClient {
+	c := &PricingAPIClient{
+		APIClient: newAPIClient(cfg, cfg.PricingAPIEndpoint),
+		currency:  cfg.PriceCurrency(),
+	}
+
+	if !cfg.NoPriceCache {
+		c.cache = NewPriceCache(config.PriceCacheDir(), cfg.PriceCacheTTL, cfg.PriceCacheMaxBytes, cfg.RefreshPrices)
+	}
+
+	return c
 }
 
-func NewPricingAPIClient(cfg *config.Config) *PricingAPIClient {
-	return &PricingAPIClient{
-		APIClient{
-			endpoint: cfg.PricingAPIEndpoint,
-			apiKey:   cfg.APIKey,
-		},
+// Hash returns a hash of the filters, so identical queries have the same hash.
+func (q PriceQuery) Hash() (string, error) {
+	b, err := json.Marshal(q)
+	if err != nil {
+		return "", err
 	}
+
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
 }
 
-func (c *PricingAPIClient) RunQueries(r *schema.Resource) ([]PriceQueryResult, error) {
-	keys, queries := c.batchQueries(r)
+// QueryPrices returns the result of each query in the same order as the queries.
+// Queries that are not in the price cache are sent to the API in batches of up to
+// maxQueriesPerRequest, and the batches are sent concurrently.
+func (c *PricingAPIClient) QueryPrices(queries []PriceQuery) ([]gjson.Result, error) {
+	results := make([]gjson.Result, len(queries))
+	missingIdxs := make([]int, 0, len(queries))
+
+	for i, q := range queries {
+		if c.cache != nil {
+			if res, ok := c.cache.Get(c.cacheNamespace(), q); ok {
+				results[i] = res
+				continue
+			}
+		}
+
+		missingIdxs = append(missingIdxs, i)
+	}
+
+	if len(missingIdxs) == 0 {
+		log.Debugf("Using cached pricing details for %d queries", len(queries))
+		return results, nil
+	}
+
+	batches := make([][]int, 0, len(missingIdxs)/maxQueriesPerRequest+1)
+	for start := 0; start < len(missingIdxs); start += maxQueriesPerRequest {
+		end := start + maxQueriesPerRequest
+		if end > len(missingIdxs) {
+			end = len(missingIdxs)
+		}
+		batches = append(batches, missingIdxs[start:end])
+	}
+
+	log.Debugf("Getting pricing details from %s for %d queries in %d requests (%d cached)", c.endpoint, len(missingIdxs), len(batches), len(queries)-len(missingIdxs))
 
-	if len(queries) == 0 {
-		log.Debugf("Skipping getting pricing details for %s since there are no queries to run", r.Name)
-		return []PriceQueryResult{}, nil
+	numWorkers := workerCount()
+	if numWorkers > len(batches) {
+		numWorkers = len(batches)
 	}
 
-	log.Debugf("Getting pricing details from %s for %s", c.endpoint, r.Name)
+	jobs := make(chan []int, len(batches))
+	resultErrors := make(chan error, len(batches))
+
+	// Each worker only writes to the indexes of its own batch so results can be shared
+	for i := 0; i < numWorkers; i++ {
+		go func(jobs <-chan []int, resultErrors chan<- error) {
+			for idxs := range jobs {
+				resultErrors <- c.runBatch(queries, idxs, results)
+			}
+		}(jobs, resultErrors)
+	}
 
-	results, err := c.doQueries(queries)
+	for _, b := range batches {
+		jobs <- b
+	}
+	close(jobs)
+
+	var err error
+	for i := 0; i < len(batches); i++ {
+		if batchErr := <-resultErrors; batchErr != nil && err == nil {
+			err = batchErr
+		}
+	}
+
+	if err != nil {
+		return []gjson.Result{}, err
+	}
+
+	return results, nil
+}
+
+func (c *PricingAPIClient) runBatch(queries []PriceQuery, idxs []int, results []gjson.Result) error {
+	gqlQueries := make([]GraphQLQuery, 0, len(idxs))
+	for _, i := range idxs {
+		gqlQueries = append(gqlQueries, c.buildQuery(queries[i].ProductFilter, queries[i].PriceFilter))
+	}
+
+	batchResults, err := c.doRetryableQueries(gqlQueries)
 	if err != nil {
-		return []PriceQueryResult{}, err
+		return err
 	}
 
-	return c.zipQueryResults(keys, results), nil
+	if len(batchResults) != len(idxs) {
+		return &APIError{errors.Errorf("expected %d results, got %d", len(idxs), len(batchResults)), "Invalid API response"}
+	}
+
+	for j, i := range idxs {
+		results[i] = batchResults[j]
+
+		if c.cache != nil {
+			err := c.cache.Set(c.cacheNamespace(), queries[i], batchResults[j])
+			if err != nil {
+				log.Debugf("Error writing to price cache: %s", err)
+			}
+		}
+	}
+
+	return nil
 }
 
 func (c *PricingAPIClient) buildQuery(product *schema.ProductFilter, price *schema.PriceFilter) GraphQLQuery {
@@ -54,50 +163,52 @@ func (c *PricingAPIClient) buildQuery(product *schema.ProductFilter, price *sche
 	v["productFilter"] = product
 	v["priceFilter"] = price
 
-	query := `
+	query := fmt.Sprintf(`
 		query($productFilter: ProductFilter!, $priceFilter: PriceFilter) {
 			products(filter: $productFilter) {
 				prices(filter: $priceFilter) {
 					priceHash
-					USD
+					%s
 				}
 			}
 		}
-	`
+	`, c.currency)
 
 	return GraphQLQuery{query, v}
 }
 
-// Batch all the queries for this resource so we can use one GraphQL call.
-// Use PriceQueryKeys to keep track of which query maps to which sub-resource and price component.
-func (c *PricingAPIClient) batchQueries(r *schema.Resource) ([]PriceQueryKey, []GraphQLQuery) {
+// cacheNamespace separates cached results from different endpoints and currencies.
+func (c *PricingAPIClient) cacheNamespace() string {
+	return c.endpoint + "|" + c.currency
+}
+
+// PriceQueryKeys returns a PriceQueryKey for every cost component of the resource and its sub-resources.
+func PriceQueryKeys(r *schema.Resource) []PriceQueryKey {
 	keys := make([]PriceQueryKey, 0)
-	queries := make([]GraphQLQuery, 0)
 
 	for _, component := range r.CostComponents {
 		keys = append(keys, PriceQueryKey{r, component})
-		queries = append(queries, c.buildQuery(component.ProductFilter, component.PriceFilter))
 	}
 
 	for _, subresource := range r.FlattenedSubResources() {
 		for _, component := range subresource.CostComponents {
 			keys = append(keys, PriceQueryKey{subresource, component})
-			queries = append(queries, c.buildQuery(component.ProductFilter, component.PriceFilter))
 		}
 	}
 
-	return keys, queries
+	return keys
 }
 
-func (c *PricingAPIClient) zipQueryResults(k []PriceQueryKey, r []gjson.Result) []PriceQueryResult {
-	res := make([]PriceQueryResult, 0, len(k))
-
-	for i, k := range k {
-		res = append(res, PriceQueryResult{
-			PriceQueryKey: k,
-			Result:        r[i],
-		})
+// workerCount returns the number of concurrent requests to make using the following formula:
+// max(min(4, numCPU * 4), 16)
+func workerCount() int {
+	numWorkers := 4
+	numCPU := runtime.NumCPU()
+	if numCPU*4 > numWorkers {
+		numWorkers = numCPU * 4
 	}
-
-	return res
+	if numWorkers > 16 {
+		numWorkers = 16
+	}
+	return numWorkers
 }
@@ -0,0 +1,202 @@
+package budget
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/shopspring/decimal"
+)
+
+// ExitCode is used when a budget is exceeded so CI pipelines can tell it apart from errors.
+const ExitCode = 2
+
+type Violation struct {
+	Budget  *config.Budget
+	Project string
+	Message string
+}
+
+func (v Violation) String() string {
+	scope := ""
+	if v.Project != "" {
+		scope = fmt.Sprintf(" for project %s", v.Project)
+	}
+
+	if v.Budget.Tag != "" {
+		scope += fmt.Sprintf(" for resources tagged %s", v.Budget.Tag)
+	}
+
+	return fmt.Sprintf("Budget %s exceeded%s: %s", v.Budget.Name, scope, v.Message)
+}
+
+type costs struct {
+	past    decimal.Decimal
+	current decimal.Decimal
+	hasDiff bool
+}
+
+// Check returns a violation for every budget limit that is exceeded by the projects in
+// the output. Increases are only checked for projects that have a past breakdown.
+func Check(budgets []*config.Budget, out output.Root) []Violation {
+	violations := make([]Violation, 0)
+
+	for _, b := range budgets {
+		if b.Project == "" {
+			c := costs{}
+			for _, p := range out.Projects {
+				c = c.add(projectCosts(p, b))
+			}
+
+			violations = append(violations, checkCosts(b, "", c, out.Currency)...)
+			continue
+		}
+
+		for _, p := range out.Projects {
+			if !matchesProject(b.Project, p) {
+				continue
+			}
+
+			violations = append(violations, checkCosts(b, p.Name, projectCosts(p, b), out.Currency)...)
+		}
+	}
+
+	return violations
+}
+
+// Unmatched returns the budgets scoped to a project pattern that doesn't match any of
+// the projects in the output, since these budgets are never checked.
+func Unmatched(budgets []*config.Budget, out output.Root) []*config.Budget {
+	unmatched := make([]*config.Budget, 0)
+
+	for _, b := range budgets {
+		if b.Project == "" {
+			continue
+		}
+
+		matched := false
+		for _, p := range out.Projects {
+			if matchesProject(b.Project, p) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			unmatched = append(unmatched, b)
+		}
+	}
+
+	return unmatched
+}
+
+func checkCosts(b *config.Budget, project string, c costs, currency string) []Violation {
+	violations := make([]Violation, 0)
+
+	if b.MonthlyTotal != nil {
+		limit := decimal.NewFromFloat(*b.MonthlyTotal)
+		if c.current.GreaterThan(limit) {
+			violations = append(violations, Violation{
+				Budget:  b,
+				Project: project,
+				Message: fmt.Sprintf("monthly cost of %s is over the %s limit", formatCost(currency, c.current), formatCost(currency, limit)),
+			})
+		}
+	}
+
+	if !c.hasDiff {
+		return violations
+	}
+
+	increase := c.current.Sub(c.past)
+
+	if b.MonthlyIncrease != nil {
+		limit := decimal.NewFromFloat(*b.MonthlyIncrease)
+		if increase.GreaterThan(limit) {
+			violations = append(violations, Violation{
+				Budget:  b,
+				Project: project,
+				Message: fmt.Sprintf("monthly cost increase of %s is over the %s limit", formatCost(currency, increase), formatCost(currency, limit)),
+			})
+		}
+	}
+
+	// The percentage increase can't be calculated if there was no previous cost
+	if b.PercentIncrease != nil && c.past.IsPositive() {
+		limit := decimal.NewFromFloat(*b.PercentIncrease)
+		percent := increase.Div(c.past).Mul(decimal.NewFromInt(100))
+		if percent.GreaterThan(limit) {
+			violations = append(violations, Violation{
+				Budget:  b,
+				Project: project,
+				Message: fmt.Sprintf("monthly cost increase of %s%% is over the %s%% limit", percent.StringFixed(1), limit.String()),
+			})
+		}
+	}
+
+	return violations
+}
+
+func projectCosts(p output.Project, b *config.Budget) costs {
+	c := costs{
+		current: breakdownMonthlyCost(p.Breakdown, b),
+		hasDiff: p.PastBreakdown != nil,
+	}
+
+	if c.hasDiff {
+		c.past = breakdownMonthlyCost(p.PastBreakdown, b)
+	}
+
+	return c
+}
+
+func (c costs) add(o costs) costs {
+	return costs{
+		past:    c.past.Add(o.past),
+		current: c.current.Add(o.current),
+		hasDiff: c.hasDiff || o.hasDiff,
+	}
+}
+
+func breakdownMonthlyCost(breakdown *output.Breakdown, b *config.Budget) decimal.Decimal {
+	total := decimal.Zero
+	if breakdown == nil {
+		return total
+	}
+
+	tagKey, tagValue := b.TagKeyValue()
+
+	for _, r := range breakdown.Resources {
+		if r.MonthlyCost == nil {
+			continue
+		}
+
+		if b.Tag != "" && r.Tags[tagKey] != tagValue {
+			continue
+		}
+
+		total = total.Add(*r.MonthlyCost)
+	}
+
+	return total
+}
+
+// matchesProject returns true if the project name or path matches the pattern.
+func matchesProject(pattern string, p output.Project) bool {
+	if ok, _ := filepath.Match(pattern, p.Name); ok {
+		return true
+	}
+
+	if p.Metadata != nil {
+		if ok, _ := filepath.Match(pattern, p.Metadata.Path); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatCost(currency string, d decimal.Decimal) string {
+	return output.FormatCost(currency, &d)
+}
@@ -0,0 +1,98 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/output"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func testResource(name string, monthlyCost int64, tags map[string]string) *schema.Resource {
+	c := decimal.NewFromInt(monthlyCost)
+	return &schema.Resource{Name: name, MonthlyCost: &c, Tags: tags}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestCheck(t *testing.T) {
+	web := schema.NewProject("infracost/web", &schema.ProjectMetadata{Path: "web"})
+	web.PastResources = []*schema.Resource{
+		testResource("aws_instance.web", 100, map[string]string{"team": "web"}),
+	}
+	web.Resources = []*schema.Resource{
+		testResource("aws_instance.web", 150, map[string]string{"team": "web"}),
+		testResource("aws_db_instance.db", 200, map[string]string{"team": "data"}),
+	}
+
+	api := schema.NewProject("infracost/api", &schema.ProjectMetadata{Path: "api"})
+	api.PastResources = []*schema.Resource{testResource("aws_instance.api", 100, nil)}
+	api.Resources = []*schema.Resource{testResource("aws_instance.api", 100, nil)}
+
+	projects := []*schema.Project{web, api}
+
+	budgets := []*config.Budget{
+		{Name: "total", MonthlyTotal: float64Ptr(1000), MonthlyIncrease: float64Ptr(200)},
+		{Name: "per-project", Project: "infracost/*", PercentIncrease: float64Ptr(100)},
+		{Name: "team-web", Tag: "team=web", MonthlyIncrease: float64Ptr(10)},
+		{Name: "api", Project: "api", MonthlyTotal: float64Ptr(50)},
+	}
+
+	violations := Check(budgets, output.ToOutputFormat(projects, "USD"))
+
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+
+	assert.Equal(t, []string{
+		"Budget total exceeded: monthly cost increase of $250 is over the $200 limit",
+		"Budget per-project exceeded for project infracost/web: monthly cost increase of 250.0% is over the 100% limit",
+		"Budget team-web exceeded for resources tagged team=web: monthly cost increase of $50.00 is over the $10.00 limit",
+		"Budget api exceeded for project infracost/api: monthly cost of $100 is over the $50.00 limit",
+	}, messages)
+}
+
+func TestCheckNoDiff(t *testing.T) {
+	p := schema.NewProject("infracost/web", &schema.ProjectMetadata{})
+	p.HasDiff = false
+	p.Resources = []*schema.Resource{testResource("aws_instance.web", 150, nil)}
+
+	violations := Check([]*config.Budget{
+		{Name: "increase", MonthlyIncrease: float64Ptr(10), PercentIncrease: float64Ptr(10)},
+	}, output.ToOutputFormat([]*schema.Project{p}, "USD"))
+
+	assert.Empty(t, violations)
+}
+
+func TestCheckCurrency(t *testing.T) {
+	p := schema.NewProject("infracost/web", &schema.ProjectMetadata{})
+	p.HasDiff = false
+	p.Resources = []*schema.Resource{testResource("aws_instance.web", 1500, nil)}
+
+	violations := Check([]*config.Budget{
+		{Name: "total", MonthlyTotal: float64Ptr(1000)},
+	}, output.ToOutputFormat([]*schema.Project{p}, "EUR"))
+
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "Budget total exceeded: monthly cost of €1,500 is over the €1,000 limit", violations[0].String())
+}
+
+func TestUnmatched(t *testing.T) {
+	out := output.ToOutputFormat([]*schema.Project{
+		schema.NewProject("infracost/web", &schema.ProjectMetadata{Path: "web"}),
+	}, "USD")
+
+	budgets := []*config.Budget{
+		{Name: "total", MonthlyTotal: float64Ptr(1000)},
+		{Name: "web", Project: "infracost/*", MonthlyTotal: float64Ptr(1000)},
+		{Name: "web-path", Project: "web", MonthlyTotal: float64Ptr(1000)},
+		{Name: "api", Project: "infracost/api", MonthlyTotal: float64Ptr(1000)},
+	}
+
+	assert.Equal(t, []*config.Budget{budgets[3]}, Unmatched(budgets, out))
+}
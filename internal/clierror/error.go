@@ -20,3 +20,25 @@ func NewSanitizedError(err error, sanitizedMsg string) *SanitizedError {
 		err:          err,
 	}
 }
+
+// ExitCodeError is returned when the command completed but should exit with a specific
+// non-zero exit code, e.g. when a budget is exceeded. These aren't reported upstream.
+type ExitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ExitCodeError) ExitCode() int {
+	return e.code
+}
+
+func NewExitCodeError(err error, code int) *ExitCodeError {
+	return &ExitCodeError{
+		code: code,
+		err:  err,
+	}
+}
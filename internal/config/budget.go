@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Budget is a limit on the monthly cost, or the increase in the monthly cost, that
+// is checked after the costs are calculated. A budget applies to the total of all
+// projects unless it's scoped to projects matching a name or path pattern, in which
+// case each matching project is checked separately. It can also be scoped to the
+// resources with a tag, given as key=value.
+type Budget struct {
+	Name            string   `yaml:"name,omitempty"`
+	Project         string   `yaml:"project,omitempty"`
+	Tag             string   `yaml:"tag,omitempty"`
+	MonthlyTotal    *float64 `yaml:"monthly_total,omitempty"`
+	MonthlyIncrease *float64 `yaml:"monthly_increase,omitempty"`
+	PercentIncrease *float64 `yaml:"percent_increase,omitempty"`
+}
+
+// TagKeyValue returns the key and value of the tag the budget is scoped to.
+func (b *Budget) TagKeyValue() (string, string) {
+	p := strings.SplitN(b.Tag, "=", 2)
+	if len(p) < 2 {
+		return p[0], ""
+	}
+
+	return p[0], p[1]
+}
+
+func (b *Budget) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("Budgets must have a name")
+	}
+
+	if b.MonthlyTotal == nil && b.MonthlyIncrease == nil && b.PercentIncrease == nil {
+		return fmt.Errorf("Budget %s must set at least one of monthly_total, monthly_increase or percent_increase", b.Name)
+	}
+
+	if b.Tag != "" {
+		if k, v := b.TagKeyValue(); k == "" || v == "" {
+			return fmt.Errorf("Budget %s has invalid tag '%s', it should be in the format key=value", b.Name, b.Tag)
+		}
+	}
+
+	return nil
+}
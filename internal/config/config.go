@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -20,7 +21,16 @@ type Project struct {
 	TerraformCloudHost  string `yaml:"terraform_cloud_host,omitempty" envconfig:"INFRACOST_TERRAFORM_CLOUD_HOST"`
 	TerraformCloudToken string `yaml:"terraform_cloud_token,omitempty" envconfig:"INFRACOST_TERRAFORM_CLOUD_TOKEN"`
 	UsageFile           string `yaml:"usage_file,omitempty" ignored:"true"`
+	UsageProfile        string `yaml:"usage_profile,omitempty" envconfig:"INFRACOST_USAGE_PROFILE"`
 	TerraformUseState   bool   `yaml:"terraform_use_state,omitempty" ignored:"true"`
+	TerraformParseHCL   bool   `yaml:"terraform_parse_hcl,omitempty" ignored:"true"`
+
+	CloudFormationRegion         string `yaml:"cloudformation_region,omitempty" envconfig:"INFRACOST_CLOUDFORMATION_REGION"`
+	CloudFormationParametersFile string `yaml:"cloudformation_parameters_file,omitempty" ignored:"true"`
+	CloudFormationCompareTo      string `yaml:"cloudformation_compare_to,omitempty" ignored:"true"`
+	// CloudFormationCompareToParametersFile is the parameters file of the compare to
+	// template. If it's not set the parameters file of the template is used for both.
+	CloudFormationCompareToParametersFile string `yaml:"cloudformation_compare_to_parameters_file,omitempty" ignored:"true"`
 }
 
 type Config struct { // nolint:golint
@@ -36,12 +46,32 @@ type Config struct { // nolint:golint
 	DefaultPricingAPIEndpoint string `yaml:"default_pricing_api_endpoint,omitempty" envconfig:"INFRACOST_DEFAULT_PRICING_API_ENDPOINT"`
 	DashboardAPIEndpoint      string `yaml:"dashboard_api_endpoint,omitempty" envconfig:"INFRACOST_DASHBOARD_API_ENDPOINT"`
 	EnableDashboard           bool   `yaml:"enable_dashboard,omitempty" envconfig:"INFRACOST_ENABLE_DASHBOARD"`
+	PricingSnapshotPath       string `yaml:"pricing_snapshot_path,omitempty" envconfig:"INFRACOST_PRICING_SNAPSHOT_PATH"`
+	Currency                  string `envconfig:"INFRACOST_CURRENCY"`
+	CurrencyRatesFile         string `envconfig:"INFRACOST_CURRENCY_RATES_FILE"`
+
+	APITimeout      time.Duration `yaml:"api_timeout,omitempty" envconfig:"INFRACOST_API_TIMEOUT"`
+	APIMaxRetries   int           `yaml:"api_max_retries,omitempty" envconfig:"INFRACOST_API_MAX_RETRIES"`
+	APIRetryMaxWait time.Duration `yaml:"api_retry_max_wait,omitempty" envconfig:"INFRACOST_API_RETRY_MAX_WAIT"`
+
+	NoPriceCache       bool          `envconfig:"INFRACOST_NO_PRICE_CACHE"`
+	RefreshPrices      bool          `ignored:"true"`
+	PriceCacheTTL      time.Duration `envconfig:"INFRACOST_PRICE_CACHE_TTL"`
+	PriceCacheMaxBytes int64         `envconfig:"INFRACOST_PRICE_CACHE_MAX_BYTES"`
 
 	Projects      []*Project `yaml:"projects" ignored:"true"`
 	Format        string     `yaml:"format,omitempty" ignored:"true"`
 	ShowSkipped   bool       `yaml:"show_skipped,omitempty" ignored:"true"`
 	SyncUsageFile bool       `yaml:"sync_usage_file,omitempty" ignored:"true"`
+	StrictPricing bool       `yaml:"strict_pricing,omitempty" ignored:"true"`
 	Fields        []string   `yaml:"fields,omitempty" ignored:"true"`
+	GroupBy       string     `yaml:"group_by,omitempty" ignored:"true"`
+	Budgets       []*Budget  `yaml:"budgets,omitempty" ignored:"true"`
+	PolicyPaths   []string   `yaml:"policy_paths,omitempty" ignored:"true"`
+
+	// SourceLocations adds the file and line of each resource to the output. It's
+	// always on for the SARIF format.
+	SourceLocations bool `yaml:"source_locations,omitempty" ignored:"true"`
 }
 
 func init() {
@@ -59,6 +89,14 @@ func DefaultConfig() *Config {
 		DefaultPricingAPIEndpoint: "https://pricing.api.infracost.io",
 		PricingAPIEndpoint:        "https://pricing.api.infracost.io",
 		DashboardAPIEndpoint:      "https://dashboard.api.infracost.io",
+		Currency:                  "USD",
+
+		APITimeout:      60 * time.Second,
+		APIMaxRetries:   3,
+		APIRetryMaxWait: 30 * time.Second,
+
+		PriceCacheTTL:      24 * time.Hour,
+		PriceCacheMaxBytes: 100 * 1024 * 1024,
 
 		Projects: []*Project{{}},
 
@@ -74,6 +112,7 @@ func (c *Config) LoadFromConfigFile(path string) error {
 	}
 
 	c.Projects = cfgFile.Projects
+	c.Budgets = cfgFile.Budgets
 
 	// Reload the environment to overwrite any of the config file configs
 	err = c.LoadFromEnv()
@@ -109,6 +148,11 @@ func (c *Config) loadEnvVars() error {
 		return err
 	}
 
+	c.Currency = strings.ToUpper(strings.TrimSpace(c.Currency))
+	if c.Currency == "" {
+		c.Currency = "USD"
+	}
+
 	for _, project := range c.Projects {
 		err = envconfig.Process("", project)
 		if err != nil {
@@ -155,11 +199,38 @@ func (c *Config) IsLogging() bool {
 	return c.LogLevel != ""
 }
 
+// IncludeSourceLocations returns true if the source locations of the resources should
+// be added. Finding them means parsing the Terraform files again so it's only done
+// when they are needed.
+func (c *Config) IncludeSourceLocations() bool {
+	return c.SourceLocations || strings.ToLower(c.Format) == "sarif"
+}
+
 func (c *Config) IsSelfHosted() bool {
 	return c.PricingAPIEndpoint != c.DefaultPricingAPIEndpoint
 }
 
+// IsOffline returns true if prices are looked up from a local pricing snapshot
+// instead of the Cloud Pricing API.
+func (c *Config) IsOffline() bool {
+	return c.PricingSnapshotPath != ""
+}
+
+// PriceCurrency returns the currency to request prices in. If a currency rates
+// file is used then prices are requested in USD and converted locally.
+func (c *Config) PriceCurrency() string {
+	if c.CurrencyRatesFile != "" {
+		return "USD"
+	}
+
+	return c.Currency
+}
+
 func (c *Config) IsTelemetryDisabled() bool {
+	if c.IsOffline() {
+		return true
+	}
+
 	return c.IsSelfHosted() && IsFalsy(os.Getenv("INFRACOST_SELF_HOSTED_TELEMETRY"))
 }
 
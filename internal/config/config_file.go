@@ -16,6 +16,7 @@ const maxConfigFileVersion = "0.1"
 type ConfigFileSpec struct { // nolint:golint
 	Version  string     `yaml:"version"`
 	Projects []*Project `yaml:"projects" ignored:"true"`
+	Budgets  []*Budget  `yaml:"budgets,omitempty" ignored:"true"`
 }
 
 func LoadConfigFile(path string) (ConfigFileSpec, error) {
@@ -39,6 +40,12 @@ func LoadConfigFile(path string) (ConfigFileSpec, error) {
 		return cfgFile, fmt.Errorf("Invalid config file version. Supported versions are %s ≤ x ≤ %s", minConfigFileVersion, maxConfigFileVersion)
 	}
 
+	for _, b := range cfgFile.Budgets {
+		if err := b.Validate(); err != nil {
+			return cfgFile, err
+		}
+	}
+
 	return cfgFile, nil
 }
 
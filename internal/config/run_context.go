@@ -52,6 +52,10 @@ func (c *RunContext) SetContextValue(key string, value interface{}) {
 	c.contextVals[key] = value
 }
 
+func (c *RunContext) Context() context.Context {
+	return c.ctx
+}
+
 func (c *RunContext) ContextValues() map[string]interface{} {
 	return c.contextVals
 }
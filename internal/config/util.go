@@ -44,3 +44,8 @@ func isDir(path string) bool {
 
 	return info.IsDir()
 }
+
+// PriceCacheDir returns the directory used to cache responses from the Cloud Pricing API.
+func PriceCacheDir() string {
+	return filepath.Join(userConfigDir(), "cache", "prices")
+}
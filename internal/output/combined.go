@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -12,22 +13,43 @@ type ReportInput struct {
 	Root     Root
 }
 
+// Load parses the Infracost JSON output, migrating it from older versions first.
 func Load(data []byte) (Root, error) {
 	var out Root
-	err := json.Unmarshal(data, &out)
+
+	data, err := migrate(data)
+	if err != nil {
+		return out, err
+	}
+
+	err = json.Unmarshal(data, &out)
 	return out, err
 }
 
-func Combine(inputs []ReportInput, opts Options) Root {
+func Combine(inputs []ReportInput, opts Options) (Root, error) {
 	var combined Root
 
+	currency := ""
+
 	var totalHourlyCost *decimal.Decimal
 	var totalMonthlyCost *decimal.Decimal
+	var totalMonthlyCostRange *CostRange
+	hasRanges := false
 
 	projects := make([]Project, 0)
 	summaries := make([]*Summary, 0, len(inputs))
 
 	for _, input := range inputs {
+		inputCurrency := input.Root.Currency
+		if inputCurrency == "" {
+			inputCurrency = "USD"
+		}
+
+		if currency == "" {
+			currency = inputCurrency
+		} else if currency != inputCurrency {
+			return combined, fmt.Errorf("Cannot combine outputs with different currencies (%s and %s)", currency, inputCurrency)
+		}
 
 		projects = append(projects, input.Root.Projects...)
 
@@ -47,14 +69,22 @@ func Combine(inputs []ReportInput, opts Options) Root {
 
 			totalMonthlyCost = decimalPtr(totalMonthlyCost.Add(*input.Root.TotalMonthlyCost))
 		}
+
+		hasRanges = hasRanges || input.Root.TotalMonthlyCostRange != nil
+		totalMonthlyCostRange = addCostRanges(totalMonthlyCostRange, costRangeOrCost(input.Root.TotalMonthlyCostRange, input.Root.TotalMonthlyCost))
+	}
+
+	if hasRanges {
+		combined.TotalMonthlyCostRange = totalMonthlyCostRange
 	}
 
 	combined.Version = outputVersion
+	combined.Currency = currency
 	combined.Projects = projects
 	combined.TotalHourlyCost = totalHourlyCost
 	combined.TotalMonthlyCost = totalMonthlyCost
 	combined.TimeGenerated = time.Now()
 	combined.Summary = MergeSummaries(summaries)
 
-	return combined
+	return combined, nil
 }
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+// Compare builds a diff of two Infracost outputs, e.g. the breakdowns of a base and
+// head branch, so it can be shown with the diff formatters. Projects are matched by
+// name and resources are matched by address. The breakdowns in the from output are
+// used as the past breakdowns of the projects.
+func Compare(from Root, to Root) (Root, error) {
+	var out Root
+
+	fromCurrency := currencyOrDefault(from.Currency)
+	toCurrency := currencyOrDefault(to.Currency)
+	if fromCurrency != toCurrency {
+		return out, fmt.Errorf("Cannot compare outputs with different currencies (%s and %s)", fromCurrency, toCurrency)
+	}
+
+	fromProjects := make(map[string]Project, len(from.Projects))
+	for _, p := range from.Projects {
+		fromProjects[p.Name] = p
+	}
+
+	projects := make([]Project, 0, len(to.Projects))
+
+	for _, p := range to.Projects {
+		pastBreakdown := emptyBreakdown()
+		if fromProject, ok := fromProjects[p.Name]; ok && fromProject.Breakdown != nil {
+			pastBreakdown = fromProject.Breakdown
+		}
+		delete(fromProjects, p.Name)
+
+		breakdown := p.Breakdown
+		if breakdown == nil {
+			breakdown = emptyBreakdown()
+		}
+
+		projects = append(projects, Project{
+			Name:          p.Name,
+			Metadata:      p.Metadata,
+			PastBreakdown: pastBreakdown,
+			Breakdown:     breakdown,
+			Diff:          diffBreakdowns(pastBreakdown, breakdown),
+			Summary:       p.Summary,
+		})
+	}
+
+	// Projects that have been removed
+	for _, p := range from.Projects {
+		if _, ok := fromProjects[p.Name]; !ok {
+			continue
+		}
+
+		pastBreakdown := p.Breakdown
+		if pastBreakdown == nil {
+			pastBreakdown = emptyBreakdown()
+		}
+
+		projects = append(projects, Project{
+			Name:          p.Name,
+			Metadata:      p.Metadata,
+			PastBreakdown: pastBreakdown,
+			Breakdown:     emptyBreakdown(),
+			Diff:          diffBreakdowns(pastBreakdown, emptyBreakdown()),
+			Summary:       p.Summary,
+		})
+	}
+
+	out.Version = outputVersion
+	out.Currency = toCurrency
+	out.Projects = projects
+	out.TotalHourlyCost = to.TotalHourlyCost
+	out.TotalMonthlyCost = to.TotalMonthlyCost
+	out.TotalMonthlyCostRange = to.TotalMonthlyCostRange
+	out.TimeGenerated = time.Now()
+	out.Summary = to.Summary
+
+	return out, nil
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return "USD"
+	}
+
+	return currency
+}
+
+func emptyBreakdown() *Breakdown {
+	return &Breakdown{
+		Resources:        []Resource{},
+		TotalHourlyCost:  decimalPtr(decimal.Zero),
+		TotalMonthlyCost: decimalPtr(decimal.Zero),
+	}
+}
+
+// diffBreakdowns calculates the diff of two breakdowns with the same diff logic used
+// for the past and current resources of a project.
+func diffBreakdowns(past *Breakdown, current *Breakdown) *Breakdown {
+	project := schema.NewProject("", nil)
+	project.PastResources = schemaResources(past.Resources)
+	project.Resources = schemaResources(current.Resources)
+	project.CalculateDiff()
+
+	return outputBreakdown(project.Diff)
+}
+
+// schemaResources converts output resources back to schema resources so they can be
+// diffed. The quantities and prices in the output already include the unit
+// multiplier so it's set to 1.
+func schemaResources(resources []Resource) []*schema.Resource {
+	arr := make([]*schema.Resource, 0, len(resources))
+
+	for _, r := range resources {
+		arr = append(arr, schemaResource(r))
+	}
+
+	return arr
+}
+
+func schemaResource(r Resource) *schema.Resource {
+	comps := make([]*schema.CostComponent, 0, len(r.CostComponents))
+	for _, c := range r.CostComponents {
+		comp := &schema.CostComponent{
+			Name:            c.Name,
+			Unit:            c.Unit,
+			UnitMultiplier:  1,
+			HourlyQuantity:  c.HourlyQuantity,
+			MonthlyQuantity: c.MonthlyQuantity,
+			HourlyCost:      c.HourlyCost,
+			MonthlyCost:     c.MonthlyCost,
+		}
+		comp.SetPrice(c.Price)
+		comp.SetPriceMatchStatus(schema.PriceMatchStatus(c.PriceMatchStatus))
+
+		comps = append(comps, comp)
+	}
+
+	var costRange *schema.ResourceRange
+	if r.MonthlyCostRange != nil {
+		costRange = &schema.ResourceRange{
+			Low:  &schema.Resource{Name: r.Name, MonthlyCost: r.MonthlyCostRange.LowMonthlyCost},
+			High: &schema.Resource{Name: r.Name, MonthlyCost: r.MonthlyCostRange.HighMonthlyCost},
+		}
+	}
+
+	return &schema.Resource{
+		Name:           r.Name,
+		ResourceType:   r.ResourceType,
+		Tags:           r.Tags,
+		SourceLocation: r.SourceLocation,
+		HourlyCost:     r.HourlyCost,
+		MonthlyCost:    r.MonthlyCost,
+		CostComponents: comps,
+		SubResources:   schemaResources(r.SubResources),
+		Range:          costRange,
+	}
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var csvHeader = []string{
+	"Project",
+	"Resource",
+	"Resource type",
+	"Tags",
+	"Cost component",
+	"Unit",
+	"Monthly quantity",
+	"Price",
+	"Hourly cost",
+	"Monthly cost",
+}
+
+// ToCSV outputs a row for every cost component so the costs can be pivoted in a
+// spreadsheet. Sub resources are flattened using their full address, e.g.
+// aws_instance.web.root_block_device. Quantities and costs are output unformatted.
+func ToCSV(out Root, opts Options) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	w := csv.NewWriter(buf)
+
+	err := w.Write(csvHeader)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	for _, project := range out.Projects {
+		if project.Breakdown == nil {
+			continue
+		}
+
+		for _, r := range project.Breakdown.Resources {
+			err = w.WriteAll(csvResourceRows(project.Label(opts.DashboardEnabled), r, "", r.ResourceType))
+			if err != nil {
+				return []byte{}, err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return []byte{}, err
+	}
+
+	// The commands print a trailing newline
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func csvResourceRows(projectLabel string, r Resource, prefix string, resourceType string) [][]string {
+	rows := make([][]string, 0, len(r.CostComponents))
+
+	address := prefix + r.Name
+	tags := csvTags(r.Tags)
+
+	for _, c := range r.CostComponents {
+		rows = append(rows, []string{
+			projectLabel,
+			address,
+			resourceType,
+			tags,
+			c.Name,
+			c.Unit,
+			csvDecimal(c.MonthlyQuantity),
+			c.Price.String(),
+			csvDecimal(c.HourlyCost),
+			csvDecimal(c.MonthlyCost),
+		})
+	}
+
+	// Sub resources don't have their own tags or type so they use their parent's
+	for _, s := range r.SubResources {
+		if s.Tags == nil {
+			s.Tags = r.Tags
+		}
+		rows = append(rows, csvResourceRows(projectLabel, s, address+".", resourceType)...)
+	}
+
+	return rows
+}
+
+// csvTags formats the tags as key=value pairs separated by semicolons, sorted by key.
+func csvTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, tags[k]))
+	}
+
+	return strings.Join(pairs, ";")
+}
+
+func csvDecimal(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+
+	return d.String()
+}
@@ -1,12 +1,42 @@
 package output
 
 import (
+	"fmt"
+
 	"github.com/dustin/go-humanize"
 	"github.com/shopspring/decimal"
 )
 
 var roundCostsAbove = 100
 
+var currencySymbols = map[string]string{
+	"AUD": "A$",
+	"BRL": "R$",
+	"CAD": "CA$",
+	"CNY": "CN¥",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "JP¥",
+	"KRW": "₩",
+	"NZD": "NZ$",
+	"USD": "$",
+}
+
+// currencySymbol returns the symbol for the currency code. Currencies without a
+// known symbol are prefixed with their code instead, e.g. "CHF 10".
+func currencySymbol(currency string) string {
+	if currency == "" {
+		return "$"
+	}
+
+	if sym, ok := currencySymbols[currency]; ok {
+		return sym
+	}
+
+	return currency + " "
+}
+
 func formatQuantity(q *decimal.Decimal) string {
 	if q == nil {
 		return "-"
@@ -15,7 +45,12 @@ func formatQuantity(q *decimal.Decimal) string {
 	return humanize.CommafWithDigits(f, 4)
 }
 
-func formatCost(d *decimal.Decimal) string {
+// FormatCost formats the cost in the currency the same way as the outputs, e.g. €1,234.
+func FormatCost(currency string, d *decimal.Decimal) string {
+	return formatCost(currency, d)
+}
+
+func formatCost(currency string, d *decimal.Decimal) string {
 	if d == nil {
 		return "-"
 	}
@@ -27,10 +62,10 @@ func formatCost(d *decimal.Decimal) string {
 		s = humanize.FormatFloat("#,###.", f)
 	}
 
-	return "$" + s
+	return currencySymbol(currency) + s
 }
 
-func formatCost2DP(d *decimal.Decimal) string {
+func formatCost2DP(currency string, d *decimal.Decimal) string {
 	if d == nil {
 		return "-"
 	}
@@ -38,16 +73,34 @@ func formatCost2DP(d *decimal.Decimal) string {
 	f, _ := d.Float64()
 
 	s := humanize.FormatFloat("#,###.##", f)
-	return "$" + s
+	return currencySymbol(currency) + s
+}
+
+// formatCostRange formats the low and high monthly costs of a range, e.g. "$10 - $50".
+func formatCostRange(currency string, r *CostRange) string {
+	if r == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s - %s", formatCost2DP(currency, r.LowMonthlyCost), formatCost2DP(currency, r.HighMonthlyCost))
+}
+
+// formatCostChangeRange formats the low and high changes of a range, e.g. "+$10 - +$50".
+func formatCostChangeRange(currency string, r *CostRange) string {
+	if r == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s - %s", formatCostChange(currency, r.LowMonthlyCost), formatCostChange(currency, r.HighMonthlyCost))
 }
 
-func formatPrice(d decimal.Decimal) string {
+func formatPrice(currency string, d decimal.Decimal) string {
 	if d.LessThan(decimal.NewFromFloat(0.1)) {
-		return "$" + d.String()
+		return currencySymbol(currency) + d.String()
 	}
 
 	f, _ := d.Float64()
 
 	s := humanize.FormatFloat("#,###.##", f)
-	return "$" + s
+	return currencySymbol(currency) + s
 }
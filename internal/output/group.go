@@ -0,0 +1,152 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+var groupByTagPrefix = "tag:"
+
+// noGroupLabel is shown for resources that don't have a value for the group,
+// e.g. resources without the tag or resources in the root module.
+var noGroupLabel = "(none)"
+
+type Group struct {
+	Name             string           `json:"name"`
+	ResourceCount    int              `json:"resourceCount"`
+	TotalHourlyCost  *decimal.Decimal `json:"totalHourlyCost"`
+	TotalMonthlyCost *decimal.Decimal `json:"totalMonthlyCost"`
+}
+
+// ValidateGroupBy returns an error if the group by option isn't one of type,
+// provider, module or tag:<key>.
+func ValidateGroupBy(groupBy string) error {
+	switch {
+	case groupBy == "", groupBy == "type", groupBy == "provider", groupBy == "module":
+		return nil
+	case strings.HasPrefix(groupBy, groupByTagPrefix) && len(groupBy) > len(groupByTagPrefix):
+		return nil
+	}
+
+	return fmt.Errorf("Invalid group by '%s', valid options are: type, provider, module, tag:<key>", groupBy)
+}
+
+// BuildGroups aggregates the costs of the top level resources in all the projects by the
+// group by option, sorted by the highest monthly cost.
+func BuildGroups(out Root, groupBy string) []Group {
+	if groupBy == "" {
+		return nil
+	}
+
+	groupMap := make(map[string]*Group)
+
+	for _, project := range out.Projects {
+		if project.Breakdown == nil {
+			continue
+		}
+
+		for _, r := range project.Breakdown.Resources {
+			name := resourceGroupName(r, groupBy)
+
+			g, ok := groupMap[name]
+			if !ok {
+				g = &Group{Name: name}
+				groupMap[name] = g
+			}
+
+			g.ResourceCount++
+			g.TotalHourlyCost = addDecimalPtrs(g.TotalHourlyCost, r.HourlyCost)
+			g.TotalMonthlyCost = addDecimalPtrs(g.TotalMonthlyCost, r.MonthlyCost)
+		}
+	}
+
+	groups := make([]Group, 0, len(groupMap))
+	for _, g := range groupMap {
+		groups = append(groups, *g)
+	}
+
+	sortGroups(groups)
+
+	return groups
+}
+
+// sortGroups sorts the groups by monthly cost, most expensive first.
+func sortGroups(groups []Group) {
+	sort.Slice(groups, func(i, j int) bool {
+		ci := decimal.Zero
+		if groups[i].TotalMonthlyCost != nil {
+			ci = *groups[i].TotalMonthlyCost
+		}
+
+		cj := decimal.Zero
+		if groups[j].TotalMonthlyCost != nil {
+			cj = *groups[j].TotalMonthlyCost
+		}
+
+		if ci.Equal(cj) {
+			return groups[i].Name < groups[j].Name
+		}
+
+		return ci.GreaterThan(cj)
+	})
+}
+
+func resourceGroupName(r Resource, groupBy string) string {
+	switch {
+	case groupBy == "type":
+		return resourceType(r)
+	case groupBy == "provider":
+		return resourceProvider(resourceType(r))
+	case groupBy == "module":
+		return strings.TrimSuffix(schema.AddressModulePart(r.Name), ".")
+	case strings.HasPrefix(groupBy, groupByTagPrefix):
+		return r.Tags[strings.TrimPrefix(groupBy, groupByTagPrefix)]
+	}
+
+	return ""
+}
+
+// resourceType returns the type of the resource. JSON from versions before 0.4 doesn't
+// have the resource type, so for Terraform resources it is taken from the address.
+func resourceType(r Resource) string {
+	if r.ResourceType != "" {
+		return r.ResourceType
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.Name, schema.AddressModulePart(r.Name)), ".", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[0]
+}
+
+// resourceProvider returns the provider of a resource type, e.g. aws for both
+// aws_instance and AWS::EC2::Instance.
+func resourceProvider(resourceType string) string {
+	if i := strings.Index(resourceType, "::"); i != -1 {
+		return strings.ToLower(resourceType[:i])
+	}
+
+	return strings.SplitN(resourceType, "_", 2)[0]
+}
+
+func groupLabel(name string) string {
+	if name == "" {
+		return noGroupLabel
+	}
+
+	return name
+}
+
+func groupByLabel(groupBy string) string {
+	if strings.HasPrefix(groupBy, groupByTagPrefix) {
+		return fmt.Sprintf("tag %s", strings.TrimPrefix(groupBy, groupByTagPrefix))
+	}
+
+	return groupBy
+}
@@ -5,11 +5,48 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/sprig"
+	"github.com/shopspring/decimal"
 )
 
+// maxHTMLChartBars is the number of bars shown in each chart, the rest are combined.
+const maxHTMLChartBars = 10
+
+// maxHTMLTagCharts is the number of tag keys that are charted when the output isn't
+// grouped by a tag. The keys used by the most resources are charted.
+const maxHTMLTagCharts = 3
+
+type htmlChart struct {
+	Title string
+	Bars  []htmlChartBar
+}
+
+type htmlChartBar struct {
+	Label       string
+	MonthlyCost *decimal.Decimal
+	Percent     float64
+}
+
+type htmlProjectDiff struct {
+	Project Project
+	Rows    []htmlDiffRow
+}
+
+type htmlDiffRow struct {
+	Name            string
+	PastMonthlyCost *decimal.Decimal
+	MonthlyCost     *decimal.Decimal
+	Change          *decimal.Decimal
+	Kind            string
+}
+
+// ToHTML outputs a self-contained HTML report. The styles and scripts are embedded so
+// the report can be published as a CI artifact and viewed offline. The resource tables
+// can be searched, filtered, sorted and collapsed, and there are charts of the costs by
+// project and tag and a view of the diff for projects that have one.
 func ToHTML(out Root, opts Options) ([]byte, error) {
 	var buf bytes.Buffer
 	bufw := bufio.NewWriter(&buf)
@@ -25,13 +62,32 @@ func ToHTML(out Root, opts Options) ([]byte, error) {
 			safe = strings.ReplaceAll(safe, "\n", "<br />")
 			return template.HTML(safe) // nolint:gosec
 		},
-		"contains":       contains,
-		"formatCost2DP":  formatCost2DP,
-		"formatPrice":    formatPrice,
+		"contains": contains,
+		"formatCost2DP": func(d *decimal.Decimal) string {
+			return formatCost2DP(out.Currency, d)
+		},
+		"formatPrice": func(d decimal.Decimal) string {
+			return formatPrice(out.Currency, d)
+		},
 		"formatQuantity": formatQuantity,
+		"formatCostChange": func(d *decimal.Decimal) string {
+			return formatCostChange(out.Currency, d)
+		},
+		"formatCostRange": func(r *CostRange) string {
+			return formatCostRange(out.Currency, r)
+		},
+		"decimalString": func(d *decimal.Decimal) string {
+			if d == nil {
+				return "0"
+			}
+			return d.String()
+		},
+		"resourceSearchText": resourceSearchText,
 		"projectLabel": func(p Project) string {
 			return p.Label(opts.DashboardEnabled)
 		},
+		"groupLabel":   groupLabel,
+		"groupByLabel": groupByLabel,
 	})
 	tmpl, err := tmpl.Parse(HTMLTemplate)
 	if err != nil {
@@ -39,12 +95,17 @@ func ToHTML(out Root, opts Options) ([]byte, error) {
 	}
 
 	unsupportedResourcesMessage := out.unsupportedResourcesMessage(opts.ShowSkipped)
+	priceMatchWarningsMessage := out.priceMatchWarningsMessage(false)
 
 	err = tmpl.Execute(bufw, struct {
 		Root                        Root
 		UnsupportedResourcesMessage string
+		PriceMatchWarningsMessage   string
 		Options                     Options
-	}{out, unsupportedResourcesMessage, opts})
+		Groups                      []Group
+		Charts                      []htmlChart
+		Diffs                       []htmlProjectDiff
+	}{out, unsupportedResourcesMessage, priceMatchWarningsMessage, opts, BuildGroups(out, opts.GroupBy), htmlCharts(out, opts.GroupBy), htmlDiffs(out)})
 	if err != nil {
 		return []byte{}, err
 	}
@@ -52,3 +113,193 @@ func ToHTML(out Root, opts Options) ([]byte, error) {
 	bufw.Flush()
 	return buf.Bytes(), nil
 }
+
+// htmlCharts returns a chart of the monthly cost of each project and a chart for the
+// tag key the output is grouped by, or for the tag keys used by the most resources if
+// it isn't grouped by a tag.
+func htmlCharts(out Root, groupBy string) []htmlChart {
+	charts := make([]htmlChart, 0)
+
+	if len(out.Projects) > 1 {
+		groups := make([]Group, 0, len(out.Projects))
+		for _, p := range out.Projects {
+			if p.Breakdown == nil {
+				continue
+			}
+
+			groups = append(groups, Group{
+				Name:             p.Name,
+				ResourceCount:    len(p.Breakdown.Resources),
+				TotalMonthlyCost: p.Breakdown.TotalMonthlyCost,
+			})
+		}
+		sortGroups(groups)
+
+		charts = append(charts, htmlChart{Title: "Monthly cost by project", Bars: htmlChartBars(groups)})
+	}
+
+	keys := []string{strings.TrimPrefix(groupBy, groupByTagPrefix)}
+	if !strings.HasPrefix(groupBy, groupByTagPrefix) {
+		keys = tagKeys(out)
+		if len(keys) > maxHTMLTagCharts {
+			keys = keys[:maxHTMLTagCharts]
+		}
+	}
+
+	for _, key := range keys {
+		tagGroupBy := groupByTagPrefix + key
+		charts = append(charts, htmlChart{
+			Title: fmt.Sprintf("Monthly cost by %s", groupByLabel(tagGroupBy)),
+			Bars:  htmlChartBars(BuildGroups(out, tagGroupBy)),
+		})
+	}
+
+	return charts
+}
+
+// htmlChartBars converts the groups to bars, sized relative to the most expensive
+// group. Any groups after the maximum number of bars are combined into one.
+func htmlChartBars(groups []Group) []htmlChartBar {
+	if len(groups) > maxHTMLChartBars {
+		other := Group{Name: fmt.Sprintf("%d others", len(groups)-maxHTMLChartBars+1)}
+		for _, g := range groups[maxHTMLChartBars-1:] {
+			other.TotalMonthlyCost = addDecimalPtrs(other.TotalMonthlyCost, g.TotalMonthlyCost)
+		}
+
+		groups = append(groups[:maxHTMLChartBars-1:maxHTMLChartBars-1], other)
+	}
+
+	max := decimal.Zero
+	for _, g := range groups {
+		if g.TotalMonthlyCost != nil && g.TotalMonthlyCost.GreaterThan(max) {
+			max = *g.TotalMonthlyCost
+		}
+	}
+
+	bars := make([]htmlChartBar, 0, len(groups))
+	for _, g := range groups {
+		percent := 0.0
+		if g.TotalMonthlyCost != nil && max.IsPositive() {
+			percent, _ = g.TotalMonthlyCost.Div(max).Mul(decimal.NewFromInt(100)).Float64()
+		}
+
+		bars = append(bars, htmlChartBar{
+			Label:       groupLabel(g.Name),
+			MonthlyCost: g.TotalMonthlyCost,
+			Percent:     percent,
+		})
+	}
+
+	return bars
+}
+
+// tagKeys returns the tag keys used by the resources, the ones used by the most
+// resources first.
+func tagKeys(out Root) []string {
+	keyMap := make(map[string]int)
+
+	for _, p := range out.Projects {
+		if p.Breakdown == nil {
+			continue
+		}
+
+		for _, r := range p.Breakdown.Resources {
+			for k := range r.Tags {
+				keyMap[k]++
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(keyMap))
+	for k := range keyMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keyMap[keys[i]] != keyMap[keys[j]] {
+			return keyMap[keys[i]] > keyMap[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// htmlDiffs returns the resources that have changed in each project with a diff, with
+// the biggest changes first.
+func htmlDiffs(out Root) []htmlProjectDiff {
+	diffs := make([]htmlProjectDiff, 0)
+
+	for _, p := range out.Projects {
+		if p.Diff == nil || len(p.Diff.Resources) == 0 {
+			continue
+		}
+
+		var pastResources, resources []Resource
+		if p.PastBreakdown != nil {
+			pastResources = p.PastBreakdown.Resources
+		}
+		if p.Breakdown != nil {
+			resources = p.Breakdown.Resources
+		}
+
+		rows := make([]htmlDiffRow, 0, len(p.Diff.Resources))
+		for _, d := range p.Diff.Resources {
+			row := htmlDiffRow{
+				Name:   d.Name,
+				Change: d.MonthlyCost,
+			}
+
+			past := findResourceByName(pastResources, d.Name)
+			current := findResourceByName(resources, d.Name)
+
+			switch {
+			case past == nil:
+				row.Kind = "added"
+			case current == nil:
+				row.Kind = "removed"
+			case d.MonthlyCost != nil && d.MonthlyCost.IsNegative():
+				row.Kind = "decreased"
+			default:
+				row.Kind = "increased"
+			}
+
+			if past != nil {
+				row.PastMonthlyCost = past.MonthlyCost
+			}
+			if current != nil {
+				row.MonthlyCost = current.MonthlyCost
+			}
+
+			rows = append(rows, row)
+		}
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			return decimalAbs(rows[i].Change).GreaterThan(decimalAbs(rows[j].Change))
+		})
+
+		diffs = append(diffs, htmlProjectDiff{Project: p, Rows: rows})
+	}
+
+	return diffs
+}
+
+func decimalAbs(d *decimal.Decimal) decimal.Decimal {
+	if d == nil {
+		return decimal.Zero
+	}
+
+	return d.Abs()
+}
+
+// resourceSearchText returns the text that the search box matches resources against.
+func resourceSearchText(r Resource) string {
+	tags := make([]string, 0, len(r.Tags))
+	for k, v := range r.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(tags)
+
+	parts := append([]string{r.Name, r.ResourceType}, tags...)
+
+	return strings.ToLower(strings.Join(parts, " "))
+}
@@ -5,5 +5,10 @@ import (
 )
 
 func ToJSON(out Root, opts Options) ([]byte, error) {
+	if opts.GroupBy != "" {
+		out.GroupBy = opts.GroupBy
+		out.Groups = BuildGroups(out, opts.GroupBy)
+	}
+
 	return json.Marshal(out)
 }
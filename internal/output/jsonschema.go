@@ -0,0 +1,182 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var (
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// JSONSchema returns the JSON Schema of the Infracost JSON output, generated from the
+// output types so it can't get out of date. The published copy in schema/ is generated
+// from this with `make schema`.
+func JSONSchema() ([]byte, error) {
+	g := &jsonSchemaGenerator{definitions: make(map[string]interface{})}
+
+	g.typeSchema(reflect.TypeOf(Root{}))
+
+	// Inline the root type so validation errors aren't nested under a reference
+	s := g.definitions["Root"].(map[string]interface{})
+	delete(g.definitions, "Root")
+
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = "Infracost JSON output"
+	s["description"] = fmt.Sprintf("Infracost JSON output version %s", outputVersion)
+	s["definitions"] = g.definitions
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// Validate checks the Infracost JSON output against the JSON Schema, after migrating it
+// from older versions. The error lists every problem that was found.
+func Validate(data []byte) error {
+	data, err := migrate(data)
+	if err != nil {
+		return err
+	}
+
+	s, err := JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(s), gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return errors.Wrap(err, "Error validating JSON")
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		msgs = append(msgs, e.String())
+	}
+
+	return fmt.Errorf("Invalid Infracost JSON:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+type jsonSchemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+func (g *jsonSchemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case decimalType:
+		return map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?$`}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.typeSchema(t.Elem()))
+	case reflect.Slice:
+		return nullable(map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())})
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())})
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+
+	return map[string]interface{}{}
+}
+
+// structSchema adds a definition for the struct and returns a reference to it. Fields
+// that are omitted when empty are optional and all other fields are required.
+func (g *jsonSchemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	ref := map[string]interface{}{"$ref": fmt.Sprintf("#/definitions/%s", t.Name())}
+
+	if _, ok := g.definitions[t.Name()]; ok {
+		return ref
+	}
+
+	// Add a placeholder first in case the type refers to itself, e.g. sub-resources
+	g.definitions[t.Name()] = nil
+
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := parseJSONTag(f)
+		if name == "-" {
+			continue
+		}
+
+		properties[name] = g.typeSchema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	sort.Strings(required)
+
+	g.definitions[t.Name()] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+
+	return ref
+}
+
+func parseJSONTag(f reflect.StructField) (string, string) {
+	tag := f.Tag.Get("json")
+	parts := strings.SplitN(tag, ",", 2)
+
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	opts := ""
+	if len(parts) > 1 {
+		opts = parts[1]
+	}
+
+	return name, opts
+}
+
+// nullable allows the value to be null. Types are extended rather than wrapped where
+// possible since that gives clearer validation errors.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []string{t, "null"}
+		return s
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{map[string]interface{}{"type": "null"}, s},
+	}
+}
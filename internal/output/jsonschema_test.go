@@ -0,0 +1,115 @@
+package output
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestJSONSchemaUpToDate(t *testing.T) {
+	published, err := ioutil.ReadFile("../../schema/infracost.schema.json")
+	assert.Equal(t, nil, err)
+
+	generated, err := JSONSchema()
+	assert.Equal(t, nil, err)
+
+	// Run `make schema` if this fails
+	assert.Equal(t, string(published), string(generated))
+}
+
+func TestValidate(t *testing.T) {
+	r := Root{
+		Version:          outputVersion,
+		Currency:         "USD",
+		TotalMonthlyCost: decimalPtr(decimal.NewFromFloat(12.5)),
+		TimeGenerated:    time.Now(),
+		Projects: []Project{
+			{
+				Name:     "web",
+				Metadata: &schema.ProjectMetadata{Path: "infra/web", Type: "terraform_dir"},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:        "aws_instance.web",
+							Metadata:    map[string]string{},
+							MonthlyCost: decimalPtr(decimal.NewFromFloat(12.5)),
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", Unit: "hours", Price: decimal.NewFromFloat(0.0171)},
+							},
+						},
+					},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromFloat(12.5)),
+				},
+			},
+		},
+	}
+
+	b, err := ToJSON(r, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, Validate(b))
+
+	err = Validate([]byte(`{"version": "0.4", "currency": "USD", "projects": [{"name": 1}]}`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestLoadMigratesV01(t *testing.T) {
+	data := []byte(`{
+		"version": "0.1",
+		"projects": [
+			{
+				"path": "infra/web",
+				"metadata": {"terraformWorkspace": "prod"},
+				"pastBreakdown": null,
+				"breakdown": {"resources": [], "totalHourlyCost": "0", "totalMonthlyCost": "0"},
+				"diff": null,
+				"summary": null
+			}
+		],
+		"totalHourlyCost": "0",
+		"totalMonthlyCost": "0",
+		"timeGenerated": "2021-06-01T00:00:00Z",
+		"summary": null
+	}`)
+
+	r, err := Load(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, outputVersion, r.Version)
+	assert.Equal(t, "USD", r.Currency)
+	assert.Equal(t, "infra/web", r.Projects[0].Name)
+	assert.Equal(t, "infra/web", r.Projects[0].Metadata.Path)
+	assert.Equal(t, "prod", r.Projects[0].Metadata.TerraformWorkspace)
+
+	assert.Equal(t, nil, Validate(data))
+}
+
+func TestValidateV02WithoutCurrency(t *testing.T) {
+	data := []byte(`{
+		"version": "0.2",
+		"projects": [
+			{
+				"name": "infra/web",
+				"metadata": {"path": "infra/web", "type": "terraform_dir"},
+				"pastBreakdown": null,
+				"breakdown": {"resources": [], "totalHourlyCost": "0", "totalMonthlyCost": "0"},
+				"diff": null,
+				"summary": null
+			}
+		],
+		"totalHourlyCost": "0",
+		"totalMonthlyCost": "0",
+		"timeGenerated": "2021-06-01T00:00:00Z",
+		"summary": null
+	}`)
+
+	assert.Equal(t, nil, Validate(data))
+
+	r, err := Load(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, outputVersion, r.Version)
+	assert.Equal(t, "USD", r.Currency)
+	assert.Equal(t, "infra/web", r.Projects[0].Name)
+}
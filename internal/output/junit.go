@@ -0,0 +1,147 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/infracost/infracost/internal/ui"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Contents string `xml:",cdata"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",cdata"`
+}
+
+// ToJUnit outputs a JUnit XML report so CI systems can show the costs alongside test
+// results. Each project is a test case with its cost breakdown in the output, and each
+// budget and policy check is a test case that fails with the cost breakdown of the
+// projects it relates to.
+func ToJUnit(out Root, opts Options) ([]byte, error) {
+	projectSuite := junitTestSuite{Name: "Projects"}
+
+	for _, project := range out.Projects {
+		projectSuite.TestCases = append(projectSuite.TestCases, junitTestCase{
+			Name:      project.Label(opts.DashboardEnabled),
+			Classname: "infracost.project",
+			SystemOut: &junitOutput{Contents: junitProjectsBreakdown(out, opts, []string{project.Name})},
+		})
+	}
+
+	suites := []junitTestSuite{projectSuite}
+
+	budgetSuite := junitTestSuite{Name: "Budgets"}
+	policySuite := junitTestSuite{Name: "Policies"}
+
+	for _, check := range opts.Checks {
+		tc := junitTestCase{
+			Name:      check.Name,
+			Classname: fmt.Sprintf("infracost.%s", check.Type),
+		}
+
+		if check.Failed() {
+			tc.Failure = &junitFailure{
+				Message:  strings.Join(check.Failures, "; "),
+				Type:     check.Type,
+				Contents: strings.Join(check.Failures, "\n") + "\n\n" + junitProjectsBreakdown(out, opts, check.Projects),
+			}
+		}
+
+		if check.Type == BudgetCheck {
+			budgetSuite.TestCases = append(budgetSuite.TestCases, tc)
+		} else {
+			policySuite.TestCases = append(policySuite.TestCases, tc)
+		}
+	}
+
+	for _, s := range []junitTestSuite{budgetSuite, policySuite} {
+		if len(s.TestCases) > 0 {
+			suites = append(suites, s)
+		}
+	}
+
+	root := junitTestSuites{Name: "infracost"}
+
+	for i := range suites {
+		suites[i].Tests = len(suites[i].TestCases)
+		for _, tc := range suites[i].TestCases {
+			if tc.Failure != nil {
+				suites[i].Failures++
+			}
+		}
+
+		root.Tests += suites[i].Tests
+		root.Failures += suites[i].Failures
+	}
+
+	root.Suites = suites
+
+	b, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+// junitProjectsBreakdown returns the cost breakdown table of the named projects, or all
+// projects if no names are given, without any colors.
+func junitProjectsBreakdown(out Root, opts Options, names []string) string {
+	filtered := out
+	filtered.Projects = make([]Project, 0, len(out.Projects))
+
+	for _, p := range out.Projects {
+		if len(names) == 0 || contains(names, p.Name) {
+			filtered.Projects = append(filtered.Projects, p)
+		}
+	}
+
+	if len(names) > 0 {
+		filtered.TotalMonthlyCost = nil
+		for _, p := range filtered.Projects {
+			if p.Breakdown != nil {
+				filtered.TotalMonthlyCost = addDecimalPtrs(filtered.TotalMonthlyCost, p.Breakdown.TotalMonthlyCost)
+			}
+		}
+	}
+
+	tableOpts := opts
+	tableOpts.GroupBy = ""
+	if len(tableOpts.Fields) == 0 {
+		tableOpts.Fields = []string{"monthlyQuantity", "unit", "monthlyCost"}
+	}
+
+	b, err := ToTable(filtered, tableOpts)
+	if err != nil {
+		return ""
+	}
+
+	return ui.StripColor(string(b))
+}
@@ -0,0 +1,400 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ToMarkdown renders the output as GitHub flavored markdown so it can be posted as a pull
+// request comment. If any of the projects have a diff the cost changes are shown,
+// otherwise the breakdown of the monthly costs is shown.
+func ToMarkdown(out Root, opts Options) ([]byte, error) {
+	hasDiff := false
+	for _, project := range out.Projects {
+		if project.Diff != nil {
+			hasDiff = true
+			break
+		}
+	}
+
+	s := "## Infracost estimate\n\n"
+
+	hasNilCosts := false
+	if hasDiff {
+		s += markdownDiff(out, opts, &hasNilCosts)
+	} else {
+		s += markdownBreakdown(out, opts, &hasNilCosts)
+	}
+
+	warnings := out.priceMatchWarnings(hasDiff)
+	if len(warnings) > 0 {
+		s += fmt.Sprintf("\n%s Prices could not be matched exactly for %d cost components:\n\n", markdownEscape(priceMatchWarningMarker), len(warnings))
+		for _, w := range warnings {
+			s += fmt.Sprintf("- %s\n", markdownEscape(w))
+		}
+	}
+
+	if hasNilCosts {
+		s += "\nTo estimate usage-based resources use --usage-file, see https://infracost.io/usage-file\n"
+	}
+
+	unsupportedMsg := out.unsupportedResourcesMessage(opts.ShowSkipped)
+	if unsupportedMsg != "" {
+		s += "\n" + unsupportedMsg + "\n"
+	}
+
+	return []byte(strings.TrimSuffix(s, "\n")), nil
+}
+
+func markdownDiff(out Root, opts Options, hasNilCosts *bool) string {
+	s := ""
+
+	var totalOldCost, totalNewCost *decimal.Decimal
+	var totalDiffRange *CostRange
+	hasRanges := false
+	rows := ""
+	projectCount := 0
+
+	for _, project := range out.Projects {
+		if project.Diff == nil {
+			continue
+		}
+
+		projectCount++
+
+		var oldCost, newCost *decimal.Decimal
+		if project.PastBreakdown != nil {
+			oldCost = project.PastBreakdown.TotalMonthlyCost
+		}
+		if project.Breakdown != nil {
+			newCost = project.Breakdown.TotalMonthlyCost
+		}
+
+		totalOldCost = addDecimalPtrs(totalOldCost, oldCost)
+		totalNewCost = addDecimalPtrs(totalNewCost, newCost)
+
+		hasRanges = hasRanges || project.Diff.TotalMonthlyCostRange != nil
+		totalDiffRange = addCostRanges(totalDiffRange, costRangeOrCost(project.Diff.TotalMonthlyCostRange, project.Diff.TotalMonthlyCost))
+
+		rows += fmt.Sprintf("| %s | %s | %s | %s |\n",
+			markdownEscape(project.Label(opts.DashboardEnabled)),
+			formatCost(out.Currency, oldCost),
+			formatCost(out.Currency, newCost),
+			markdownCostChange(out.Currency, project.Diff.TotalMonthlyCost, oldCost, newCost),
+		)
+	}
+
+	totalDiff := decimal.Zero
+	if totalNewCost != nil {
+		totalDiff = totalDiff.Add(*totalNewCost)
+	}
+	if totalOldCost != nil {
+		totalDiff = totalDiff.Sub(*totalOldCost)
+	}
+
+	percent := formatPercentChange(totalOldCost, totalNewCost)
+	if percent != "" {
+		percent = fmt.Sprintf(" (%s)", percent)
+	}
+
+	switch {
+	case totalDiff.IsPositive():
+		s += fmt.Sprintf("Monthly cost will increase by **%s**%s 📈\n\n", formatCost(out.Currency, &totalDiff), percent)
+	case totalDiff.IsNegative():
+		abs := totalDiff.Abs()
+		s += fmt.Sprintf("Monthly cost will decrease by **%s**%s 📉\n\n", formatCost(out.Currency, &abs), percent)
+	default:
+		s += "Monthly cost will not change\n\n"
+	}
+
+	if hasRanges {
+		s += fmt.Sprintf("Depending on usage the change will be between **%s** and **%s**\n\n",
+			formatCostChange(out.Currency, totalDiffRange.LowMonthlyCost),
+			formatCostChange(out.Currency, totalDiffRange.HighMonthlyCost),
+		)
+	}
+
+	s += "| Project | Previous | New | Diff |\n"
+	s += "| --- | ---: | ---: | ---: |\n"
+	s += rows
+
+	if projectCount > 1 {
+		s += fmt.Sprintf("| **Total** | **%s** | **%s** | **%s** |\n",
+			formatCost(out.Currency, totalOldCost),
+			formatCost(out.Currency, totalNewCost),
+			markdownCostChange(out.Currency, &totalDiff, totalOldCost, totalNewCost),
+		)
+	}
+
+	for _, project := range out.Projects {
+		if project.Diff == nil || len(project.Diff.Resources) == 0 {
+			continue
+		}
+
+		s += fmt.Sprintf("\n<details>\n<summary>Cost details for %s</summary>\n\n", markdownEscape(project.Label(opts.DashboardEnabled)))
+		s += "| Resource | Previous | New | Diff |\n"
+		s += "| --- | ---: | ---: | ---: |\n"
+
+		for _, diffResource := range project.Diff.Resources {
+			var oldResource, newResource *Resource
+			if project.PastBreakdown != nil {
+				oldResource = findResourceByName(project.PastBreakdown.Resources, diffResource.Name)
+			}
+			if project.Breakdown != nil {
+				newResource = findResourceByName(project.Breakdown.Resources, diffResource.Name)
+			}
+
+			if (newResource == nil || resourceHasNilCosts(*newResource)) &&
+				(oldResource == nil || resourceHasNilCosts(*oldResource)) {
+				*hasNilCosts = true
+			}
+
+			s += markdownResourceDiffRows(out.Currency, diffResource, oldResource, newResource, 0)
+		}
+
+		s += "\n</details>\n"
+	}
+
+	return s
+}
+
+func markdownResourceDiffRows(currency string, diffResource Resource, oldResource *Resource, newResource *Resource, level int) string {
+	op := UPDATED
+	if oldResource == nil {
+		op = ADDED
+	} else if newResource == nil {
+		op = REMOVED
+	}
+
+	var oldCost, newCost *decimal.Decimal
+	if oldResource != nil {
+		oldCost = oldResource.MonthlyCost
+	}
+	if newResource != nil {
+		newCost = newResource.MonthlyCost
+	}
+
+	name := fmt.Sprintf("%s %s", markdownOpChar(op), markdownEscape(diffResource.Name))
+	if level == 0 {
+		name = fmt.Sprintf("%s **%s**", markdownOpChar(op), markdownEscape(diffResource.Name))
+	}
+
+	s := fmt.Sprintf("| %s%s | %s | %s | %s |\n",
+		markdownIndent(level),
+		name,
+		formatCost(currency, oldCost),
+		formatCost(currency, newCost),
+		markdownCostChange(currency, diffResource.MonthlyCost, oldCost, newCost),
+	)
+
+	for _, diffComponent := range diffResource.CostComponents {
+		var oldComponent, newComponent *CostComponent
+		if oldResource != nil {
+			oldComponent = findCostComponentByName(oldResource.CostComponents, diffComponent.Name)
+		}
+		if newResource != nil {
+			newComponent = findCostComponentByName(newResource.CostComponents, diffComponent.Name)
+		}
+
+		s += markdownCostComponentDiffRow(currency, diffComponent, oldComponent, newComponent, level+1)
+	}
+
+	for _, diffSubResource := range diffResource.SubResources {
+		var oldSubResource, newSubResource *Resource
+		if oldResource != nil {
+			oldSubResource = findResourceByName(oldResource.SubResources, diffSubResource.Name)
+		}
+		if newResource != nil {
+			newSubResource = findResourceByName(newResource.SubResources, diffSubResource.Name)
+		}
+
+		s += markdownResourceDiffRows(currency, diffSubResource, oldSubResource, newSubResource, level+1)
+	}
+
+	return s
+}
+
+func markdownCostComponentDiffRow(currency string, diffComponent CostComponent, oldComponent *CostComponent, newComponent *CostComponent, level int) string {
+	op := UPDATED
+	if oldComponent == nil {
+		op = ADDED
+	} else if newComponent == nil {
+		op = REMOVED
+	}
+
+	var oldCost, newCost *decimal.Decimal
+	if oldComponent != nil {
+		oldCost = oldComponent.MonthlyCost
+	}
+	if newComponent != nil {
+		newCost = newComponent.MonthlyCost
+	}
+
+	diff := markdownCostChange(currency, diffComponent.MonthlyCost, oldCost, newCost)
+	if oldCost == nil && newCost == nil {
+		diff = fmt.Sprintf("Depends on usage: %s per %s", formatPriceChange(currency, diffComponent.Price), markdownEscape(diffComponent.Unit))
+	}
+
+	return fmt.Sprintf("| %s%s %s | %s | %s | %s |\n",
+		markdownIndent(level),
+		markdownOpChar(op),
+		markdownCostComponentName(diffComponent),
+		formatCost(currency, oldCost),
+		formatCost(currency, newCost),
+		diff,
+	)
+}
+
+func markdownBreakdown(out Root, opts Options, hasNilCosts *bool) string {
+	s := fmt.Sprintf("Monthly cost will be **%s**", formatCost2DP(out.Currency, out.TotalMonthlyCost))
+	if out.TotalMonthlyCostRange != nil {
+		s += fmt.Sprintf(" (usage range %s)", formatCostRange(out.Currency, out.TotalMonthlyCostRange))
+	}
+	s += "\n\n"
+
+	s += "| Project | Monthly cost |\n"
+	s += "| --- | ---: |\n"
+
+	projectCount := 0
+	for _, project := range out.Projects {
+		if project.Breakdown == nil {
+			continue
+		}
+
+		projectCount++
+
+		s += fmt.Sprintf("| %s | %s |\n",
+			markdownEscape(project.Label(opts.DashboardEnabled)),
+			formatCost2DP(out.Currency, project.Breakdown.TotalMonthlyCost),
+		)
+	}
+
+	if projectCount > 1 {
+		s += fmt.Sprintf("| **Total** | **%s** |\n", formatCost2DP(out.Currency, out.TotalMonthlyCost))
+	}
+
+	for _, project := range out.Projects {
+		if project.Breakdown == nil || len(project.Breakdown.Resources) == 0 {
+			continue
+		}
+
+		if breakdownHasNilCosts(*project.Breakdown) {
+			*hasNilCosts = true
+		}
+
+		s += fmt.Sprintf("\n<details>\n<summary>Cost details for %s</summary>\n\n", markdownEscape(project.Label(opts.DashboardEnabled)))
+		s += "| Resource | Monthly quantity | Unit | Monthly cost |\n"
+		s += "| --- | ---: | --- | ---: |\n"
+
+		for _, r := range project.Breakdown.Resources {
+			s += markdownResourceRows(out.Currency, r, 0)
+		}
+
+		s += "\n</details>\n"
+	}
+
+	return s
+}
+
+func markdownResourceRows(currency string, r Resource, level int) string {
+	name := markdownEscape(r.Name)
+	if level == 0 {
+		name = fmt.Sprintf("**%s**", name)
+	}
+
+	monthlyCost := formatCost2DP(currency, r.MonthlyCost)
+	if r.MonthlyCostRange != nil {
+		monthlyCost += fmt.Sprintf(" (%s)", formatCostRange(currency, r.MonthlyCostRange))
+	}
+
+	s := fmt.Sprintf("| %s%s | | | %s |\n", markdownIndent(level), name, monthlyCost)
+
+	for _, c := range r.CostComponents {
+		monthlyCost := formatCost2DP(currency, c.MonthlyCost)
+		if c.MonthlyCost == nil {
+			monthlyCost = fmt.Sprintf("Depends on usage: %s per %s", formatPrice(currency, c.Price), markdownEscape(c.Unit))
+		}
+
+		s += fmt.Sprintf("| %s%s | %s | %s | %s |\n",
+			markdownIndent(level+1),
+			markdownCostComponentName(c),
+			formatQuantity(c.MonthlyQuantity),
+			markdownEscape(c.Unit),
+			monthlyCost,
+		)
+	}
+
+	for _, sub := range r.SubResources {
+		s += markdownResourceRows(currency, sub, level+1)
+	}
+
+	return s
+}
+
+func markdownCostComponentName(c CostComponent) string {
+	name := markdownEscape(c.Name)
+	if c.PriceMatchWarning() != "" {
+		name += " " + markdownEscape(priceMatchWarningMarker)
+	}
+
+	return name
+}
+
+func markdownCostChange(currency string, d *decimal.Decimal, oldCost *decimal.Decimal, newCost *decimal.Decimal) string {
+	if d == nil {
+		return "-"
+	}
+
+	s := formatCostChange(currency, d)
+
+	percent := formatPercentChange(oldCost, newCost)
+	if percent != "" {
+		s += fmt.Sprintf(" (%s)", percent)
+	}
+
+	return s
+}
+
+func markdownOpChar(op int) string {
+	switch op {
+	case ADDED:
+		return "+"
+	case REMOVED:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// markdownIndent indents the first cell of nested rows since markdown tables don't
+// preserve leading whitespace.
+func markdownIndent(level int) string {
+	return strings.Repeat("&nbsp;&nbsp;&nbsp;&nbsp;", level)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func addDecimalPtrs(d1 *decimal.Decimal, d2 *decimal.Decimal) *decimal.Decimal {
+	if d1 == nil {
+		return d2
+	}
+
+	if d2 == nil {
+		return d1
+	}
+
+	return decimalPtr(d1.Add(*d2))
+}
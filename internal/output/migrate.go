@@ -0,0 +1,107 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// migrations upgrade the JSON output from a version to the next version, so files
+// generated by older versions of Infracost can still be loaded.
+var migrations = map[string]func(map[string]interface{}) error{
+	"0.1": migrateFromV01,
+	"0.2": migrateFromV02,
+	"0.3": migrateFromV03,
+}
+
+// migrate upgrades the JSON output to the current version. Versions that don't have
+// a migration are returned unchanged so the caller can decide whether to reject them.
+func migrate(data []byte) ([]byte, error) {
+	var out map[string]interface{}
+	err := json.Unmarshal(data, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := false
+
+	for {
+		version, _ := out["version"].(string)
+
+		m, ok := migrations[version]
+		if !ok {
+			break
+		}
+
+		err = m(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error migrating JSON from version %s", version)
+		}
+
+		migrated = true
+	}
+
+	if !migrated {
+		return data, nil
+	}
+
+	return json.Marshal(out)
+}
+
+// migrateFromV01 migrates from 0.1 to 0.2. In 0.1 projects were identified by their
+// path and had their metadata as a map of strings. In 0.2 projects have a name, and the
+// path is part of the metadata.
+func migrateFromV01(out map[string]interface{}) error {
+	projects, _ := out["projects"].([]interface{})
+
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Invalid project %v", p)
+		}
+
+		path, _ := project["path"].(string)
+		delete(project, "path")
+
+		metadata, _ := project["metadata"].(map[string]interface{})
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		if _, ok := metadata["path"]; !ok {
+			metadata["path"] = path
+		}
+		if _, ok := metadata["type"]; !ok {
+			metadata["type"] = ""
+		}
+		project["metadata"] = metadata
+
+		if _, ok := project["name"]; !ok {
+			project["name"] = path
+		}
+	}
+
+	out["version"] = "0.2"
+
+	return nil
+}
+
+// migrateFromV02 migrates from 0.2 to 0.3. The currency was added in 0.3 and earlier
+// versions were always in USD.
+func migrateFromV02(out map[string]interface{}) error {
+	if _, ok := out["currency"]; !ok {
+		out["currency"] = "USD"
+	}
+
+	out["version"] = "0.3"
+
+	return nil
+}
+
+// migrateFromV03 migrates from 0.3 to 0.4. The resource type was added to resources
+// in 0.4, but it is optional so nothing else needs to change.
+func migrateFromV03(out map[string]interface{}) error {
+	out["version"] = "0.4"
+
+	return nil
+}
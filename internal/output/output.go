@@ -10,17 +10,26 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-var outputVersion = "0.2"
+var outputVersion = "0.4"
+
+// priceMatchWarningMarker is shown next to cost components whose price could not be matched exactly.
+var priceMatchWarningMarker = "*"
 
 type Root struct {
 	Version          string           `json:"version"`
 	RunID            string           `json:"runId,omitempty"`
+	Currency         string           `json:"currency"`
 	Projects         []Project        `json:"projects"`
 	TotalHourlyCost  *decimal.Decimal `json:"totalHourlyCost"`
 	TotalMonthlyCost *decimal.Decimal `json:"totalMonthlyCost"`
 	TimeGenerated    time.Time        `json:"timeGenerated"`
 	Summary          *Summary         `json:"summary"`
 	FullSummary      *Summary         `json:"-"`
+	GroupBy          string           `json:"groupBy,omitempty"`
+	Groups           []Group          `json:"groups,omitempty"`
+
+	// TotalMonthlyCostRange is only set if any of the resources have usage ranges.
+	TotalMonthlyCostRange *CostRange `json:"totalMonthlyCostRange,omitempty"`
 }
 
 type Project struct {
@@ -44,26 +53,46 @@ type Breakdown struct {
 	Resources        []Resource       `json:"resources"`
 	TotalHourlyCost  *decimal.Decimal `json:"totalHourlyCost"`
 	TotalMonthlyCost *decimal.Decimal `json:"totalMonthlyCost"`
+
+	// TotalMonthlyCostRange is only set if any of the resources have usage ranges.
+	TotalMonthlyCostRange *CostRange `json:"totalMonthlyCostRange,omitempty"`
+}
+
+// CostRange is the monthly cost with the low and high usage estimates.
+type CostRange struct {
+	LowMonthlyCost  *decimal.Decimal `json:"lowMonthlyCost"`
+	HighMonthlyCost *decimal.Decimal `json:"highMonthlyCost"`
 }
 
 type CostComponent struct {
-	Name            string           `json:"name"`
-	Unit            string           `json:"unit"`
-	HourlyQuantity  *decimal.Decimal `json:"hourlyQuantity"`
-	MonthlyQuantity *decimal.Decimal `json:"monthlyQuantity"`
-	Price           decimal.Decimal  `json:"price"`
-	HourlyCost      *decimal.Decimal `json:"hourlyCost"`
-	MonthlyCost     *decimal.Decimal `json:"monthlyCost"`
+	Name             string           `json:"name"`
+	Unit             string           `json:"unit"`
+	HourlyQuantity   *decimal.Decimal `json:"hourlyQuantity"`
+	MonthlyQuantity  *decimal.Decimal `json:"monthlyQuantity"`
+	Price            decimal.Decimal  `json:"price"`
+	HourlyCost       *decimal.Decimal `json:"hourlyCost"`
+	MonthlyCost      *decimal.Decimal `json:"monthlyCost"`
+	PriceMatchStatus string           `json:"priceMatchStatus,omitempty"`
+}
+
+// PriceMatchWarning returns a warning if the price for the cost component could not be matched exactly.
+func (c CostComponent) PriceMatchWarning() string {
+	return schema.PriceMatchStatus(c.PriceMatchStatus).Message()
 }
 
 type Resource struct {
-	Name           string            `json:"name"`
-	Tags           map[string]string `json:"tags,omitempty"`
-	Metadata       map[string]string `json:"metadata"`
-	HourlyCost     *decimal.Decimal  `json:"hourlyCost"`
-	MonthlyCost    *decimal.Decimal  `json:"monthlyCost"`
-	CostComponents []CostComponent   `json:"costComponents,omitempty"`
-	SubResources   []Resource        `json:"subresources,omitempty"`
+	Name           string                 `json:"name"`
+	ResourceType   string                 `json:"resourceType,omitempty"`
+	Tags           map[string]string      `json:"tags,omitempty"`
+	SourceLocation *schema.SourceLocation `json:"sourceLocation,omitempty"`
+	Metadata       map[string]string      `json:"metadata"`
+	HourlyCost     *decimal.Decimal       `json:"hourlyCost"`
+	MonthlyCost    *decimal.Decimal       `json:"monthlyCost"`
+	CostComponents []CostComponent        `json:"costComponents,omitempty"`
+	SubResources   []Resource             `json:"subresources,omitempty"`
+
+	// MonthlyCostRange is only set if any of the resource's usage values are ranges.
+	MonthlyCostRange *CostRange `json:"monthlyCostRange,omitempty"`
 }
 
 type Summary struct {
@@ -87,6 +116,27 @@ type Options struct {
 	GroupLabel       string
 	GroupKey         string
 	Fields           []string
+	GroupBy          string
+	Checks           []Check
+}
+
+const (
+	BudgetCheck = "budget"
+	PolicyCheck = "policy"
+)
+
+// Check is the result of a budget or policy check.
+type Check struct {
+	Type     string
+	Name     string
+	Failures []string
+	// Projects are the names of the projects the failures relate to. If it's empty the
+	// failures relate to all the projects.
+	Projects []string
+}
+
+func (c Check) Failed() bool {
+	return len(c.Failures) > 0
 }
 
 func outputBreakdown(resources []*schema.Resource) *Breakdown {
@@ -107,6 +157,8 @@ func outputBreakdown(resources []*schema.Resource) *Breakdown {
 		Resources:        arr,
 		TotalHourlyCost:  totalMonthlyCost,
 		TotalMonthlyCost: totalHourlyCost,
+
+		TotalMonthlyCostRange: calculateTotalCostRange(arr),
 	}
 }
 
@@ -122,6 +174,8 @@ func outputResource(r *schema.Resource) Resource {
 			Price:           c.UnitMultiplierPrice(),
 			HourlyCost:      c.HourlyCost,
 			MonthlyCost:     c.MonthlyCost,
+
+			PriceMatchStatus: string(c.PriceMatchStatus()),
 		})
 	}
 
@@ -130,19 +184,33 @@ func outputResource(r *schema.Resource) Resource {
 		subresources = append(subresources, outputResource(s))
 	}
 
+	var costRange *CostRange
+	if r.Range != nil {
+		costRange = &CostRange{
+			LowMonthlyCost:  r.LowMonthlyCost(),
+			HighMonthlyCost: r.HighMonthlyCost(),
+		}
+	}
+
 	return Resource{
 		Name:           r.Name,
+		ResourceType:   r.ResourceType,
 		Metadata:       map[string]string{},
 		Tags:           r.Tags,
+		SourceLocation: r.SourceLocation,
 		HourlyCost:     r.HourlyCost,
 		MonthlyCost:    r.MonthlyCost,
 		CostComponents: comps,
 		SubResources:   subresources,
+
+		MonthlyCostRange: costRange,
 	}
 }
 
-func ToOutputFormat(projects []*schema.Project) Root {
+func ToOutputFormat(projects []*schema.Project, currency string) Root {
 	var totalMonthlyCost, totalHourlyCost *decimal.Decimal
+	var totalMonthlyCostRange *CostRange
+	hasRanges := false
 
 	outProjects := make([]Project, 0, len(projects))
 	summaries := make([]*Summary, 0, len(projects))
@@ -174,6 +242,11 @@ func ToOutputFormat(projects []*schema.Project) Root {
 			totalMonthlyCost = decimalPtr(totalMonthlyCost.Add(*breakdown.TotalMonthlyCost))
 		}
 
+		if breakdown != nil {
+			hasRanges = hasRanges || breakdown.TotalMonthlyCostRange != nil
+			totalMonthlyCostRange = addCostRanges(totalMonthlyCostRange, costRangeOrCost(breakdown.TotalMonthlyCostRange, breakdown.TotalMonthlyCost))
+		}
+
 		summary := BuildSummary(project.Resources, SummaryOptions{
 			OnlyFields: []string{"UnsupportedResourceCounts"},
 		})
@@ -193,14 +266,21 @@ func ToOutputFormat(projects []*schema.Project) Root {
 		})
 	}
 
+	if !hasRanges {
+		totalMonthlyCostRange = nil
+	}
+
 	out := Root{
 		Version:          outputVersion,
+		Currency:         currency,
 		Projects:         outProjects,
 		TotalHourlyCost:  totalHourlyCost,
 		TotalMonthlyCost: totalMonthlyCost,
 		TimeGenerated:    time.Now(),
 		Summary:          MergeSummaries(summaries),
 		FullSummary:      MergeSummaries(fullSummaries),
+
+		TotalMonthlyCostRange: totalMonthlyCostRange,
 	}
 
 	return out
@@ -258,6 +338,63 @@ func (r *Root) unsupportedResourcesMessage(showSkipped bool) string {
 	return msg
 }
 
+// priceMatchWarnings returns a warning for every cost component in the projects
+// whose price could not be matched exactly. If useDiff is set the diff
+// breakdowns are used instead of the current breakdowns.
+func (r *Root) priceMatchWarnings(useDiff bool) []string {
+	warnings := make([]string, 0)
+
+	for _, p := range r.Projects {
+		b := p.Breakdown
+		if useDiff {
+			b = p.Diff
+		}
+
+		if b == nil {
+			continue
+		}
+
+		for _, res := range b.Resources {
+			warnings = append(warnings, resourcePriceMatchWarnings(res, "")...)
+		}
+	}
+
+	return warnings
+}
+
+func resourcePriceMatchWarnings(r Resource, prefix string) []string {
+	warnings := make([]string, 0)
+
+	name := prefix + r.Name
+
+	for _, c := range r.CostComponents {
+		if msg := c.PriceMatchWarning(); msg != "" {
+			warnings = append(warnings, fmt.Sprintf("%s %s: %s", name, c.Name, msg))
+		}
+	}
+
+	for _, s := range r.SubResources {
+		warnings = append(warnings, resourcePriceMatchWarnings(s, name+".")...)
+	}
+
+	return warnings
+}
+
+// priceMatchWarningsMessage returns the footnote explaining the price match warning markers.
+func (r *Root) priceMatchWarningsMessage(useDiff bool) string {
+	warnings := r.priceMatchWarnings(useDiff)
+	if len(warnings) == 0 {
+		return ""
+	}
+
+	msg := fmt.Sprintf("%s Prices could not be matched exactly for %d cost components:", priceMatchWarningMarker, len(warnings))
+	for _, w := range warnings {
+		msg += "\n  " + w
+	}
+
+	return msg
+}
+
 func BuildSummary(resources []*schema.Resource, opts SummaryOptions) *Summary {
 	supportedResourceCounts := make(map[string]int)
 	unsupportedResourceCounts := make(map[string]int)
@@ -357,6 +494,49 @@ func calculateTotalCosts(resources []Resource) (*decimal.Decimal, *decimal.Decim
 	return totalHourlyCost, totalMonthlyCost
 }
 
+// calculateTotalCostRange returns the total monthly cost range of the resources, using
+// the monthly cost of the resources that don't have a range. It returns nil if none of
+// the resources have a range.
+func calculateTotalCostRange(resources []Resource) *CostRange {
+	var total *CostRange
+	hasRanges := false
+
+	for _, r := range resources {
+		hasRanges = hasRanges || r.MonthlyCostRange != nil
+		total = addCostRanges(total, costRangeOrCost(r.MonthlyCostRange, r.MonthlyCost))
+	}
+
+	if !hasRanges {
+		return nil
+	}
+
+	return total
+}
+
+// costRangeOrCost returns the cost range, or a range with the same low and high cost if
+// there isn't one.
+func costRangeOrCost(r *CostRange, cost *decimal.Decimal) *CostRange {
+	if r != nil {
+		return r
+	}
+
+	return &CostRange{LowMonthlyCost: cost, HighMonthlyCost: cost}
+}
+
+func addCostRanges(r1 *CostRange, r2 *CostRange) *CostRange {
+	if r1 == nil {
+		r1 = &CostRange{}
+	}
+	if r2 == nil {
+		r2 = &CostRange{}
+	}
+
+	return &CostRange{
+		LowMonthlyCost:  addDecimalPtrs(r1.LowMonthlyCost, r2.LowMonthlyCost),
+		HighMonthlyCost: addDecimalPtrs(r1.HighMonthlyCost, r2.HighMonthlyCost),
+	}
+}
+
 func sortResources(resources []Resource, groupKey string) {
 	sort.Slice(resources, func(i, j int) bool {
 		// If an empty group key is passed just sort by name
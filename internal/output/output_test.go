@@ -1,8 +1,14 @@
 package output
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/infracost/infracost/internal/ui"
 	"github.com/shopspring/decimal"
 	"gopkg.in/go-playground/assert.v1"
 )
@@ -32,3 +38,635 @@ func TestCalculateTotalCosts(t *testing.T) {
 	actual, _ = totalMonthlyCost.Float64()
 	assert.Equal(t, expected, actual)
 }
+
+func TestFormatCostCurrency(t *testing.T) {
+	d := decimal.NewFromFloat(1234.5)
+
+	assert.Equal(t, "$1,234.50", formatCost2DP("", &d))
+	assert.Equal(t, "$1,234.50", formatCost2DP("USD", &d))
+	assert.Equal(t, "€1,234.50", formatCost2DP("EUR", &d))
+	assert.Equal(t, "£1,234.50", formatCost2DP("GBP", &d))
+	assert.Equal(t, "CHF 1,234.50", formatCost2DP("CHF", &d))
+	assert.Equal(t, "CN¥1,234.50", formatCost2DP("CNY", &d))
+	assert.Equal(t, "JP¥1,234.50", formatCost2DP("JPY", &d))
+}
+
+func TestCombineCurrencies(t *testing.T) {
+	_, err := Combine([]ReportInput{
+		{Root: Root{Currency: "USD"}},
+		{Root: Root{}},
+	}, Options{})
+	assert.Equal(t, nil, err)
+
+	_, err = Combine([]ReportInput{
+		{Root: Root{Currency: "USD"}},
+		{Root: Root{Currency: "EUR"}},
+	}, Options{})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestPriceMatchWarnings(t *testing.T) {
+	r := Root{
+		Projects: []Project{
+			{
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name: "aws_instance.web",
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", PriceMatchStatus: "multiple_products"},
+								{Name: "CPU credits"},
+							},
+							SubResources: []Resource{
+								{
+									Name:           "root_block_device",
+									CostComponents: []CostComponent{{Name: "Storage", PriceMatchStatus: "no_prices"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"aws_instance.web Instance usage: Multiple products found, using the first product",
+		"aws_instance.web.root_block_device Storage: No prices found, using 0.00",
+	}, r.priceMatchWarnings(false))
+}
+
+func TestToMarkdownDiff(t *testing.T) {
+	r := Root{
+		Currency: "USD",
+		Projects: []Project{
+			{
+				Name: "infracost/example",
+				PastBreakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:           "aws_instance.web",
+							MonthlyCost:    decimalPtr(decimal.NewFromInt(100)),
+							CostComponents: []CostComponent{{Name: "Instance usage", MonthlyCost: decimalPtr(decimal.NewFromInt(100))}},
+						},
+					},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(100)),
+				},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:           "aws_instance.web",
+							MonthlyCost:    decimalPtr(decimal.NewFromInt(150)),
+							CostComponents: []CostComponent{{Name: "Instance usage", MonthlyCost: decimalPtr(decimal.NewFromInt(150))}},
+						},
+					},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(150)),
+				},
+				Diff: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:           "aws_instance.web",
+							MonthlyCost:    decimalPtr(decimal.NewFromInt(50)),
+							CostComponents: []CostComponent{{Name: "Instance usage", MonthlyCost: decimalPtr(decimal.NewFromInt(50))}},
+						},
+					},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(50)),
+				},
+			},
+		},
+	}
+
+	b, err := ToMarkdown(r, Options{})
+	assert.Equal(t, nil, err)
+
+	expected := `## Infracost estimate
+
+Monthly cost will increase by **$50.00** (+50%) 📈
+
+| Project | Previous | New | Diff |
+| --- | ---: | ---: | ---: |
+| infracost/example | $100 | $150 | +$50.00 (+50%) |
+
+<details>
+<summary>Cost details for infracost/example</summary>
+
+| Resource | Previous | New | Diff |
+| --- | ---: | ---: | ---: |
+| ~ **aws\_instance.web** | $100 | $150 | +$50.00 (+50%) |
+| &nbsp;&nbsp;&nbsp;&nbsp;~ Instance usage | $100 | $150 | +$50.00 (+50%) |
+
+</details>`
+
+	assert.Equal(t, expected, string(b))
+}
+
+func TestToCSV(t *testing.T) {
+	r := Root{
+		Projects: []Project{
+			{
+				Name: "infracost/example",
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:         "aws_instance.web",
+							ResourceType: "aws_instance",
+							Tags:         map[string]string{"team": "web", "env": "prod"},
+							CostComponents: []CostComponent{
+								{
+									Name:            "Instance usage (Linux/UNIX, on-demand, t3.medium)",
+									Unit:            "hours",
+									MonthlyQuantity: decimalPtr(decimal.NewFromInt(730)),
+									Price:           decimal.NewFromFloat(0.0416),
+									HourlyCost:      decimalPtr(decimal.NewFromFloat(0.0416)),
+									MonthlyCost:     decimalPtr(decimal.NewFromFloat(30.368)),
+								},
+							},
+							SubResources: []Resource{
+								{
+									Name: "root_block_device",
+									CostComponents: []CostComponent{
+										{Name: "Storage (general purpose SSD, gp2)", Unit: "GB", Price: decimal.NewFromFloat(0.1)},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	b, err := ToCSV(r, Options{})
+	assert.Equal(t, nil, err)
+
+	expected := `Project,Resource,Resource type,Tags,Cost component,Unit,Monthly quantity,Price,Hourly cost,Monthly cost
+infracost/example,aws_instance.web,aws_instance,env=prod;team=web,"Instance usage (Linux/UNIX, on-demand, t3.medium)",hours,730,0.0416,0.0416,30.368
+infracost/example,aws_instance.web.root_block_device,aws_instance,env=prod;team=web,"Storage (general purpose SSD, gp2)",GB,,0.1,,`
+
+	assert.Equal(t, expected, string(b))
+}
+
+func TestBuildGroups(t *testing.T) {
+	r := Root{
+		Projects: []Project{
+			{
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web", ResourceType: "aws_instance", Tags: map[string]string{"team": "web"}, MonthlyCost: decimalPtr(decimal.NewFromInt(10))},
+						{Name: "module.db.aws_db_instance.db", ResourceType: "aws_db_instance", Tags: map[string]string{"team": "data"}, MonthlyCost: decimalPtr(decimal.NewFromInt(50))},
+						{Name: "module.db.aws_instance.bastion", ResourceType: "aws_instance", MonthlyCost: decimalPtr(decimal.NewFromInt(5))},
+					},
+				},
+			},
+			{
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "Topic", ResourceType: "AWS::SNS::Topic"},
+						{Name: "google_compute_instance.app", ResourceType: "google_compute_instance", Tags: map[string]string{"team": "web"}, MonthlyCost: decimalPtr(decimal.NewFromInt(20))},
+					},
+				},
+			},
+		},
+	}
+
+	groupCosts := func(groups []Group) map[string]string {
+		m := make(map[string]string)
+		for _, g := range groups {
+			c := "-"
+			if g.TotalMonthlyCost != nil {
+				c = g.TotalMonthlyCost.String()
+			}
+			m[g.Name] = fmt.Sprintf("%d %s", g.ResourceCount, c)
+		}
+		return m
+	}
+
+	assert.Equal(t, map[string]string{"aws_db_instance": "1 50", "google_compute_instance": "1 20", "aws_instance": "2 15", "AWS::SNS::Topic": "1 -"}, groupCosts(BuildGroups(r, "type")))
+	assert.Equal(t, map[string]string{"aws": "4 65", "google": "1 20"}, groupCosts(BuildGroups(r, "provider")))
+	assert.Equal(t, map[string]string{"module.db": "2 55", "": "3 30"}, groupCosts(BuildGroups(r, "module")))
+	assert.Equal(t, map[string]string{"web": "2 30", "data": "1 50", "": "2 5"}, groupCosts(BuildGroups(r, "tag:team")))
+
+	groups := BuildGroups(r, "tag:team")
+	assert.Equal(t, "data", groups[0].Name)
+	assert.Equal(t, "web", groups[1].Name)
+
+	assert.Equal(t, nil, ValidateGroupBy("tag:team"))
+	assert.NotEqual(t, nil, ValidateGroupBy("tag:"))
+	assert.NotEqual(t, nil, ValidateGroupBy("region"))
+}
+
+func TestBuildGroupsWithoutResourceType(t *testing.T) {
+	// JSON from versions before 0.4 doesn't have the resource type
+	r := Root{
+		Projects: []Project{
+			{
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{Name: "aws_instance.web[0]", MonthlyCost: decimalPtr(decimal.NewFromInt(10))},
+						{Name: "module.app[\"a\"].aws_instance.app", MonthlyCost: decimalPtr(decimal.NewFromInt(5))},
+						{Name: "google_compute_instance.app", MonthlyCost: decimalPtr(decimal.NewFromInt(20))},
+						{Name: "Topic"},
+					},
+				},
+			},
+		},
+	}
+
+	groupNames := func(groups []Group) []string {
+		names := make([]string, 0, len(groups))
+		for _, g := range groups {
+			names = append(names, fmt.Sprintf("%s %d", g.Name, g.ResourceCount))
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"google_compute_instance 1", "aws_instance 2", " 1"}, groupNames(BuildGroups(r, "type")))
+	assert.Equal(t, []string{"google 1", "aws 2", " 1"}, groupNames(BuildGroups(r, "provider")))
+}
+
+func TestCompare(t *testing.T) {
+	resource := func(name string, cost int64) Resource {
+		return Resource{
+			Name:        name,
+			MonthlyCost: decimalPtr(decimal.NewFromInt(cost)),
+			CostComponents: []CostComponent{
+				{Name: "Instance usage", MonthlyQuantity: decimalPtr(decimal.NewFromInt(730)), MonthlyCost: decimalPtr(decimal.NewFromInt(cost))},
+			},
+		}
+	}
+
+	from := Root{
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{
+				Resources:        []Resource{resource("aws_instance.a", 10), resource("aws_instance.b", 20), resource("aws_instance.c", 30)},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(60)),
+			}},
+			{Name: "old", Breakdown: &Breakdown{
+				Resources:        []Resource{resource("aws_instance.old", 5)},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(5)),
+			}},
+		},
+	}
+
+	to := Root{
+		Currency: "USD",
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{
+				Resources:        []Resource{resource("aws_instance.a", 10), resource("aws_instance.b", 25), resource("aws_instance.d", 40)},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(75)),
+			}},
+		},
+	}
+
+	out, err := Compare(from, to)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(out.Projects))
+
+	diffCosts := func(p Project) map[string]string {
+		m := make(map[string]string)
+		for _, r := range p.Diff.Resources {
+			m[r.Name] = r.MonthlyCost.String()
+		}
+		return m
+	}
+
+	assert.Equal(t, "web", out.Projects[0].Name)
+	assert.Equal(t, map[string]string{"aws_instance.b": "5", "aws_instance.c": "-30", "aws_instance.d": "40"}, diffCosts(out.Projects[0]))
+	assert.Equal(t, "15", out.Projects[0].Diff.TotalMonthlyCost.String())
+
+	assert.Equal(t, "old", out.Projects[1].Name)
+	assert.Equal(t, map[string]string{"aws_instance.old": "-5"}, diffCosts(out.Projects[1]))
+	assert.Equal(t, "-5", out.Projects[1].Diff.TotalMonthlyCost.String())
+
+	_, err = Compare(Root{Currency: "EUR"}, to)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCompareKeepsNilCosts(t *testing.T) {
+	lambda := func(cost int64) Resource {
+		return Resource{
+			Name:        "aws_lambda_function.api",
+			MonthlyCost: decimalPtr(decimal.NewFromInt(cost)),
+			CostComponents: []CostComponent{
+				{Name: "Requests"},
+				{Name: "Duration", MonthlyCost: decimalPtr(decimal.NewFromInt(cost))},
+			},
+		}
+	}
+
+	from := Root{Projects: []Project{{Name: "web", Breakdown: &Breakdown{Resources: []Resource{lambda(10)}}}}}
+	to := Root{Projects: []Project{{Name: "web", Breakdown: &Breakdown{Resources: []Resource{lambda(20)}}}}}
+
+	out, err := Compare(from, to)
+	assert.Equal(t, nil, err)
+
+	diff := out.Projects[0].Diff.Resources[0]
+	assert.Equal(t, (*decimal.Decimal)(nil), diff.HourlyCost)
+	assert.Equal(t, "10", diff.MonthlyCost.String())
+	assert.Equal(t, 1, len(diff.CostComponents))
+	assert.Equal(t, "Duration", diff.CostComponents[0].Name)
+	assert.Equal(t, (*decimal.Decimal)(nil), diff.CostComponents[0].HourlyCost)
+}
+
+func TestToJUnit(t *testing.T) {
+	r := Root{
+		Currency:         "USD",
+		TotalMonthlyCost: decimalPtr(decimal.NewFromInt(30)),
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{
+				Resources:        []Resource{{Name: "aws_instance.web", MonthlyCost: decimalPtr(decimal.NewFromInt(10))}},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(10)),
+			}},
+			{Name: "db", Breakdown: &Breakdown{
+				Resources:        []Resource{{Name: "aws_db_instance.db", MonthlyCost: decimalPtr(decimal.NewFromInt(20))}},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(20)),
+			}},
+		},
+	}
+
+	b, err := ToJUnit(r, Options{
+		Checks: []Check{
+			{Type: BudgetCheck, Name: "total"},
+			{Type: BudgetCheck, Name: "db", Failures: []string{"Budget db exceeded"}, Projects: []string{"db"}},
+			{Type: PolicyCheck, Name: "Policies"},
+		},
+	})
+	assert.Equal(t, nil, err)
+
+	var suites junitTestSuites
+	assert.Equal(t, nil, xml.Unmarshal(b, &suites))
+
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 3, len(suites.Suites))
+	assert.Equal(t, "Projects", suites.Suites[0].Name)
+	assert.Equal(t, "Budgets", suites.Suites[1].Name)
+	assert.Equal(t, "Policies", suites.Suites[2].Name)
+
+	failure := suites.Suites[1].TestCases[1].Failure
+	assert.NotEqual(t, nil, failure)
+	assert.Equal(t, "Budget db exceeded", failure.Message)
+	assert.Equal(t, true, strings.Contains(failure.Contents, "aws_db_instance.db"))
+	assert.Equal(t, false, strings.Contains(failure.Contents, "aws_instance.web"))
+}
+
+func TestToSARIF(t *testing.T) {
+	unsupported := map[string]int{"aws_unsupported": 2}
+
+	r := Root{
+		Currency: "USD",
+		Projects: []Project{
+			{
+				Name:     "web",
+				Metadata: &schema.ProjectMetadata{Path: "infra/web"},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:           "aws_instance.web",
+							SourceLocation: &schema.SourceLocation{Filename: "main.tf", StartLine: 3},
+							CostComponents: []CostComponent{
+								{Name: "Instance usage", PriceMatchStatus: string(schema.PriceMatchNoPrices)},
+								{Name: "Storage"},
+							},
+						},
+					},
+				},
+				Diff: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:           "aws_instance.web",
+							SourceLocation: &schema.SourceLocation{Filename: "main.tf", StartLine: 3},
+							MonthlyCost:    decimalPtr(decimal.NewFromInt(150)),
+						},
+						{Name: "aws_instance.small", MonthlyCost: decimalPtr(decimal.NewFromInt(5))},
+					},
+				},
+				Summary: &Summary{UnsupportedResourceCounts: &unsupported},
+			},
+		},
+	}
+
+	b, err := ToSARIF(r, Options{
+		Checks: []Check{
+			{Type: BudgetCheck, Name: "total", Failures: []string{"Budget total exceeded"}},
+			{Type: PolicyCheck, Name: "Policies"},
+		},
+	})
+	assert.Equal(t, nil, err)
+
+	var log sarifLog
+	assert.Equal(t, nil, json.Unmarshal(b, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+
+	results := log.Runs[0].Results
+	assert.Equal(t, 4, len(results))
+
+	assert.Equal(t, sarifRuleUnsupportedResource, results[0].RuleID)
+	assert.Equal(t, "infra/web", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+
+	assert.Equal(t, sarifRulePriceMatch, results[1].RuleID)
+	assert.Equal(t, "infra/web/main.tf", results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 3, results[1].Locations[0].PhysicalLocation.Region.StartLine)
+
+	assert.Equal(t, sarifRuleLargeCostIncrease, results[2].RuleID)
+	assert.Equal(t, "aws_instance.web monthly cost increases by $150.00", results[2].Message.Text)
+
+	assert.Equal(t, sarifRuleBudgetExceeded, results[3].RuleID)
+	assert.Equal(t, "Budget total exceeded", results[3].Message.Text)
+}
+
+func TestToHTML(t *testing.T) {
+	r := Root{
+		Currency:         "USD",
+		TotalMonthlyCost: decimalPtr(decimal.NewFromInt(30)),
+		Projects: []Project{
+			{
+				Name: "web",
+				PastBreakdown: &Breakdown{
+					Resources:        []Resource{},
+					TotalMonthlyCost: decimalPtr(decimal.Zero),
+				},
+				Breakdown: &Breakdown{
+					Resources: []Resource{
+						{
+							Name:         "aws_instance.web",
+							ResourceType: "aws_instance",
+							Tags:         map[string]string{"team": "frontend"},
+							MonthlyCost:  decimalPtr(decimal.NewFromInt(10)),
+						},
+					},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(10)),
+				},
+				Diff: &Breakdown{
+					Resources:        []Resource{{Name: "aws_instance.web", MonthlyCost: decimalPtr(decimal.NewFromInt(10))}},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(10)),
+				},
+			},
+			{
+				Name: "db",
+				Breakdown: &Breakdown{
+					Resources:        []Resource{{Name: "aws_db_instance.db", ResourceType: "aws_db_instance", MonthlyCost: decimalPtr(decimal.NewFromInt(20))}},
+					TotalMonthlyCost: decimalPtr(decimal.NewFromInt(20)),
+				},
+			},
+		},
+	}
+
+	b, err := ToHTML(r, Options{Fields: []string{"monthlyQuantity", "unit", "monthlyCost"}})
+	assert.Equal(t, nil, err)
+
+	html := string(b)
+	assert.Equal(t, true, strings.Contains(html, "Monthly cost by project"))
+	assert.Equal(t, true, strings.Contains(html, "Monthly cost by tag team"))
+	assert.Equal(t, true, strings.Contains(html, `<tr class="resource added">`))
+	assert.Equal(t, true, strings.Contains(html, `data-search="aws_instance.web aws_instance team=frontend"`))
+
+	// Everything must be embedded so the report works offline
+	assert.Equal(t, false, strings.Contains(html, "<script src"))
+	assert.Equal(t, false, strings.Contains(html, "stylesheet"))
+}
+
+func TestHTMLCharts(t *testing.T) {
+	resource := func(name string, tags map[string]string) Resource {
+		return Resource{Name: name, Tags: tags, MonthlyCost: decimalPtr(decimal.NewFromInt(10))}
+	}
+
+	r := Root{
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{Resources: []Resource{
+				resource("aws_instance.a", map[string]string{"team": "a", "env": "prod", "owner": "x", "cost-center": "1"}),
+				resource("aws_instance.b", map[string]string{"team": "b", "env": "prod", "owner": "y"}),
+				resource("aws_instance.c", map[string]string{"team": "c", "env": "dev", "name": "c"}),
+			}}},
+		},
+	}
+
+	titles := func(charts []htmlChart) []string {
+		t := make([]string, 0, len(charts))
+		for _, c := range charts {
+			t = append(t, c.Title)
+		}
+		return t
+	}
+
+	assert.Equal(t, []string{"Monthly cost by tag env", "Monthly cost by tag team", "Monthly cost by tag owner"}, titles(htmlCharts(r, "")))
+	assert.Equal(t, []string{"Monthly cost by tag cost-center"}, titles(htmlCharts(r, "tag:cost-center")))
+}
+
+func TestHTMLChartBars(t *testing.T) {
+	groups := make([]Group, 0, 12)
+	for i := 12; i > 0; i-- {
+		groups = append(groups, Group{Name: fmt.Sprintf("g%d", i), TotalMonthlyCost: decimalPtr(decimal.NewFromInt(int64(i)))})
+	}
+
+	bars := htmlChartBars(groups)
+	assert.Equal(t, maxHTMLChartBars, len(bars))
+	assert.Equal(t, 100.0, bars[0].Percent)
+	assert.Equal(t, "3 others", bars[9].Label)
+	assert.Equal(t, "6", bars[9].MonthlyCost.String())
+}
+
+func TestCostRanges(t *testing.T) {
+	cost := func(c int64) *decimal.Decimal {
+		return decimalPtr(decimal.NewFromInt(c))
+	}
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.HasDiff = false
+	project.Resources = []*schema.Resource{
+		{
+			Name:        "aws_lambda_function.api",
+			MonthlyCost: cost(20),
+			Range: &schema.ResourceRange{
+				Low:  &schema.Resource{MonthlyCost: cost(10)},
+				High: &schema.Resource{MonthlyCost: cost(50)},
+			},
+		},
+		{
+			Name:        "aws_instance.web",
+			MonthlyCost: cost(100),
+		},
+	}
+
+	out := ToOutputFormat([]*schema.Project{project}, "USD")
+
+	breakdown := out.Projects[0].Breakdown
+	assert.Equal(t, "10", breakdown.Resources[1].MonthlyCostRange.LowMonthlyCost.String())
+	assert.Equal(t, "50", breakdown.Resources[1].MonthlyCostRange.HighMonthlyCost.String())
+	assert.Equal(t, (*CostRange)(nil), breakdown.Resources[0].MonthlyCostRange)
+	assert.Equal(t, "110", breakdown.TotalMonthlyCostRange.LowMonthlyCost.String())
+	assert.Equal(t, "150", breakdown.TotalMonthlyCostRange.HighMonthlyCost.String())
+	assert.Equal(t, "110", out.TotalMonthlyCostRange.LowMonthlyCost.String())
+	assert.Equal(t, "150", out.TotalMonthlyCostRange.HighMonthlyCost.String())
+
+	table, err := ToTable(out, Options{Fields: []string{"monthlyQuantity", "unit", "monthlyCost"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(ui.StripColor(string(table)), "aws_lambda_function.api (usage range $10.00 - $50.00)"))
+	assert.Equal(t, true, strings.Contains(ui.StripColor(string(table)), "USAGE RANGE"))
+	assert.Equal(t, true, strings.Contains(ui.StripColor(string(table)), "$110.00 - $150.00"))
+
+	md, err := ToMarkdown(out, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(string(md), "Monthly cost will be **$120.00** (usage range $110.00 - $150.00)"))
+
+	b, err := json.Marshal(out)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(string(b), `"totalMonthlyCostRange":{"lowMonthlyCost":"110","highMonthlyCost":"150"}`))
+
+	// Outputs without ranges are unchanged
+	project.Resources = project.Resources[1:]
+	out = ToOutputFormat([]*schema.Project{project}, "USD")
+	assert.Equal(t, (*CostRange)(nil), out.TotalMonthlyCostRange)
+	assert.Equal(t, (*CostRange)(nil), out.Projects[0].Breakdown.TotalMonthlyCostRange)
+}
+
+func TestCompareCostRanges(t *testing.T) {
+	from := Root{
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{
+				Resources: []Resource{{
+					Name:           "aws_lambda_function.api",
+					MonthlyCost:    decimalPtr(decimal.NewFromInt(20)),
+					CostComponents: []CostComponent{{Name: "Requests", MonthlyCost: decimalPtr(decimal.NewFromInt(20))}},
+				}},
+				TotalMonthlyCost: decimalPtr(decimal.NewFromInt(20)),
+			}},
+		},
+	}
+
+	costRange := &CostRange{LowMonthlyCost: decimalPtr(decimal.NewFromInt(10)), HighMonthlyCost: decimalPtr(decimal.NewFromInt(50))}
+	to := Root{
+		Projects: []Project{
+			{Name: "web", Breakdown: &Breakdown{
+				Resources: []Resource{{
+					Name:             "aws_lambda_function.api",
+					MonthlyCost:      decimalPtr(decimal.NewFromInt(30)),
+					MonthlyCostRange: costRange,
+					CostComponents:   []CostComponent{{Name: "Requests", MonthlyCost: decimalPtr(decimal.NewFromInt(30))}},
+				}},
+				TotalMonthlyCost:      decimalPtr(decimal.NewFromInt(30)),
+				TotalMonthlyCostRange: costRange,
+			}},
+		},
+		TotalMonthlyCost:      decimalPtr(decimal.NewFromInt(30)),
+		TotalMonthlyCostRange: costRange,
+	}
+
+	out, err := Compare(from, to)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, costRange, out.TotalMonthlyCostRange)
+
+	diff := out.Projects[0].Diff
+	assert.Equal(t, "-10", diff.Resources[0].MonthlyCostRange.LowMonthlyCost.String())
+	assert.Equal(t, "30", diff.Resources[0].MonthlyCostRange.HighMonthlyCost.String())
+	assert.Equal(t, "-10", diff.TotalMonthlyCostRange.LowMonthlyCost.String())
+	assert.Equal(t, "30", diff.TotalMonthlyCostRange.HighMonthlyCost.String())
+
+	s, err := ToDiff(out, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(ui.StripColor(string(s)), "Range:   -$10.00 - +$30.00"))
+
+	md, err := ToMarkdown(out, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(string(md), "Depending on usage the change will be between **-$10.00** and **+$30.00**"))
+}
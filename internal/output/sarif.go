@@ -0,0 +1,303 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/infracost/infracost/internal/version"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifLargeIncrease is the monthly cost increase of a single resource that is
+// reported as a finding. It's a fixed threshold, in the output currency, so the rule
+// means the same thing in every run; budgets and policies should be used for limits
+// that differ between projects.
+var sarifLargeIncrease = decimal.NewFromInt(100)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+const (
+	sarifRuleUnsupportedResource = "unsupported-resource"
+	sarifRulePriceMatch          = "price-match-warning"
+	sarifRuleLargeCostIncrease   = "large-cost-increase"
+	sarifRuleBudgetExceeded      = "budget-exceeded"
+	sarifRulePolicyFailed        = "policy-failed"
+)
+
+var sarifRules = []sarifRule{
+	{
+		ID:                   sarifRuleUnsupportedResource,
+		Name:                 "UnsupportedResource",
+		ShortDescription:     sarifMessage{Text: "Resources that Infracost does not support yet, some of which might be free"},
+		DefaultConfiguration: sarifConfiguration{Level: "note"},
+	},
+	{
+		ID:                   sarifRulePriceMatch,
+		Name:                 "PriceMatchWarning",
+		ShortDescription:     sarifMessage{Text: "Cost components that could not be matched exactly to a price, so their cost might be zero or inaccurate"},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   sarifRuleLargeCostIncrease,
+		Name:                 "LargeCostIncrease",
+		ShortDescription:     sarifMessage{Text: fmt.Sprintf("Resources whose monthly cost increases by %s or more", sarifLargeIncrease.String())},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   sarifRuleBudgetExceeded,
+		Name:                 "BudgetExceeded",
+		ShortDescription:     sarifMessage{Text: "Budgets from the config file that have been exceeded"},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	{
+		ID:                   sarifRulePolicyFailed,
+		Name:                 "PolicyFailed",
+		ShortDescription:     sarifMessage{Text: "Policies that have failed"},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+}
+
+// ToSARIF outputs the cost findings as a SARIF log so they can be shown as code
+// scanning alerts. Findings for resources point to their resource block when the
+// source location is known, otherwise they point to the project path.
+func ToSARIF(out Root, opts Options) ([]byte, error) {
+	results := make([]sarifResult, 0)
+
+	for _, project := range out.Projects {
+		results = append(results, sarifUnsupportedResults(project)...)
+
+		if project.Breakdown != nil {
+			for _, r := range project.Breakdown.Resources {
+				results = append(results, sarifPriceMatchResults(project, r, r)...)
+			}
+		}
+
+		if project.Diff != nil {
+			for _, r := range project.Diff.Resources {
+				if r.MonthlyCost == nil || r.MonthlyCost.LessThan(sarifLargeIncrease) {
+					continue
+				}
+
+				results = append(results, sarifResult{
+					RuleID:    sarifRuleLargeCostIncrease,
+					Level:     "warning",
+					Message:   sarifMessage{Text: fmt.Sprintf("%s monthly cost increases by %s", r.Name, formatCost2DP(out.Currency, r.MonthlyCost))},
+					Locations: sarifResourceLocations(project, r),
+				})
+			}
+		}
+	}
+
+	for _, check := range opts.Checks {
+		ruleID := sarifRulePolicyFailed
+		if check.Type == BudgetCheck {
+			ruleID = sarifRuleBudgetExceeded
+		}
+
+		var locations []sarifLocation
+		for _, project := range out.Projects {
+			if len(check.Projects) == 0 || contains(check.Projects, project.Name) {
+				locations = append(locations, sarifProjectLocation(project)...)
+			}
+		}
+
+		for _, failure := range check.Failures {
+			results = append(results, sarifResult{
+				RuleID:    ruleID,
+				Level:     "error",
+				Message:   sarifMessage{Text: failure},
+				Locations: locations,
+			})
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "Infracost",
+						Version:        version.Version,
+						InformationURI: "https://www.infracost.io",
+						Rules:          sarifRules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	return json.MarshalIndent(log, "", "  ")
+}
+
+func sarifUnsupportedResults(project Project) []sarifResult {
+	if project.Summary == nil || project.Summary.UnsupportedResourceCounts == nil {
+		return nil
+	}
+
+	counts := *project.Summary.UnsupportedResourceCounts
+
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	results := make([]sarifResult, 0, len(types))
+	for _, t := range types {
+		results = append(results, sarifResult{
+			RuleID:    sarifRuleUnsupportedResource,
+			Level:     "note",
+			Message:   sarifMessage{Text: fmt.Sprintf("%d x %s is not supported yet", counts[t], t)},
+			Locations: sarifProjectLocation(project),
+		})
+	}
+
+	return results
+}
+
+// sarifPriceMatchResults returns a result for each cost component of the resource
+// and its sub-resources that wasn't matched exactly to a price. The results point to the
+// top-level resource since sub-resources don't have their own source location.
+func sarifPriceMatchResults(project Project, topLevel Resource, r Resource) []sarifResult {
+	var results []sarifResult
+
+	for _, c := range r.CostComponents {
+		if c.PriceMatchStatus == string(schema.PriceMatchOK) {
+			continue
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    sarifRulePriceMatch,
+			Level:     "warning",
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s: %s", r.Name, c.Name, c.PriceMatchWarning())},
+			Locations: sarifResourceLocations(project, topLevel),
+		})
+	}
+
+	for _, s := range r.SubResources {
+		s.Name = fmt.Sprintf("%s.%s", r.Name, s.Name)
+		results = append(results, sarifPriceMatchResults(project, topLevel, s)...)
+	}
+
+	return results
+}
+
+func sarifResourceLocations(project Project, r Resource) []sarifLocation {
+	if r.SourceLocation == nil {
+		return sarifProjectLocation(project)
+	}
+
+	projectPath := ""
+	if project.Metadata != nil {
+		projectPath = project.Metadata.Path
+	}
+
+	return []sarifLocation{
+		{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(filepath.Join(projectPath, r.SourceLocation.Filename))},
+				Region:           &sarifRegion{StartLine: r.SourceLocation.StartLine},
+			},
+		},
+	}
+}
+
+func sarifProjectLocation(project Project) []sarifLocation {
+	if project.Metadata == nil || project.Metadata.Path == "" {
+		return nil
+	}
+
+	return []sarifLocation{
+		{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(project.Metadata.Path)},
+			},
+		},
+	}
+}
+
+// sarifURI returns the path relative to the working directory if possible, since code
+// scanning tools expect paths relative to the repository root.
+func sarifURI(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+
+	return filepath.ToSlash(filepath.Clean(path))
+}
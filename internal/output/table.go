@@ -38,7 +38,7 @@ func ToTable(out Root, opts Options) ([]byte, error) {
 			hasNilCosts = true
 		}
 
-		tableOut := tableForBreakdown(*project.Breakdown, opts.Fields, includeProjectTotals)
+		tableOut := tableForBreakdown(out.Currency, *project.Breakdown, opts.Fields, includeProjectTotals)
 
 		// Get the last table length so we can align the overall total with it
 		if i == len(out.Projects)-1 {
@@ -58,19 +58,42 @@ func ToTable(out Root, opts Options) ([]byte, error) {
 		s += "\n"
 	}
 
-	totalOut := formatCost2DP(out.TotalMonthlyCost)
+	if opts.GroupBy != "" {
+		s += "----------------------------------\n"
+		s += fmt.Sprintf("%s\n\n", ui.BoldString("Monthly cost by "+groupByLabel(opts.GroupBy)))
+		s += tableForGroups(out.Currency, BuildGroups(out, opts.GroupBy))
+		s += "\n\n"
+	}
+
+	totalOut := formatCost2DP(out.Currency, out.TotalMonthlyCost)
 
 	s += fmt.Sprintf("%s%s",
 		ui.BoldString(" OVERALL TOTAL"),
 		fmt.Sprintf("%*s ", tableLen-15, totalOut), // pad based on the last line length
 	)
 
+	if out.TotalMonthlyCostRange != nil {
+		s += fmt.Sprintf("\n%s%s",
+			ui.BoldString(" USAGE RANGE"),
+			fmt.Sprintf("%*s ", tableLen-13, formatCostRange(out.Currency, out.TotalMonthlyCostRange)),
+		)
+	}
+
 	unsupportedMsg := out.unsupportedResourcesMessage(opts.ShowSkipped)
+	priceMatchMsg := out.priceMatchWarningsMessage(false)
 
-	if hasNilCosts || unsupportedMsg != "" {
+	if hasNilCosts || unsupportedMsg != "" || priceMatchMsg != "" {
 		s += "\n----------------------------------"
 	}
 
+	if priceMatchMsg != "" {
+		s += "\n" + ui.WarningString(priceMatchMsg)
+
+		if hasNilCosts || unsupportedMsg != "" {
+			s += "\n"
+		}
+	}
+
 	if hasNilCosts {
 		s += fmt.Sprintf("\nTo estimate usage-based resources use --usage-file, see %s",
 			ui.LinkString("https://infracost.io/usage-file"),
@@ -88,7 +111,7 @@ func ToTable(out Root, opts Options) ([]byte, error) {
 	return []byte(s), nil
 }
 
-func tableForBreakdown(breakdown Breakdown, fields []string, includeTotal bool) string {
+func tableForBreakdown(currency string, breakdown Breakdown, fields []string, includeTotal bool) string {
 	t := table.NewWriter()
 	t.Style().Options.DrawBorder = false
 	t.Style().Options.SeparateColumns = false
@@ -163,10 +186,14 @@ func tableForBreakdown(breakdown Breakdown, fields []string, includeTotal bool)
 	t.AppendHeader(headers)
 
 	for _, r := range breakdown.Resources {
-		t.AppendRow(table.Row{ui.BoldString(r.Name)})
+		name := ui.BoldString(r.Name)
+		if r.MonthlyCostRange != nil {
+			name += ui.FaintStringf(" (usage range %s)", formatCostRange(currency, r.MonthlyCostRange))
+		}
+		t.AppendRow(table.Row{name})
 
-		buildCostComponentRows(t, r.CostComponents, "", len(r.SubResources) > 0, fields)
-		buildSubResourceRows(t, r.SubResources, "", fields)
+		buildCostComponentRows(t, currency, r.CostComponents, "", len(r.SubResources) > 0, fields)
+		buildSubResourceRows(t, currency, r.SubResources, "", fields)
 
 		t.AppendRow(table.Row{""})
 	}
@@ -178,14 +205,54 @@ func tableForBreakdown(breakdown Breakdown, fields []string, includeTotal bool)
 		for q := 0; q < numOfFields; q++ {
 			totalCostRow = append(totalCostRow, "")
 		}
-		totalCostRow = append(totalCostRow, formatCost2DP(breakdown.TotalMonthlyCost))
+		totalCostRow = append(totalCostRow, formatCost2DP(currency, breakdown.TotalMonthlyCost))
 		t.AppendRow(totalCostRow)
+
+		if breakdown.TotalMonthlyCostRange != nil {
+			var rangeRow table.Row
+			rangeRow = append(rangeRow, ui.FaintString("Usage range"))
+			for q := 0; q < numOfFields; q++ {
+				rangeRow = append(rangeRow, "")
+			}
+			rangeRow = append(rangeRow, ui.FaintString(formatCostRange(currency, breakdown.TotalMonthlyCostRange)))
+			t.AppendRow(rangeRow)
+		}
+	}
+
+	return t.Render()
+}
+
+func tableForGroups(currency string, groups []Group) string {
+	t := table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.Style().Options.SeparateRows = false
+	t.Style().Options.SeparateHeader = false
+	t.Style().Format.Header = text.FormatDefault
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 2, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 3, Align: text.AlignRight, AlignHeader: text.AlignRight},
+	})
+	t.AppendHeader(table.Row{
+		ui.UnderlineString("Name"),
+		ui.UnderlineString("Resources"),
+		ui.UnderlineString("Monthly Cost"),
+	})
+
+	for _, g := range groups {
+		t.AppendRow(table.Row{
+			groupLabel(g.Name),
+			g.ResourceCount,
+			formatCost2DP(currency, g.TotalMonthlyCost),
+		})
 	}
 
 	return t.Render()
 }
 
-func buildSubResourceRows(t table.Writer, subresources []Resource, prefix string, fields []string) {
+func buildSubResourceRows(t table.Writer, currency string, subresources []Resource, prefix string, fields []string) {
 	for i, r := range subresources {
 		labelPrefix := prefix + "├─"
 		nextPrefix := prefix + "│  "
@@ -196,12 +263,12 @@ func buildSubResourceRows(t table.Writer, subresources []Resource, prefix string
 
 		t.AppendRow(table.Row{fmt.Sprintf("%s %s", ui.FaintString(labelPrefix), r.Name)})
 
-		buildCostComponentRows(t, r.CostComponents, nextPrefix, len(r.SubResources) > 0, fields)
-		buildSubResourceRows(t, r.SubResources, nextPrefix, fields)
+		buildCostComponentRows(t, currency, r.CostComponents, nextPrefix, len(r.SubResources) > 0, fields)
+		buildSubResourceRows(t, currency, r.SubResources, nextPrefix, fields)
 	}
 }
 
-func buildCostComponentRows(t table.Writer, costComponents []CostComponent, prefix string, hasSubResources bool, fields []string) {
+func buildCostComponentRows(t table.Writer, currency string, costComponents []CostComponent, prefix string, hasSubResources bool, fields []string) {
 	for i, c := range costComponents {
 		labelPrefix := prefix + "├─"
 		if !hasSubResources && i == len(costComponents)-1 {
@@ -209,10 +276,13 @@ func buildCostComponentRows(t table.Writer, costComponents []CostComponent, pref
 		}
 
 		label := fmt.Sprintf("%s %s", ui.FaintString(labelPrefix), c.Name)
+		if c.PriceMatchWarning() != "" {
+			label += " " + ui.WarningString(priceMatchWarningMarker)
+		}
 
 		if c.MonthlyCost == nil {
 			price := fmt.Sprintf("Monthly cost depends on usage: %s per %s",
-				formatPrice(c.Price),
+				formatPrice(currency, c.Price),
 				c.Unit,
 			)
 
@@ -227,7 +297,7 @@ func buildCostComponentRows(t table.Writer, costComponents []CostComponent, pref
 			tableRow = append(tableRow, label)
 
 			if contains(fields, "price") {
-				tableRow = append(tableRow, formatPrice(c.Price))
+				tableRow = append(tableRow, formatPrice(currency, c.Price))
 			}
 			if contains(fields, "monthlyQuantity") {
 				tableRow = append(tableRow, formatQuantity(c.MonthlyQuantity))
@@ -236,10 +306,10 @@ func buildCostComponentRows(t table.Writer, costComponents []CostComponent, pref
 				tableRow = append(tableRow, c.Unit)
 			}
 			if contains(fields, "hourlyCost") {
-				tableRow = append(tableRow, formatCost2DP(c.HourlyCost))
+				tableRow = append(tableRow, formatCost2DP(currency, c.HourlyCost))
 			}
 			if contains(fields, "monthlyCost") {
-				tableRow = append(tableRow, formatCost2DP(c.MonthlyCost))
+				tableRow = append(tableRow, formatCost2DP(currency, c.MonthlyCost))
 			}
 
 			t.AppendRow(tableRow)
@@ -83,6 +83,15 @@ tr.total {
   font-weight: bold;
 }
 
+tr.usage-range, .usage-range {
+  color: #6b7280;
+  font-weight: normal;
+}
+
+tr.resource.top-level .usage-range {
+  color: #d8dce2;
+}
+
 table.overall-total tr.total {
   background-color: #ffdfb9;
   font-weight: bold;
@@ -101,6 +110,11 @@ table.overall-total tr.total td {
   color: #6b7280;
 }
 
+.price-match-warning {
+  color: #d97706;
+  cursor: help;
+}
+
 @media screen and (max-width: 1024px) {
   table.breakdown, table.overall-total {
     min-width: auto;
@@ -114,6 +128,191 @@ table.overall-total {
   margin-top: 1rem;
 }
 
+.toolbar {
+  display: flex;
+  flex-wrap: wrap;
+  gap: 0.5rem;
+  margin-bottom: 1rem;
+}
+
+.toolbar input, .toolbar select, .tabs button {
+  font-size: 0.875rem;
+  padding: 0.25rem 0.5rem;
+}
+
+.toolbar input {
+  min-width: 20rem;
+}
+
+.tabs {
+  margin-bottom: 1rem;
+}
+
+.tabs button.active {
+  font-weight: bold;
+}
+
+.hidden {
+  display: none;
+}
+
+th.sortable, td.sortable {
+  cursor: pointer;
+  user-select: none;
+}
+
+th.sortable[data-direction="asc"]::after, td.sortable[data-direction="asc"]::after {
+  content: " \25B2";
+}
+
+th.sortable[data-direction="desc"]::after, td.sortable[data-direction="desc"]::after {
+  content: " \25BC";
+}
+
+button.toggle {
+  background: none;
+  border: none;
+  color: inherit;
+  cursor: pointer;
+  padding: 0 0.25rem 0 0;
+}
+
+tbody.collapsed tr:not(.top-level) {
+  display: none;
+}
+
+tbody.collapsed button.toggle {
+  transform: rotate(-90deg);
+}
+
+.charts {
+  display: flex;
+  flex-wrap: wrap;
+  gap: 2rem;
+  margin-bottom: 1.5rem;
+}
+
+.chart {
+  min-width: 24rem;
+}
+
+.chart .bar-row {
+  display: flex;
+  align-items: center;
+  margin-bottom: 0.25rem;
+  font-size: 0.875rem;
+}
+
+.chart .bar-label {
+  width: 10rem;
+  overflow: hidden;
+  text-overflow: ellipsis;
+  white-space: nowrap;
+}
+
+.chart .bar-track {
+  width: 10rem;
+  margin: 0 0.5rem;
+}
+
+.chart .bar {
+  background-color: #7b61ff;
+  height: 0.75rem;
+  min-width: 1px;
+}
+
+tr.increased td.change, tr.added td.change {
+  color: #dc2626;
+}
+
+tr.decreased td.change, tr.removed td.change {
+  color: #16a34a;
+}
+
+tr.removed td.name {
+  text-decoration: line-through;
+}
+
+{{end}}
+
+{{define "script"}}
+(function() {
+  var search = document.getElementById("search");
+  var typeFilter = document.getElementById("type-filter");
+  var groups = Array.prototype.slice.call(document.querySelectorAll("tbody.resource-group"));
+
+  var types = {};
+  groups.forEach(function(g) {
+    if (g.dataset.type) {
+      types[g.dataset.type] = true;
+    }
+  });
+  Object.keys(types).sort().forEach(function(t) {
+    var option = document.createElement("option");
+    option.value = t;
+    option.textContent = t;
+    typeFilter.appendChild(option);
+  });
+
+  function filter() {
+    var q = search.value.trim().toLowerCase();
+    var t = typeFilter.value;
+    groups.forEach(function(g) {
+      var match = g.dataset.search.indexOf(q) !== -1 && (t === "" || g.dataset.type === t);
+      g.classList.toggle("hidden", !match);
+    });
+  }
+  search.addEventListener("input", filter);
+  typeFilter.addEventListener("change", filter);
+
+  document.querySelectorAll("button.toggle").forEach(function(b) {
+    b.addEventListener("click", function() {
+      var group = b.closest("tbody");
+      var collapsed = group.classList.toggle("collapsed");
+      b.setAttribute("aria-expanded", collapsed ? "false" : "true");
+    });
+  });
+
+  document.getElementById("collapse-all").addEventListener("click", function() {
+    groups.forEach(function(g) { g.classList.add("collapsed"); });
+  });
+  document.getElementById("expand-all").addEventListener("click", function() {
+    groups.forEach(function(g) { g.classList.remove("collapsed"); });
+  });
+
+  document.querySelectorAll(".sortable").forEach(function(header) {
+    header.addEventListener("click", function() {
+      var table = header.closest("table");
+      var key = header.dataset.sort;
+      var direction = header.dataset.direction === "asc" ? "desc" : "asc";
+      table.querySelectorAll(".sortable").forEach(function(h) { delete h.dataset.direction; });
+      header.dataset.direction = direction;
+
+      var rows = Array.prototype.slice.call(table.querySelectorAll("tbody.resource-group"));
+      rows.sort(function(a, b) {
+        var result;
+        if (key === "name") {
+          result = a.dataset.name.localeCompare(b.dataset.name);
+        } else {
+          result = parseFloat(a.dataset[key]) - parseFloat(b.dataset[key]);
+        }
+        return direction === "asc" ? result : -result;
+      });
+
+      var footer = table.querySelector("tfoot");
+      rows.forEach(function(r) { table.insertBefore(r, footer); });
+    });
+  });
+
+  document.querySelectorAll(".tabs button").forEach(function(tab) {
+    tab.addEventListener("click", function() {
+      document.querySelectorAll(".tabs button").forEach(function(t) {
+        t.classList.toggle("active", t === tab);
+        document.getElementById(t.dataset.view).classList.toggle("hidden", t !== tab);
+      });
+    });
+  });
+})();
 {{end}}
 
 {{define "faviconBase64"}}
@@ -144,7 +343,9 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
     <td class="name">
       {{if gt .Indent 1}}{{repeat (int (add .Indent -1)) "&nbsp;&nbsp;&nbsp;&nbsp;" | safeHTML}}{{end}}
       {{if gt .Indent 0}}<span class="arrow">&#8627;</span>{{end}}
+      {{if and (eq .Indent 0) (or .Resource.CostComponents .Resource.SubResources)}}<button class="toggle" aria-expanded="true" title="Collapse/expand">&#9662;</button>{{end}}
       {{.Resource.Name}}
+      {{with .Resource.MonthlyCostRange}}<span class="usage-range">(usage range {{. | formatCostRange}})</span>{{end}}
     </td>
     {{template "emptyTableRows" dict "Fields" $fields}}
   </tr>
@@ -177,6 +378,7 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
       {{if gt .Indent 1}}{{repeat (int (add .Indent -1)) "&nbsp;&nbsp;&nbsp;&nbsp;" | safeHTML}}{{end}}
       {{if gt .Indent 0}}<span class="arrow">&#8627;</span>{{end}}
       {{.CostComponent.Name}}
+      {{with .CostComponent.PriceMatchWarning}}<span class="price-match-warning" title="{{.}}">*</span>{{end}}
     </td>
     {{if .CostComponent.MonthlyCost}}
       {{if contains .Fields "monthlyQuantity"}}
@@ -201,7 +403,7 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
 {{end}}
 
 {{define "tableHeaders"}}
-  <th class="name">Name</th>
+  <th class="name sortable" data-sort="name">Name</th>
   {{if contains .Fields "monthlyQuantity"}}
     <td class="monthly-quantity">Monthly Qty</td>
   {{end}}
@@ -215,7 +417,7 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
     <td class="hourly-cost">Hourly Cost</td>
   {{end}}
   {{if contains .Fields "monthlyCost"}}
-    <td class="monthly-cost">Monthly Cost</td>
+    <td class="monthly-cost sortable" data-sort="monthlyCost">Monthly Cost</td>
   {{end}}
 {{end}}
 
@@ -226,15 +428,64 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
     <thead>      
       {{template "tableHeaders" dict "Fields" $fields}}
     </thead>
-    <tbody>
-      {{range .Resources}}
+    {{range .Resources}}
+      <tbody class="resource-group" data-name="{{.Name}}" data-type="{{.ResourceType}}" data-monthly-cost="{{.MonthlyCost | decimalString}}" data-search="{{. | resourceSearchText}}">
         {{template "resourceRows" dict "Resource" . "Fields" $fields "Indent" 0}}
-      {{end}}
+      </tbody>
+    {{end}}
+    <tfoot>
       <tr class="total">
         <td class="name" colspan="{{len .Options.Fields}}">Project total</td>
         <td class="monthly-cost">{{.Project.Breakdown.TotalMonthlyCost | formatCost2DP}}</td>
       </tr>
+      {{with .Project.Breakdown.TotalMonthlyCostRange}}
+        <tr class="usage-range">
+          <td class="name" colspan="{{len $fields}}">Usage range</td>
+          <td class="monthly-cost">{{. | formatCostRange}}</td>
+        </tr>
+      {{end}}
+    </tfoot>
+  </table>
+{{end}}
+
+{{define "chart"}}
+  <div class="chart">
+    <p class="project-name">{{.Title}}</p>
+    {{range .Bars}}
+      <div class="bar-row">
+        <span class="bar-label" title="{{.Label}}">{{.Label}}</span>
+        <div class="bar-track"><div class="bar" style="width: {{printf "%.1f" .Percent}}%"></div></div>
+        <span class="bar-value">{{.MonthlyCost | formatCost2DP}}</span>
+      </div>
+    {{end}}
+  </div>
+{{end}}
+
+{{define "diffBlock"}}
+  <p class="project-name">Project: {{.Project | projectLabel}}</p>
+  <table class="breakdown diff">
+    <thead>
+      <th class="name">Name</th>
+      <td class="monthly-cost">Previous</td>
+      <td class="monthly-cost">New</td>
+      <td class="monthly-cost">Change</td>
+    </thead>
+    <tbody>
+      {{range .Rows}}
+        <tr class="resource {{.Kind}}">
+          <td class="name">{{.Name}}</td>
+          <td class="monthly-cost">{{.PastMonthlyCost | formatCost2DP}}</td>
+          <td class="monthly-cost">{{.MonthlyCost | formatCost2DP}}</td>
+          <td class="monthly-cost change">{{.Change | formatCostChange}}</td>
+        </tr>
+      {{end}}
     </tbody>
+    <tfoot>
+      <tr class="total">
+        <td class="name" colspan="3">Monthly cost change</td>
+        <td class="monthly-cost">{{.Project.Diff.TotalMonthlyCost | formatCostChange}}</td>
+      </tr>
+    </tfoot>
   </table>
 {{end}}
 
@@ -264,22 +515,89 @@ iVBORw0KGgoAAAANSUhEUgAAAMAAAADACAMAAABlApw1AAAABGdBTUEAALGPC/xhBQAAAAFzUkdCAK7O
 
     {{$options := .Options}}
 
-    {{range .Root.Projects}}
-      {{$resources := .Breakdown.Resources}}
-      {{template "projectBlock" dict "Project" . "Options" $options "Resources" $resources "Indent" 0}}
+    {{if .Charts}}
+      <div class="charts">
+        {{range .Charts}}
+          {{template "chart" .}}
+        {{end}}
+      </div>
+    {{end}}
+
+    {{if .Diffs}}
+      <div class="tabs">
+        <button class="active" data-view="breakdown-view">Breakdown</button>
+        <button data-view="diff-view">Diff</button>
+      </div>
+    {{end}}
+
+    <div id="breakdown-view">
+      <div class="toolbar">
+        <input id="search" type="search" placeholder="Search by name, type or tag" aria-label="Search resources">
+        <select id="type-filter" aria-label="Filter by resource type">
+          <option value="">All resource types</option>
+        </select>
+        <button id="collapse-all">Collapse all</button>
+        <button id="expand-all">Expand all</button>
+      </div>
+
+      {{range .Root.Projects}}
+        {{$resources := .Breakdown.Resources}}
+        {{template "projectBlock" dict "Project" . "Options" $options "Resources" $resources "Indent" 0}}
+      {{end}}
+    </div>
+
+    {{if .Diffs}}
+      <div id="diff-view" class="hidden">
+        {{range .Diffs}}
+          {{template "diffBlock" .}}
+        {{end}}
+      </div>
     {{end}}
-    
+
+    {{if .Groups}}
+      <p class="project-name">Monthly cost by {{.Options.GroupBy | groupByLabel}}</p>
+      <table class="breakdown groups">
+        <thead>
+          <th class="name">Name</th>
+          <td class="monthly-quantity">Resources</td>
+          <td class="monthly-cost">Monthly cost</td>
+        </thead>
+        <tbody>
+          {{range .Groups}}
+            <tr class="resource top-level">
+              <td class="name">{{.Name | groupLabel}}</td>
+              <td class="monthly-quantity">{{.ResourceCount}}</td>
+              <td class="monthly-cost">{{.TotalMonthlyCost | formatCost2DP}}</td>
+            </tr>
+          {{end}}
+        </tbody>
+      </table>
+    {{end}}
+
     <table class="overall-total">
       <tbody>
         <tr class="total">
           <td class="name" colspan="{{len .Options.Fields}}">Overall total</td>
           <td class="monthly-cost">{{.Root.TotalMonthlyCost | formatCost2DP}}</td>
         </tr>
+        {{with .Root.TotalMonthlyCostRange}}
+          <tr class="usage-range">
+            <td class="name" colspan="{{len $.Options.Fields}}">Usage range</td>
+            <td class="monthly-cost">{{. | formatCostRange}}</td>
+          </tr>
+        {{end}}
       </tbody>
     </table>
 
     <div class="warnings">
+      {{if .PriceMatchWarningsMessage}}
+        <p class="price-match-warning">{{.PriceMatchWarningsMessage | replaceNewLines}}</p>
+      {{end}}
       <p>{{.UnsupportedResourcesMessage | replaceNewLines}}</p>
     </div>
+
+    <script>
+      {{template "script"}}
+    </script>
   </body>
 </html>`
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/pkg/errors"
+)
+
+// ExitCode is used when a policy fails so CI pipelines can tell it apart from errors
+// and exceeded budgets.
+const ExitCode = 3
+
+// Policies are written in the infracost package and can define deny and warn rules
+// that return messages, e.g.
+//
+//	package infracost
+//
+//	deny[msg] {
+//	    r := input.projects[_].breakdown.resources[_]
+//	    r.resourceType == "aws_nat_gateway"
+//	    msg := sprintf("%s: NAT gateways are not allowed", [r.name])
+//	}
+var (
+	denyQuery = "data.infracost.deny"
+	warnQuery = "data.infracost.warn"
+)
+
+type Result struct {
+	Failures []string
+	Warnings []string
+}
+
+func (r *Result) Failed() bool {
+	return len(r.Failures) > 0
+}
+
+// Evaluate evaluates the Rego policies in the paths, which can be files or
+// directories, against the Infracost JSON output.
+func Evaluate(ctx context.Context, paths []string, input []byte) (*Result, error) {
+	var data interface{}
+	err := json.Unmarshal(input, &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing policy input")
+	}
+
+	failures, err := evalQuery(ctx, denyQuery, paths, data)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := evalQuery(ctx, warnQuery, paths, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Failures: failures,
+		Warnings: warnings,
+	}, nil
+}
+
+func evalQuery(ctx context.Context, query string, paths []string, input interface{}) ([]string, error) {
+	r := rego.New(
+		rego.Query(query),
+		rego.Load(paths, onlyRegoFiles),
+	)
+
+	pq, err := r.PrepareForEval(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading policies")
+	}
+
+	rs, err := pq.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error evaluating policies")
+	}
+
+	msgs := make([]string, 0)
+
+	for _, result := range rs {
+		for _, expr := range result.Expressions {
+			values, ok := expr.Value.([]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, v := range values {
+				msgs = append(msgs, message(v))
+			}
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return msgs, nil
+}
+
+// message returns the message for a rule value, which can either be a string or an
+// object with a msg key.
+func message(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if msg, ok := t["msg"].(string); ok {
+			return msg
+		}
+	}
+
+	return fmt.Sprint(v)
+}
+
+// onlyRegoFiles skips any files in the policy directories that aren't Rego policies,
+// including Rego test files.
+func onlyRegoFiles(abspath string, info os.FileInfo, depth int) bool {
+	if info.IsDir() {
+		return false
+	}
+
+	return filepath.Ext(abspath) != ".rego" || strings.HasSuffix(abspath, "_test.rego")
+}
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+package infracost
+
+deny[msg] {
+	r := input.projects[_].breakdown.resources[_]
+	r.resourceType == "aws_ebs_volume"
+	r.costComponents[_].name == "Storage (general purpose SSD, gp2)"
+	msg := sprintf("%s: use gp3 volumes instead of gp2", [r.name])
+}
+
+warn[{"msg": msg}] {
+	r := input.projects[_].breakdown.resources[_]
+	to_number(r.monthlyCost) > 100
+	msg := sprintf("%s: costs more than $100/month", [r.name])
+}
+`
+
+const testInput = `{
+  "projects": [
+    {
+      "breakdown": {
+        "resources": [
+          {
+            "name": "aws_ebs_volume.data",
+            "resourceType": "aws_ebs_volume",
+            "monthlyCost": "10",
+            "costComponents": [{"name": "Storage (general purpose SSD, gp2)"}]
+          },
+          {
+            "name": "aws_instance.web",
+            "resourceType": "aws_instance",
+            "monthlyCost": "150.5",
+            "costComponents": [{"name": "Instance usage (Linux/UNIX, on-demand, m5.large)"}]
+          }
+        ]
+      }
+    }
+  ]
+}`
+
+func TestEvaluate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "policy.rego"), []byte(testPolicy), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# Policies"), 0600))
+
+	result, err := Evaluate(context.Background(), []string{dir}, []byte(testInput))
+	require.NoError(t, err)
+
+	assert.True(t, result.Failed())
+	assert.Equal(t, []string{"aws_ebs_volume.data: use gp3 volumes instead of gp2"}, result.Failures)
+	assert.Equal(t, []string{"aws_instance.web: costs more than $100/month"}, result.Warnings)
+}
+
+func TestEvaluateNoRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policy.rego")
+	require.NoError(t, ioutil.WriteFile(path, []byte("package infracost\n"), 0600))
+
+	result, err := Evaluate(context.Background(), []string{path}, []byte(testInput))
+	require.NoError(t, err)
+
+	assert.False(t, result.Failed())
+	assert.Empty(t, result.Warnings)
+}
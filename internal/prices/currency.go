@@ -0,0 +1,82 @@
+package prices
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v2"
+)
+
+// Currency describes which currency field to read from the price results
+// and the rate used to convert those prices into the output currency.
+type Currency struct {
+	Code  string
+	Field string
+	Rate  decimal.Decimal
+}
+
+// CurrencyRatesFile is the format of the currency rates file. Rates are the
+// value of 1 USD in each currency, e.g.
+//
+//	rates:
+//	  EUR: 0.92
+//	  GBP: 0.79
+type CurrencyRatesFile struct {
+	Rates map[string]float64 `yaml:"rates"`
+}
+
+// LoadCurrency returns the currency for the config. If a currency rates file is
+// set, prices are read in USD and converted using the rate for the currency,
+// otherwise they are read in the currency directly.
+func LoadCurrency(cfg *config.Config) (*Currency, error) {
+	code := cfg.Currency
+	if code == "" {
+		code = "USD"
+	}
+
+	c := &Currency{
+		Code:  code,
+		Field: cfg.PriceCurrency(),
+		Rate:  decimal.NewFromInt(1),
+	}
+
+	if cfg.CurrencyRatesFile == "" || code == "USD" {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(cfg.CurrencyRatesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading currency rates file")
+	}
+
+	var f CurrencyRatesFile
+	err = yaml.Unmarshal(data, &f)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing currency rates file")
+	}
+
+	for k, v := range f.Rates {
+		if strings.EqualFold(k, code) {
+			if v <= 0 {
+				return nil, fmt.Errorf("Invalid rate %v for %s in currency rates file", v, code)
+			}
+
+			c.Rate = decimal.NewFromFloat(v)
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No rate found for %s in currency rates file %s", code, cfg.CurrencyRatesFile)
+}
+
+func (c *Currency) convert(d decimal.Decimal) decimal.Decimal {
+	if c.Rate.Equal(decimal.NewFromInt(1)) {
+		return d
+	}
+
+	return d.Mul(c.Rate)
+}
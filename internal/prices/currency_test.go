@@ -0,0 +1,42 @@
+package prices
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCurrency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-currency")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ratesFile := filepath.Join(dir, "rates.yml")
+	require.NoError(t, ioutil.WriteFile(ratesFile, []byte("rates:\n  EUR: 0.5\n"), 0600))
+
+	cfg := config.DefaultConfig()
+	cfg.Currency = "EUR"
+
+	c, err := LoadCurrency(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", c.Field)
+	assert.True(t, c.convert(decimal.NewFromInt(10)).Equal(decimal.NewFromInt(10)))
+
+	cfg.CurrencyRatesFile = ratesFile
+
+	c, err = LoadCurrency(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", c.Field)
+	assert.True(t, c.convert(decimal.NewFromInt(10)).Equal(decimal.NewFromInt(5)))
+
+	cfg.Currency = "GBP"
+
+	_, err = LoadCurrency(cfg)
+	assert.Error(t, err)
+}
@@ -1,89 +1,133 @@
 package prices
 
 import (
-	"runtime"
+	"fmt"
+	"strings"
 
 	"github.com/infracost/infracost/internal/apiclient"
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
 
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
-func PopulatePrices(cfg *config.Config, project *schema.Project) error {
-	resources := project.AllResources()
+// PriceQuerier looks up the prices for a list of product and price filters,
+// returning a result for each query in the same order.
+type PriceQuerier interface {
+	QueryPrices(queries []apiclient.PriceQuery) ([]gjson.Result, error)
+}
+
+// PopulatePrices gets the prices for all the resources across all the projects.
+func PopulatePrices(cfg *config.Config, projects []*schema.Project) error {
+	resources := make([]*schema.Resource, 0)
+	rangeResources := make([]*schema.Resource, 0)
+	for _, project := range projects {
+		for _, r := range project.AllResources() {
+			resources = append(resources, r)
+			if r.Range != nil {
+				rangeResources = append(rangeResources, r.Range.Low, r.Range.High)
+			}
+		}
+	}
 
-	c := apiclient.NewPricingAPIClient(cfg)
+	currency, err := LoadCurrency(cfg)
+	if err != nil {
+		return err
+	}
+
+	c, err := newPriceQuerier(cfg)
+	if err != nil {
+		return err
+	}
 
-	err := GetPricesConcurrent(c, resources)
+	// The resources priced with their low and high usage estimates are priced in the
+	// same batch, but aren't checked for strict pricing since they have the same products
+	err = GetPrices(c, append(resources, rangeResources...), currency)
 	if err != nil {
 		return err
 	}
+
+	if cfg.StrictPricing {
+		return checkStrictPricing(resources)
+	}
+
 	return nil
 }
 
-// GetPricesConcurrent gets the prices of all resources concurrently.
-// Concurrency level is calculated using the following formula:
-// max(min(4, numCPU * 4), 16)
-func GetPricesConcurrent(c *apiclient.PricingAPIClient, resources []*schema.Resource) error {
-	// Set the number of workers
-	numWorkers := 4
-	numCPU := runtime.NumCPU()
-	if numCPU*4 > numWorkers {
-		numWorkers = numCPU * 4
-	}
-	if numWorkers > 16 {
-		numWorkers = 16
-	}
-	numJobs := len(resources)
-	jobs := make(chan *schema.Resource, numJobs)
-	resultErrors := make(chan error, numJobs)
-
-	// Fire up the workers
-	for i := 0; i < numWorkers; i++ {
-		go func(jobs <-chan *schema.Resource, resultErrors chan<- error) {
-			for r := range jobs {
-				err := GetPrices(c, r)
-				resultErrors <- err
-			}
-		}(jobs, resultErrors)
+// newPriceQuerier returns a querier for the local pricing snapshot if one is
+// configured, otherwise it returns a client for the Cloud Pricing API.
+func newPriceQuerier(cfg *config.Config) (PriceQuerier, error) {
+	if cfg.PricingSnapshotPath != "" {
+		if cfg.PriceCurrency() != "USD" {
+			return nil, errors.New("Pricing snapshots only contain USD prices, set INFRACOST_CURRENCY_RATES_FILE to convert them to another currency")
+		}
+
+		return LoadSnapshot(cfg.PricingSnapshotPath)
 	}
 
-	// Feed the workers the jobs of getting prices
+	return apiclient.NewPricingAPIClient(cfg), nil
+}
+
+// GetPrices gets the prices of all the cost components of the resources.
+// Identical queries are deduplicated so each distinct product and price filter
+// is only looked up once, and the result is then set on every cost component using it.
+func GetPrices(c PriceQuerier, resources []*schema.Resource, currency *Currency) error {
+	keys := make([]apiclient.PriceQueryKey, 0)
 	for _, r := range resources {
-		jobs <- r
+		if r.IsSkipped {
+			continue
+		}
+		keys = append(keys, apiclient.PriceQueryKeys(r)...)
 	}
 
-	// Get the result of the jobs
-	for i := 0; i < numJobs; i++ {
-		err := <-resultErrors
+	queries := make([]apiclient.PriceQuery, 0)
+	queryIdxs := make(map[string]int)
+	keyQueryIdxs := make([]int, 0, len(keys))
+
+	for _, k := range keys {
+		q := apiclient.PriceQuery{
+			ProductFilter: k.CostComponent.ProductFilter,
+			PriceFilter:   k.CostComponent.PriceFilter,
+		}
+
+		h, err := q.Hash()
 		if err != nil {
 			return err
 		}
+
+		i, ok := queryIdxs[h]
+		if !ok {
+			i = len(queries)
+			queryIdxs[h] = i
+			queries = append(queries, q)
+		}
+
+		keyQueryIdxs = append(keyQueryIdxs, i)
 	}
-	return nil
-}
 
-func GetPrices(c *apiclient.PricingAPIClient, r *schema.Resource) error {
-	if r.IsSkipped {
+	if len(queries) == 0 {
+		log.Debug("Skipping getting pricing details since there are no queries to run")
 		return nil
 	}
 
-	results, err := c.RunQueries(r)
+	log.Debugf("Looking up %d unique prices for %d cost components", len(queries), len(keys))
+
+	results, err := c.QueryPrices(queries)
 	if err != nil {
 		return err
 	}
 
-	for _, r := range results {
-		setCostComponentPrice(r.Resource, r.CostComponent, r.Result)
+	for i, k := range keys {
+		setCostComponentPrice(k.Resource, k.CostComponent, results[keyQueryIdxs[i]], currency)
 	}
 
 	return nil
 }
 
-func setCostComponentPrice(r *schema.Resource, c *schema.CostComponent, res gjson.Result) {
+func setCostComponentPrice(r *schema.Resource, c *schema.CostComponent, res gjson.Result, currency *Currency) {
 	var p decimal.Decimal
 
 	products := res.Get("data.products").Array()
@@ -96,10 +140,12 @@ func setCostComponentPrice(r *schema.Resource, c *schema.CostComponent, res gjso
 
 		log.Warnf("No products found for %s %s, using 0.00", r.Name, c.Name)
 		c.SetPrice(decimal.Zero)
+		c.SetPriceMatchStatus(schema.PriceMatchNoProducts)
 		return
 	}
 	if len(products) > 1 {
 		log.Warnf("Multiple products found for %s %s, using the first product", r.Name, c.Name)
+		c.SetPriceMatchStatus(schema.PriceMatchMultipleProducts)
 	}
 
 	prices := products[0].Get("prices").Array()
@@ -112,20 +158,50 @@ func setCostComponentPrice(r *schema.Resource, c *schema.CostComponent, res gjso
 
 		log.Warnf("No prices found for %s %s, using 0.00", r.Name, c.Name)
 		c.SetPrice(decimal.Zero)
+		c.SetPriceMatchStatus(schema.PriceMatchNoPrices)
 		return
 	}
 	if len(prices) > 1 {
 		log.Warnf("Multiple prices found for %s %s, using the first price", r.Name, c.Name)
+		if c.PriceMatchStatus() == schema.PriceMatchOK {
+			c.SetPriceMatchStatus(schema.PriceMatchMultiplePrices)
+		}
 	}
 
 	var err error
-	p, err = decimal.NewFromString(prices[0].Get("USD").String())
+	p, err = decimal.NewFromString(prices[0].Get(currency.Field).String())
 	if err != nil {
-		log.Warnf("Error converting price (using 0.00) '%v': %s", prices[0].Get("USD").String(), err.Error())
+		log.Warnf("Error converting price (using 0.00) '%v': %s", prices[0].Get(currency.Field).String(), err.Error())
 		c.SetPrice(decimal.Zero)
+		c.SetPriceMatchStatus(schema.PriceMatchInvalidPrice)
 		return
 	}
 
-	c.SetPrice(p)
+	c.SetPrice(currency.convert(p))
 	c.SetPriceHash(prices[0].Get("priceHash").String())
 }
+
+// checkStrictPricing returns an error listing every cost component that
+// could not be matched to exactly one price.
+func checkStrictPricing(resources []*schema.Resource) error {
+	msgs := make([]string, 0)
+
+	for _, r := range resources {
+		if r.IsSkipped {
+			continue
+		}
+
+		for _, k := range apiclient.PriceQueryKeys(r) {
+			status := k.CostComponent.PriceMatchStatus()
+			if status != schema.PriceMatchOK {
+				msgs = append(msgs, fmt.Sprintf("  %s %s: %s", k.Resource.Name, k.CostComponent.Name, status.Message()))
+			}
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("Could not match prices for %d cost components:\n%s", len(msgs), strings.Join(msgs, "\n"))
+}
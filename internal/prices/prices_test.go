@@ -0,0 +1,95 @@
+package prices
+
+import (
+	"testing"
+
+	"github.com/infracost/infracost/internal/apiclient"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type countingQuerier struct {
+	s       *Snapshot
+	queries []apiclient.PriceQuery
+}
+
+func (c *countingQuerier) QueryPrices(queries []apiclient.PriceQuery) ([]gjson.Result, error) {
+	c.queries = append(c.queries, queries...)
+	return c.s.QueryPrices(queries)
+}
+
+func TestGetPricesDeduplicatesQueries(t *testing.T) {
+	s, err := ParseSnapshot([]byte(testSnapshot))
+	require.NoError(t, err)
+
+	newInstance := func(name string, instanceType string) *schema.Resource {
+		return &schema.Resource{
+			Name: name,
+			CostComponents: []*schema.CostComponent{
+				{
+					Name: "Instance usage",
+					ProductFilter: &schema.ProductFilter{
+						VendorName: strPtr("aws"),
+						Service:    strPtr("AmazonEC2"),
+						Region:     strPtr("us-east-1"),
+						AttributeFilters: []*schema.AttributeFilter{
+							{Key: "instanceType", Value: strPtr(instanceType)},
+						},
+					},
+					PriceFilter: &schema.PriceFilter{PurchaseOption: strPtr("on_demand")},
+				},
+			},
+		}
+	}
+
+	resources := []*schema.Resource{
+		newInstance("aws_instance.a", "t3.medium"),
+		newInstance("aws_instance.b", "t3.medium"),
+		newInstance("aws_instance.c", "t3.large"),
+		{Name: "aws_instance.skipped", IsSkipped: true},
+	}
+
+	c := &countingQuerier{s: s}
+	require.NoError(t, GetPrices(c, resources, &Currency{Code: "USD", Field: "USD", Rate: decimal.NewFromInt(1)}))
+
+	assert.Len(t, c.queries, 2)
+	assert.True(t, decimal.RequireFromString("0.0416").Equal(resources[0].CostComponents[0].Price()))
+	assert.True(t, decimal.RequireFromString("0.0416").Equal(resources[1].CostComponents[0].Price()))
+	assert.True(t, decimal.RequireFromString("0.0832").Equal(resources[2].CostComponents[0].Price()))
+}
+
+func TestSetCostComponentPriceMatchStatus(t *testing.T) {
+	usd := &Currency{Code: "USD", Field: "USD", Rate: decimal.NewFromInt(1)}
+
+	tests := []struct {
+		name     string
+		result   string
+		expected schema.PriceMatchStatus
+	}{
+		{"exact", `{"data":{"products":[{"prices":[{"priceHash":"a","USD":"1"}]}]}}`, schema.PriceMatchOK},
+		{"no products", `{"data":{"products":[]}}`, schema.PriceMatchNoProducts},
+		{"multiple products", `{"data":{"products":[{"prices":[{"priceHash":"a","USD":"1"}]},{"prices":[]}]}}`, schema.PriceMatchMultipleProducts},
+		{"no prices", `{"data":{"products":[{"prices":[]}]}}`, schema.PriceMatchNoPrices},
+		{"multiple prices", `{"data":{"products":[{"prices":[{"priceHash":"a","USD":"1"},{"priceHash":"b","USD":"2"}]}]}}`, schema.PriceMatchMultiplePrices},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &schema.CostComponent{Name: "cc"}
+			r := &schema.Resource{Name: "r", CostComponents: []*schema.CostComponent{c}}
+
+			setCostComponentPrice(r, c, gjson.Parse(tt.result), usd)
+			assert.Equal(t, tt.expected, c.PriceMatchStatus())
+
+			err := checkStrictPricing([]*schema.Resource{r})
+			if tt.expected == schema.PriceMatchOK {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
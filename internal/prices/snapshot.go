@@ -0,0 +1,325 @@
+package prices
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/infracost/infracost/internal/apiclient"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// SnapshotProduct is a product and its prices as stored in a pricing snapshot file.
+// The fields match the ones exposed by the Cloud Pricing API.
+type SnapshotProduct struct {
+	ProductHash   string            `json:"productHash,omitempty"`
+	VendorName    string            `json:"vendorName"`
+	Service       string            `json:"service"`
+	ProductFamily string            `json:"productFamily"`
+	Region        string            `json:"region"`
+	Sku           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	Prices        []SnapshotPrice   `json:"prices"`
+}
+
+type SnapshotPrice struct {
+	PriceHash          string `json:"priceHash"`
+	PurchaseOption     string `json:"purchaseOption"`
+	Unit               string `json:"unit"`
+	Description        string `json:"description"`
+	StartUsageAmount   string `json:"startUsageAmount"`
+	EndUsageAmount     string `json:"endUsageAmount"`
+	TermLength         string `json:"termLength"`
+	TermPurchaseOption string `json:"termPurchaseOption"`
+	TermOfferingClass  string `json:"termOfferingClass"`
+	USD                string `json:"USD"`
+}
+
+type snapshotFile struct {
+	Products []*SnapshotProduct `json:"products"`
+}
+
+// Snapshot answers price queries from a local snapshot of the Cloud Pricing API
+// so that prices can be looked up without any network access.
+type Snapshot struct {
+	products map[string][]*SnapshotProduct
+
+	// byService and all are sorted so queries without a region match the same
+	// product on every run when more than one matches
+	byService map[string][]*SnapshotProduct
+	all       []*SnapshotProduct
+
+	regexMu sync.Mutex
+	regexes map[string]*regexp.Regexp
+}
+
+var (
+	loadedSnapshotsMu sync.Mutex
+	loadedSnapshots   = map[string]*Snapshot{}
+)
+
+// LoadSnapshot loads a pricing snapshot from a JSON file, which can optionally be gzip compressed.
+// Snapshots are only loaded once per path since the same snapshot is used for every project in a run.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	loadedSnapshotsMu.Lock()
+	defer loadedSnapshotsMu.Unlock()
+
+	if s, ok := loadedSnapshots[path]; ok {
+		return s, nil
+	}
+
+	log.Debugf("Loading pricing snapshot from %s", path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading pricing snapshot file")
+	}
+
+	s, err := ParseSnapshot(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing pricing snapshot file")
+	}
+
+	loadedSnapshots[path] = s
+
+	return s, nil
+}
+
+// ParseSnapshot parses the contents of a pricing snapshot file.
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	var r io.Reader = bytes.NewReader(data)
+
+	// Check for the gzip magic number
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var f snapshotFile
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshot(f.Products), nil
+}
+
+func NewSnapshot(products []*SnapshotProduct) *Snapshot {
+	s := &Snapshot{
+		products:  make(map[string][]*SnapshotProduct),
+		byService: make(map[string][]*SnapshotProduct),
+		regexes:   make(map[string]*regexp.Regexp),
+	}
+
+	s.all = make([]*SnapshotProduct, len(products))
+	copy(s.all, products)
+	sort.SliceStable(s.all, func(i, j int) bool {
+		return snapshotProductSortKey(s.all[i]) < snapshotProductSortKey(s.all[j])
+	})
+
+	for _, p := range s.all {
+		k := snapshotIndexKey(p.VendorName, p.Service, p.Region)
+		s.products[k] = append(s.products[k], p)
+
+		k = snapshotIndexKey(p.VendorName, p.Service, "")
+		s.byService[k] = append(s.byService[k], p)
+	}
+
+	return s
+}
+
+// QueryPrices returns the matching products and prices for each query.
+func (s *Snapshot) QueryPrices(queries []apiclient.PriceQuery) ([]gjson.Result, error) {
+	results := make([]gjson.Result, 0, len(queries))
+
+	for _, q := range queries {
+		res, err := s.Query(q.ProductFilter, q.PriceFilter)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// Query returns the matching products and prices in the same format as the
+// Cloud Pricing API GraphQL response for a single products query.
+func (s *Snapshot) Query(productFilter *schema.ProductFilter, priceFilter *schema.PriceFilter) (gjson.Result, error) {
+	type resultPrice struct {
+		PriceHash string `json:"priceHash"`
+		USD       string `json:"USD"`
+	}
+	type resultProduct struct {
+		Prices []resultPrice `json:"prices"`
+	}
+
+	products := make([]resultProduct, 0)
+
+	for _, p := range s.candidates(productFilter) {
+		ok, err := s.matchesProduct(p, productFilter)
+		if err != nil {
+			return gjson.Result{}, err
+		}
+		if !ok {
+			continue
+		}
+
+		prices := make([]resultPrice, 0)
+		for _, price := range p.Prices {
+			ok, err := s.matchesPrice(price, priceFilter)
+			if err != nil {
+				return gjson.Result{}, err
+			}
+			if ok {
+				prices = append(prices, resultPrice{PriceHash: price.PriceHash, USD: price.USD})
+			}
+		}
+
+		products = append(products, resultProduct{Prices: prices})
+	}
+
+	j, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"products": products,
+		},
+	})
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	return gjson.ParseBytes(j), nil
+}
+
+// candidates returns the products that could match the filter using the indexes
+// if the filter has the indexed fields set, otherwise all products.
+func (s *Snapshot) candidates(f *schema.ProductFilter) []*SnapshotProduct {
+	if f == nil || f.VendorName == nil || f.Service == nil {
+		return s.all
+	}
+
+	if f.Region != nil {
+		return s.products[snapshotIndexKey(*f.VendorName, *f.Service, *f.Region)]
+	}
+
+	return s.byService[snapshotIndexKey(*f.VendorName, *f.Service, "")]
+}
+
+func snapshotProductSortKey(p *SnapshotProduct) string {
+	return strings.Join([]string{p.ProductHash, p.VendorName, p.Service, p.Region, p.ProductFamily, p.Sku}, "\x00")
+}
+
+func (s *Snapshot) matchesProduct(p *SnapshotProduct, f *schema.ProductFilter) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	if !matchesValue(p.VendorName, f.VendorName) ||
+		!matchesValue(p.Service, f.Service) ||
+		!matchesValue(p.ProductFamily, f.ProductFamily) ||
+		!matchesValue(p.Region, f.Region) ||
+		!matchesValue(p.Sku, f.Sku) {
+		return false, nil
+	}
+
+	for _, a := range f.AttributeFilters {
+		v, ok := p.Attributes[a.Key]
+		if !ok {
+			return false, nil
+		}
+
+		if !matchesValue(v, a.Value) {
+			return false, nil
+		}
+
+		ok, err := s.matchesRegex(v, a.ValueRegex)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (s *Snapshot) matchesPrice(p SnapshotPrice, f *schema.PriceFilter) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	if !matchesValue(p.PurchaseOption, f.PurchaseOption) ||
+		!matchesValue(p.Unit, f.Unit) ||
+		!matchesValue(p.Description, f.Description) ||
+		!matchesValue(p.StartUsageAmount, f.StartUsageAmount) ||
+		!matchesValue(p.EndUsageAmount, f.EndUsageAmount) ||
+		!matchesValue(p.TermLength, f.TermLength) ||
+		!matchesValue(p.TermPurchaseOption, f.TermPurchaseOption) ||
+		!matchesValue(p.TermOfferingClass, f.TermOfferingClass) {
+		return false, nil
+	}
+
+	return s.matchesRegex(p.Description, f.DescriptionRegex)
+}
+
+func (s *Snapshot) matchesRegex(v string, pattern *string) (bool, error) {
+	if pattern == nil {
+		return true, nil
+	}
+
+	s.regexMu.Lock()
+	defer s.regexMu.Unlock()
+
+	r, ok := s.regexes[*pattern]
+	if !ok {
+		var err error
+		r, err = parseFilterRegex(*pattern)
+		if err != nil {
+			return false, errors.Wrapf(err, "Invalid regex filter %s", *pattern)
+		}
+		s.regexes[*pattern] = r
+	}
+
+	return r.MatchString(v), nil
+}
+
+func matchesValue(v string, filter *string) bool {
+	return filter == nil || v == *filter
+}
+
+// parseFilterRegex parses a regex in the format used by the Cloud Pricing API filters,
+// e.g. `/beyond the free tier/` or `/^t3\.medium$/i`.
+func parseFilterRegex(s string) (*regexp.Regexp, error) {
+	pattern := s
+	flags := ""
+
+	if strings.HasPrefix(s, "/") {
+		if i := strings.LastIndex(s, "/"); i > 0 {
+			pattern = s[1:i]
+			flags = s[i+1:]
+		}
+	}
+
+	if strings.Contains(flags, "i") {
+		pattern = "(?i)" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func snapshotIndexKey(vendorName, service, region string) string {
+	return fmt.Sprintf("%s|%s|%s", vendorName, service, region)
+}
@@ -0,0 +1,152 @@
+package prices
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSnapshot = `{
+  "products": [
+    {
+      "vendorName": "aws",
+      "service": "AmazonEC2",
+      "productFamily": "Compute Instance",
+      "region": "us-east-1",
+      "sku": "SKU1",
+      "attributes": {"instanceType": "t3.medium", "tenancy": "Shared", "operatingSystem": "Linux"},
+      "prices": [
+        {"priceHash": "hash1", "purchaseOption": "on_demand", "unit": "Hrs", "description": "$0.0416 per On Demand Linux t3.medium Instance Hour", "USD": "0.0416"},
+        {"priceHash": "hash2", "purchaseOption": "reserved", "unit": "Hrs", "termLength": "1yr", "USD": "0.026"}
+      ]
+    },
+    {
+      "vendorName": "aws",
+      "service": "AmazonEC2",
+      "productFamily": "Compute Instance",
+      "region": "us-east-1",
+      "sku": "SKU2",
+      "attributes": {"instanceType": "t3.large", "tenancy": "Dedicated", "operatingSystem": "Linux"},
+      "prices": [
+        {"priceHash": "hash3", "purchaseOption": "on_demand", "unit": "Hrs", "USD": "0.0832"}
+      ]
+    }
+  ]
+}`
+
+func TestSnapshotQuery(t *testing.T) {
+	s, err := ParseSnapshot([]byte(testSnapshot))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		productFilter *schema.ProductFilter
+		priceFilter   *schema.PriceFilter
+		expected      string
+	}{
+		{
+			name: "attribute value",
+			productFilter: &schema.ProductFilter{
+				VendorName: strPtr("aws"),
+				Service:    strPtr("AmazonEC2"),
+				Region:     strPtr("us-east-1"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "instanceType", Value: strPtr("t3.medium")},
+				},
+			},
+			priceFilter: &schema.PriceFilter{PurchaseOption: strPtr("on_demand")},
+			expected:    `{"data":{"products":[{"prices":[{"priceHash":"hash1","USD":"0.0416"}]}]}}`,
+		},
+		{
+			name: "attribute value regex",
+			productFilter: &schema.ProductFilter{
+				VendorName: strPtr("aws"),
+				Service:    strPtr("AmazonEC2"),
+				Region:     strPtr("us-east-1"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "tenancy", ValueRegex: strPtr("/dedicated/i")},
+				},
+			},
+			expected: `{"data":{"products":[{"prices":[{"priceHash":"hash3","USD":"0.0832"}]}]}}`,
+		},
+		{
+			name: "description regex",
+			productFilter: &schema.ProductFilter{
+				VendorName: strPtr("aws"),
+				Service:    strPtr("AmazonEC2"),
+				Sku:        strPtr("SKU1"),
+			},
+			priceFilter: &schema.PriceFilter{DescriptionRegex: strPtr("/On Demand Linux/")},
+			expected:    `{"data":{"products":[{"prices":[{"priceHash":"hash1","USD":"0.0416"}]}]}}`,
+		},
+		{
+			name: "no match",
+			productFilter: &schema.ProductFilter{
+				VendorName: strPtr("aws"),
+				Service:    strPtr("AmazonEC2"),
+				Region:     strPtr("eu-west-1"),
+			},
+			expected: `{"data":{"products":[]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Query(tt.productFilter, tt.priceFilter)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, res.Raw)
+		})
+	}
+}
+
+func TestParseSnapshotGzip(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(testSnapshot))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	s, err := ParseSnapshot(buf.Bytes())
+	require.NoError(t, err)
+
+	res, err := s.Query(&schema.ProductFilter{Sku: strPtr("SKU2")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0832", res.Get("data.products.0.prices.0.USD").String())
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestSnapshotQueryOrderIsStable(t *testing.T) {
+	s, err := ParseSnapshot([]byte(`{
+  "products": [
+    {"productHash": "c", "vendorName": "aws", "service": "AmazonS3", "region": "eu-west-1", "sku": "SKU3", "attributes": {}, "prices": [{"priceHash": "hash-c", "USD": "3"}]},
+    {"productHash": "a", "vendorName": "aws", "service": "AmazonS3", "region": "us-east-1", "sku": "SKU1", "attributes": {}, "prices": [{"priceHash": "hash-a", "USD": "1"}]},
+    {"productHash": "b", "vendorName": "aws", "service": "AmazonS3", "region": "us-west-2", "sku": "SKU2", "attributes": {}, "prices": [{"priceHash": "hash-b", "USD": "2"}]},
+    {"productHash": "0", "vendorName": "aws", "service": "AWSLambda", "region": "us-east-1", "sku": "SKU4", "attributes": {}, "prices": [{"priceHash": "hash-lambda", "USD": "4"}]}
+  ]
+}`))
+	require.NoError(t, err)
+
+	// Without a region the products are matched across all the regions of the service
+	filter := &schema.ProductFilter{VendorName: strPtr("aws"), Service: strPtr("AmazonS3")}
+
+	for i := 0; i < 20; i++ {
+		res, err := s.Query(filter, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "hash-a", res.Get("data.products.0.prices.0.priceHash").String())
+		assert.Equal(t, "hash-c", res.Get("data.products.2.prices.0.priceHash").String())
+		assert.Equal(t, int64(3), res.Get("data.products.#").Int())
+	}
+
+	// Without a service all the products are matched
+	res, err := s.Query(&schema.ProductFilter{VendorName: strPtr("aws")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-lambda", res.Get("data.products.0.prices.0.priceHash").String())
+	assert.Equal(t, int64(4), res.Get("data.products.#").Int())
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"strconv"
+
+	"github.com/awslabs/goformation/v4/cloudformation/rds"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetDBInstanceRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:  "AWS::RDS::DBInstance",
+		RFunc: NewDBInstance,
+	}
+}
+
+func NewDBInstance(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*rds.DBInstance)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	args := &aws.DBInstanceArguments{
+		Address:       d.Address,
+		Region:        d.Get("region").String(),
+		InstanceClass: cfr.DBInstanceClass,
+		Engine:        cfr.Engine,
+		LicenseModel:  cfr.LicenseModel,
+		MultiAZ:       cfr.MultiAZ,
+	}
+
+	if cfr.Iops > 0 {
+		iops := float64(cfr.Iops)
+		args.IOPS = &iops
+	}
+
+	// CloudFormation defaults to io1 if IOPS are specified, otherwise gp2
+	storageType := cfr.StorageType
+	if storageType == "" {
+		storageType = "gp2"
+		if args.IOPS != nil {
+			storageType = "io1"
+		}
+	}
+	args.StorageType = &storageType
+
+	if cfr.AllocatedStorage != "" {
+		allocatedStorage, err := strconv.ParseFloat(cfr.AllocatedStorage, 64)
+		if err != nil {
+			log.Warnf("Invalid AllocatedStorage %s for resource %s", cfr.AllocatedStorage, d.Address)
+		} else {
+			args.AllocatedStorage = &allocatedStorage
+		}
+	}
+
+	return aws.NewDBInstance(args)
+}
@@ -24,7 +24,7 @@ func NewDynamoDBTable(d *schema.ResourceData, u *schema.UsageData) *schema.Resou
 		return nil
 	}
 
-	region := "us-east-1" // TODO figure out how to set region
+	region := d.Get("region").String()
 	billingMode := cfr.BillingMode
 	var readCapacity int64
 	if cfr.ProvisionedThroughput != nil {
@@ -45,8 +45,5 @@ func NewDynamoDBTable(d *schema.ResourceData, u *schema.UsageData) *schema.Resou
 	}
 	args.PopulateUsage(u)
 
-	r := aws.NewDynamoDBTable(args)
-	r.Tags = mapTags(cfr.Tags)
-
-	return r
+	return aws.NewDynamoDBTable(args)
 }
@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/ec2"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetEBSVolumeRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:  "AWS::EC2::Volume",
+		RFunc: NewEBSVolume,
+	}
+}
+
+func NewEBSVolume(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*ec2.Volume)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	args := &aws.EBSVolumeArguments{
+		Address: d.Address,
+		Region:  d.Get("region").String(),
+		IOPS:    float64(cfr.Iops),
+	}
+
+	if cfr.VolumeType != "" {
+		volumeType := cfr.VolumeType
+		args.Type = &volumeType
+	}
+
+	if cfr.Size > 0 {
+		size := float64(cfr.Size)
+		args.Size = &size
+	}
+
+	if cfr.Throughput > 0 {
+		throughput := int64(cfr.Throughput)
+		args.Throughput = &throughput
+	}
+
+	args.PopulateUsage(u)
+
+	return aws.NewEBSVolume(args)
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/ecs"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetECSServiceRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:                "AWS::ECS::Service",
+		RFunc:               NewECSService,
+		ReferenceAttributes: []string{"TaskDefinition"},
+	}
+}
+
+func NewECSService(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*ecs.Service)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	args := &aws.ECSServiceArguments{
+		Address:      d.Address,
+		Region:       d.Get("region").String(),
+		LaunchType:   cfr.LaunchType,
+		DesiredCount: 1, // The CloudFormation default for new services
+	}
+
+	if d.Get("DesiredCount").Exists() {
+		args.DesiredCount = int64(cfr.DesiredCount)
+	}
+
+	refs := d.References("TaskDefinition")
+	if len(refs) > 0 {
+		if taskDefinition, ok := refs[0].CFResource.(*ecs.TaskDefinition); ok {
+			args.Memory = taskDefinition.Memory
+			args.CPU = taskDefinition.Cpu
+
+			if len(taskDefinition.InferenceAccelerators) > 0 {
+				deviceType := taskDefinition.InferenceAccelerators[0].DeviceType
+				args.InferenceAcceleratorDeviceType = &deviceType
+			}
+		}
+	}
+
+	return aws.NewECSService(args)
+}
@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/elasticloadbalancing"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetELBRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:  "AWS::ElasticLoadBalancing::LoadBalancer",
+		RFunc: NewELB,
+	}
+}
+
+func NewELB(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	if _, ok := d.CFResource.(*elasticloadbalancing.LoadBalancer); !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	args := &aws.ELBArguments{
+		Address: d.Address,
+		Region:  d.Get("region").String(),
+	}
+	args.PopulateUsage(u)
+
+	return aws.NewELB(args)
+}
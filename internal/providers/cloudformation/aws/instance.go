@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/awslabs/goformation/v4/cloudformation/ec2"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetInstanceRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name: "AWS::EC2::Instance",
+		Notes: []string{
+			"Costs associated with marketplace AMIs are not supported.",
+			"For non-standard Linux AMIs such as Windows and RHEL, the operating system should be specified in usage file.",
+			"EC2 detailed monitoring assumes the standard 7 metrics and the lowest tier of prices for CloudWatch.",
+			"If a root volume is not specified then an 8Gi gp2 volume is assumed.",
+		},
+		RFunc: NewInstance,
+	}
+}
+
+func NewInstance(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*ec2.Instance)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	tenancy := "Shared"
+	if strings.ToLower(cfr.Tenancy) == "host" {
+		log.Warnf("Skipping resource %s. Infracost currently does not support host tenancy for AWS EC2 instances", d.Address)
+		return nil
+	} else if strings.ToLower(cfr.Tenancy) == "dedicated" {
+		tenancy = "Dedicated"
+	}
+
+	instanceType := cfr.InstanceType
+	if instanceType == "" {
+		instanceType = "m1.small" // The CloudFormation default
+	}
+
+	region := d.Get("region").String()
+
+	args := &aws.InstanceArguments{
+		Address:          d.Address,
+		Region:           region,
+		Tenancy:          tenancy,
+		PurchaseOption:   "on_demand",
+		InstanceType:     instanceType,
+		EBSOptimized:     cfr.EbsOptimized,
+		EnableMonitoring: cfr.Monitoring,
+	}
+
+	if cfr.CreditSpecification != nil {
+		args.CPUCredits = cfr.CreditSpecification.CPUCredits
+	}
+
+	for i, m := range cfr.BlockDeviceMappings {
+		// Instance store volumes are included in the instance price
+		if m.Ebs == nil {
+			continue
+		}
+
+		b := &aws.EBSVolumeArguments{
+			Address: fmt.Sprintf("BlockDeviceMappings[%d]", i),
+			Region:  region,
+			IOPS:    float64(m.Ebs.Iops),
+		}
+
+		if m.Ebs.VolumeType != "" {
+			volumeType := m.Ebs.VolumeType
+			b.Type = &volumeType
+		}
+
+		if m.Ebs.VolumeSize > 0 {
+			size := float64(m.Ebs.VolumeSize)
+			b.Size = &size
+		}
+
+		if isRootDeviceName(m.DeviceName) {
+			args.RootBlockDevice = b
+		} else {
+			args.EBSBlockDevices = append(args.EBSBlockDevices, b)
+		}
+	}
+
+	if args.RootBlockDevice == nil {
+		args.RootBlockDevice = &aws.EBSVolumeArguments{
+			Address: "RootBlockDevice",
+			Region:  region,
+		}
+	}
+
+	args.PopulateUsage(u)
+
+	return aws.NewInstance(args)
+}
+
+// rootDeviceNameRegex matches the root device names used by AMIs, e.g. /dev/xvda for
+// Amazon Linux, /dev/sda1 for Ubuntu and Windows, and /dev/nvme0n1 for Nitro instances.
+var rootDeviceNameRegex = regexp.MustCompile(`^(/dev/)?((xv|s)da\d*|nvme0n1(p\d+)?)$`)
+
+// isRootDeviceName returns true if the device name is one of the root device names used
+// by AMIs. The template doesn't say which AMI is used, so the common names are matched.
+func isRootDeviceName(name string) bool {
+	return rootDeviceNameRegex.MatchString(name)
+}
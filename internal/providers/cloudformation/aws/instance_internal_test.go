@@ -0,0 +1,33 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRootDeviceName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"/dev/xvda", true},
+		{"/dev/xvda1", true},
+		{"xvda", true},
+		{"/dev/sda", true},
+		{"/dev/sda1", true},
+		{"/dev/nvme0n1", true},
+		{"/dev/nvme0n1p1", true},
+		{"/dev/sdf", false},
+		{"/dev/xvdb", false},
+		{"/dev/sdaa", false},
+		{"/dev/nvme1n1", false},
+		{"xvdf", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isRootDeviceName(test.name), test.name)
+	}
+}
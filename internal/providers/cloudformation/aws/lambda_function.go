@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/lambda"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetLambdaFunctionRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:  "AWS::Lambda::Function",
+		Notes: []string{"Provisioned concurrency is not yet supported."},
+		RFunc: NewLambdaFunction,
+	}
+}
+
+func NewLambdaFunction(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*lambda.Function)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	var memorySize int64 = 128
+	if cfr.MemorySize > 0 {
+		memorySize = int64(cfr.MemorySize)
+	}
+
+	args := &aws.LambdaFunctionArguments{
+		Address:    d.Address,
+		Region:     d.Get("region").String(),
+		MemorySize: memorySize,
+	}
+	args.PopulateUsage(u)
+
+	return aws.NewLambdaFunction(args)
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/elasticloadbalancingv2"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetLBRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name:  "AWS::ElasticLoadBalancingV2::LoadBalancer",
+		RFunc: NewLB,
+	}
+}
+
+func NewLB(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*elasticloadbalancingv2.LoadBalancer)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	loadBalancerType := cfr.Type
+	if loadBalancerType == "" {
+		loadBalancerType = "application"
+	}
+
+	args := &aws.LBArguments{
+		Address:          d.Address,
+		Region:           d.Get("region").String(),
+		LoadBalancerType: loadBalancerType,
+	}
+	args.PopulateUsage(u)
+
+	return aws.NewLB(args)
+}
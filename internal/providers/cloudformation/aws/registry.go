@@ -20,7 +20,7 @@ var ResourceRegistry []*schema.RegistryItem = []*schema.RegistryItem{
 	// GetConfigOrganizationCustomRuleItem(),
 	// GetConfigOrganizationManagedRuleItem(),
 	// GetDataTransferRegistryItem(),
-	// GetDBInstanceRegistryItem(),
+	GetDBInstanceRegistryItem(),
 	// GetDMSRegistryItem(),
 	// GetDocDBClusterInstanceRegistryItem(),
 	// GetDocDBClusterRegistryItem(),
@@ -30,29 +30,29 @@ var ResourceRegistry []*schema.RegistryItem = []*schema.RegistryItem{
 	GetDynamoDBTableRegistryItem(),
 	// GetEBSSnapshotCopyRegistryItem(),
 	// GetEBSSnapshotRegistryItem(),
-	// GetEBSVolumeRegistryItem(),
+	GetEBSVolumeRegistryItem(),
 	// GetEC2ClientVPNEndpointRegistryItem(),
 	// GetEC2ClientVPNNetworkAssociationRegistryItem(),
 	// GetEC2TrafficMirroSessionRegistryItem(),
 	// GetEC2TransitGatewayPeeringAttachmentRegistryItem(),
 	// GetEC2TransitGatewayVpcAttachmentRegistryItem(),
 	// GetECRRegistryItem(),
-	// GetECSServiceRegistryItem(),
+	GetECSServiceRegistryItem(),
 	// GetEFSFileSystemRegistryItem(),
 	// GetEIPRegistryItem(),
 	// GetElastiCacheClusterItem(),
 	// GetElastiCacheReplicationGroupItem(),
 	// GetElasticsearchDomainRegistryItem(),
-	// GetELBRegistryItem(),
+	GetELBRegistryItem(),
 	// GetFSXWindowsFSRegistryItem(),
-	// GetInstanceRegistryItem(),
-	// GetLambdaFunctionRegistryItem(),
-	// GetLBRegistryItem(),
+	GetInstanceRegistryItem(),
+	GetLambdaFunctionRegistryItem(),
+	GetLBRegistryItem(),
 	// GetLightsailInstanceRegistryItem(),
 	// GetMSKClusterRegistryItem(),
 	// GetALBRegistryItem(),
 	// GetMQBrokerRegistryItem(),
-	// GetNATGatewayRegistryItem(),
+	GetNATGatewayRegistryItem(),
 	// GetRDSClusterRegistryItem(),
 	// GetRDSClusterInstanceRegistryItem(),
 	// GetRedshiftClusterRegistryItem(),
@@ -60,7 +60,7 @@ var ResourceRegistry []*schema.RegistryItem = []*schema.RegistryItem{
 	// GetRoute53ResolverEndpointRegistryItem(),
 	// GetRoute53RecordRegistryItem(),
 	// GetRoute53ZoneRegistryItem(),
-	// GetS3BucketRegistryItem(),
+	GetS3BucketRegistryItem(),
 	// GetS3BucketAnalyticsConfigurationRegistryItem(),
 	// GetS3BucketInventoryRegistryItem(),
 	// GetSecretsManagerSecret(),
@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"github.com/awslabs/goformation/v4/cloudformation/s3"
+	"github.com/infracost/infracost/internal/resources/aws"
+	"github.com/infracost/infracost/internal/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetS3BucketRegistryItem() *schema.RegistryItem {
+	return &schema.RegistryItem{
+		Name: "AWS::S3::Bucket",
+		Notes: []string{
+			"S3 replication time control data transfer, and batch operations are not supported.",
+		},
+		RFunc: NewS3Bucket,
+	}
+}
+
+func NewS3Bucket(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
+	cfr, ok := d.CFResource.(*s3.Bucket)
+	if !ok {
+		log.Warnf("Skipping resource %s as it did not have the expected type (got %T)", d.Address, d.CFResource)
+		return nil
+	}
+
+	args := &aws.S3BucketArguments{
+		Address: d.Address,
+		Region:  d.Get("region").String(),
+	}
+
+	if cfr.LifecycleConfiguration != nil {
+		for _, rule := range cfr.LifecycleConfiguration.Rules {
+			if len(rule.TagFilters) > 0 {
+				args.ObjectTagsEnabled = true
+			}
+
+			if rule.Status != "Enabled" {
+				continue
+			}
+
+			transitions := rule.Transitions
+			if rule.Transition != nil {
+				transitions = append(transitions, *rule.Transition)
+			}
+			for _, t := range transitions {
+				args.LifecycleStorageClasses = append(args.LifecycleStorageClasses, t.StorageClass)
+			}
+
+			noncurrentTransitions := rule.NoncurrentVersionTransitions
+			if rule.NoncurrentVersionTransition != nil {
+				noncurrentTransitions = append(noncurrentTransitions, *rule.NoncurrentVersionTransition)
+			}
+			for _, t := range noncurrentTransitions {
+				args.LifecycleStorageClasses = append(args.LifecycleStorageClasses, t.StorageClass)
+			}
+		}
+	}
+
+	args.PopulateUsage(u)
+
+	return aws.NewS3Bucket(args)
+}
@@ -1,6 +1,7 @@
 package cloudformation
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -12,11 +13,12 @@ import (
 )
 
 type Parser struct {
-	ctx *config.ProjectContext
+	ctx    *config.ProjectContext
+	region string
 }
 
-func NewParser(ctx *config.ProjectContext) *Parser {
-	return &Parser{ctx}
+func NewParser(ctx *config.ProjectContext, region string) *Parser {
+	return &Parser{ctx, region}
 }
 
 func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *schema.Resource {
@@ -32,6 +34,7 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 				Name:         d.Address,
 				ResourceType: d.Type,
 				Tags:         d.Tags,
+				Identifiers:  resourceIdentifiers(d),
 				IsSkipped:    true,
 				NoPrice:      true,
 				SkipMessage:  "Free resource.",
@@ -41,8 +44,11 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 		res := registryItem.RFunc(d, u)
 		if res != nil {
 			res.ResourceType = d.Type
-			// TODO: Figure out how to set tags.  For now, have the RFunc set them.
-			// res.Tags = d.Tags
+			res.Tags = d.Tags
+			res.Identifiers = resourceIdentifiers(d)
+			res.Range = schema.NewResourceRange(u, func(u *schema.UsageData) *schema.Resource {
+				return p.createResource(d, u)
+			})
 			return res
 		}
 	}
@@ -51,31 +57,55 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 		Name:         d.Address,
 		ResourceType: d.Type,
 		Tags:         d.Tags,
+		Identifiers:  resourceIdentifiers(d),
 		IsSkipped:    true,
 		SkipMessage:  "This resource is not currently supported",
 	}
 }
 
+// identifierProperties are the properties that name the deployed resource. Resources
+// that don't set one get a name generated by CloudFormation, or an ID like NAT
+// gateways, that isn't known from the template so they can't be identified.
+var identifierProperties = []string{"FunctionName", "BucketName", "TableName", "QueueName", "TopicName", "LogGroupName", "DeliveryStreamName", "Name"}
+
+// resourceIdentifiers returns the values of the resource's identifier properties that
+// are set in the template.
+func resourceIdentifiers(d *schema.ResourceData) []string {
+	identifiers := make([]string, 0)
+	for _, prop := range identifierProperties {
+		v := d.Get(prop)
+		if v.Type == gjson.String && v.String() != "" {
+			identifiers = append(identifiers, v.String())
+		}
+	}
+
+	return identifiers
+}
+
 func (p *Parser) parseTemplate(t *cloudformation.Template, usage map[string]*schema.UsageData) ([]*schema.Resource, []*schema.Resource, error) {
 	baseResources := p.loadUsageFileResources(usage)
 
 	var resources []*schema.Resource
 	resources = append(resources, baseResources...)
 
+	resourceDataMap := make(map[string]*schema.ResourceData, len(t.Resources))
+
 	for name, d := range t.Resources {
-		tags := map[string]string{} // TODO: Where do I get tags?
-		var usageData *schema.UsageData
+		v, err := p.resourceValues(d)
+		if err != nil {
+			return resources, resources, err
+		}
 
-		if ud := usage[name]; ud != nil {
-			usageData = ud
-		} else if strings.HasSuffix(name, "]") {
-			lastIndexOfOpenBracket := strings.LastIndex(name, "[")
+		resourceData := schema.NewCFResourceData(d.AWSCloudFormationType(), "aws", name, parseTags(v), d)
+		resourceData.RawValues = v
+		resourceDataMap[name] = resourceData
+	}
 
-			if arrayUsageData := usage[fmt.Sprintf("%s[*]", name[:lastIndexOfOpenBracket])]; arrayUsageData != nil {
-				usageData = arrayUsageData
-			}
-		}
-		resourceData := schema.NewCFResourceData(d.AWSCloudFormationType(), "aws", name, tags, d)
+	parseReferences(resourceDataMap)
+
+	usageIndex := schema.NewUsageIndex(usage)
+	for name, resourceData := range resourceDataMap {
+		usageData := usageIndex.Find(name, resourceData.Type)
 
 		if r := p.createResource(resourceData, usageData); r != nil {
 			resources = append(resources, r)
@@ -85,6 +115,63 @@ func (p *Parser) parseTemplate(t *cloudformation.Template, usage map[string]*sch
 	return resources, resources, nil
 }
 
+// resourceValues returns the properties of the resource with the region added, so they
+// can be read the same way as Terraform resource values.
+func (p *Parser) resourceValues(d cloudformation.Resource) (gjson.Result, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	props := gjson.GetBytes(b, "Properties")
+	if !props.IsObject() {
+		props = gjson.Parse("{}")
+	}
+
+	return schema.AddRawValue(props, "region", p.region), nil
+}
+
+// parseReferences links each resource to the resources referenced by the properties in
+// the ReferenceAttributes of its registry item. A Ref to a resource is resolved to the
+// logical ID of the resource when the template is loaded.
+func parseReferences(resourceDataMap map[string]*schema.ResourceData) {
+	registryMap := GetResourceRegistryMap()
+
+	for _, d := range resourceDataMap {
+		registryItem, ok := (*registryMap)[d.Type]
+		if !ok {
+			continue
+		}
+
+		for _, attr := range registryItem.ReferenceAttributes {
+			if ref, ok := resourceDataMap[d.Get(attr).String()]; ok {
+				d.AddReference(attr, ref)
+			}
+		}
+	}
+}
+
+// parseTags returns the Tags property of the resource. Most resources have a list of
+// Key/Value pairs, but some resources, e.g. SAM resources, have a map.
+func parseTags(v gjson.Result) map[string]string {
+	tags := make(map[string]string)
+
+	t := v.Get("Tags")
+	if t.IsArray() {
+		for _, tag := range t.Array() {
+			if tag.Get("Key").Exists() {
+				tags[tag.Get("Key").String()] = tag.Get("Value").String()
+			}
+		}
+	} else if t.IsObject() {
+		for k, v := range t.Map() {
+			tags[k] = v.String()
+		}
+	}
+
+	return tags
+}
+
 func (p *Parser) loadUsageFileResources(u map[string]*schema.UsageData) []*schema.Resource {
 	resources := make([]*schema.Resource, 0)
 
@@ -103,5 +190,5 @@ func (p *Parser) loadUsageFileResources(u map[string]*schema.UsageData) []*schem
 }
 
 func isAwsChina(d *schema.ResourceData) bool {
-	return strings.HasPrefix(d.Type, "aws_") && strings.HasPrefix(d.Get("region").String(), "cn-")
+	return strings.HasPrefix(d.Type, "AWS::") && strings.HasPrefix(d.Get("region").String(), "cn-")
 }
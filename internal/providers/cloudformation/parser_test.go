@@ -0,0 +1,163 @@
+package cloudformation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResourcesTemplate = `
+Resources:
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      InstanceType: t3.medium
+      EbsOptimized: true
+      BlockDeviceMappings:
+        - DeviceName: /dev/sdf
+          Ebs:
+            VolumeSize: 100
+            VolumeType: io1
+            Iops: 1000
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      AvailabilityZone: eu-west-2a
+      Size: 50
+      VolumeType: gp3
+      Throughput: 200
+  Database:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      DBInstanceClass: db.t3.large
+      Engine: postgres
+      AllocatedStorage: "20"
+      MultiAZ: true
+  Function:
+    Type: AWS::Lambda::Function
+    Properties:
+      FunctionName: api
+      MemorySize: 512
+  NatGateway:
+    Type: AWS::EC2::NatGateway
+    Properties:
+      SubnetId: subnet-12345678
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      LifecycleConfiguration:
+        Rules:
+          - Status: Enabled
+            Transitions:
+              - StorageClass: GLACIER
+                TransitionInDays: 30
+  LoadBalancer:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    Properties:
+      Subnets: [subnet-12345678]
+  ClassicLoadBalancer:
+    Type: AWS::ElasticLoadBalancing::LoadBalancer
+    Properties:
+      Listeners:
+        - LoadBalancerPort: "80"
+          InstancePort: "80"
+          Protocol: HTTP
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Cpu: "1 vCPU"
+      Memory: "2048"
+  Service:
+    Type: AWS::ECS::Service
+    Properties:
+      LaunchType: FARGATE
+      DesiredCount: 2
+      TaskDefinition: !Ref TaskDefinition
+`
+
+func TestParseTemplateResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "template.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testResourcesTemplate), 0600))
+
+	tmpl, err := loadTemplate(path, "eu-west-2", nil)
+	require.NoError(t, err)
+
+	usage := schema.NewUsageMap(map[string]interface{}{
+		"Function": map[string]interface{}{
+			"monthly_requests":    1000000,
+			"request_duration_ms": 100,
+		},
+	})
+
+	p := NewParser(config.EmptyProjectContext(), "eu-west-2")
+	_, resources, err := p.parseTemplate(tmpl, usage)
+	require.NoError(t, err)
+
+	resourceMap := make(map[string]*schema.Resource)
+	for _, r := range resources {
+		resourceMap[r.Name] = r
+	}
+
+	costComponentNames := func(r *schema.Resource) []string {
+		names := make([]string, 0, len(r.CostComponents))
+		for _, c := range r.CostComponents {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	instance := resourceMap["Instance"]
+	require.NotNil(t, instance)
+	assert.Equal(t, []string{"Instance usage (Linux/UNIX, on-demand, t3.medium)", "EBS-optimized usage", "CPU credits"}, costComponentNames(instance))
+	require.Len(t, instance.SubResources, 2)
+	assert.Equal(t, "RootBlockDevice", instance.SubResources[0].Name)
+	assert.Equal(t, "BlockDeviceMappings[0]", instance.SubResources[1].Name)
+	assert.Equal(t, []string{"Storage (provisioned IOPS SSD, io1)", "Provisioned IOPS"}, costComponentNames(instance.SubResources[1]))
+	assert.Equal(t, "eu-west-2", *instance.CostComponents[0].ProductFilter.Region)
+
+	volume := resourceMap["Volume"]
+	require.NotNil(t, volume)
+	assert.Equal(t, []string{"Storage (general purpose SSD, gp3)", "Provisioned throughput"}, costComponentNames(volume))
+	assert.Equal(t, "75", volume.CostComponents[1].MonthlyQuantity.String())
+
+	database := resourceMap["Database"]
+	require.NotNil(t, database)
+	assert.Equal(t, []string{"Database instance", "Database storage"}, costComponentNames(database))
+	assert.Equal(t, "20", database.CostComponents[1].MonthlyQuantity.String())
+
+	function := resourceMap["Function"]
+	require.NotNil(t, function)
+	assert.Equal(t, "50000", function.CostComponents[1].MonthlyQuantity.String())
+	assert.Equal(t, []string{"api"}, function.Identifiers)
+
+	require.NotNil(t, resourceMap["NatGateway"])
+	assert.Equal(t, []string{"NAT gateway", "Data processed"}, costComponentNames(resourceMap["NatGateway"]))
+
+	bucket := resourceMap["Bucket"]
+	require.NotNil(t, bucket)
+	require.Len(t, bucket.SubResources, 2)
+	assert.Equal(t, "Glacier", bucket.SubResources[0].Name)
+	assert.Equal(t, "Standard", bucket.SubResources[1].Name)
+
+	require.NotNil(t, resourceMap["LoadBalancer"])
+	assert.Equal(t, []string{"Application load balancer", "Load balancer capacity units"}, costComponentNames(resourceMap["LoadBalancer"]))
+
+	require.NotNil(t, resourceMap["ClassicLoadBalancer"])
+	assert.Equal(t, []string{"Classic load balancer", "Data processed"}, costComponentNames(resourceMap["ClassicLoadBalancer"]))
+
+	service := resourceMap["Service"]
+	require.NotNil(t, service)
+	assert.Equal(t, []string{"Per GB per hour", "Per vCPU per hour"}, costComponentNames(service))
+	assert.Equal(t, "4", service.CostComponents[0].HourlyQuantity.String())
+	assert.Equal(t, "2", service.CostComponents[1].HourlyQuantity.String())
+}
@@ -0,0 +1,281 @@
+package cloudformation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/awslabs/goformation/v4"
+	"github.com/awslabs/goformation/v4/cloudformation"
+	"github.com/awslabs/goformation/v4/intrinsics"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadTemplate reads a CloudFormation template, resolving parameters, conditions and
+// intrinsic functions. Resources with a condition that evaluates to false are removed.
+func loadTemplate(path string, region string, parameters map[string]interface{}) (*cloudformation.Template, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	isYAML := !strings.HasSuffix(path, ".json")
+	if !isYAML {
+		data, isYAML = stackTemplateBody(data)
+	}
+
+	if isYAML {
+		// Convert the YAML to JSON, including any short form intrinsic functions like !Ref
+		data, err = intrinsics.ProcessYAML(data, &intrinsics.ProcessorOptions{NoProcess: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var raw map[string]interface{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid template JSON")
+	}
+
+	setParameterValues(raw, parameters)
+
+	opts := &intrinsics.ProcessorOptions{
+		EvaluateConditions: true,
+		IntrinsicHandlerOverrides: map[string]intrinsics.IntrinsicHandler{
+			"Ref":     refHandler(region),
+			"Fn::Sub": subHandler(region),
+		},
+	}
+
+	conditions, err := evaluateConditions(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	removeConditionalResources(raw, conditions)
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return goformation.ParseJSONWithOptions(data, opts)
+}
+
+// stackTemplateBody returns the template from the output of `aws cloudformation
+// get-template`, which wraps the template of a deployed stack in a TemplateBody key. The
+// template body is a string if the stack was created from a YAML template, so it's
+// returned with true to indicate it should be processed as YAML. Any other JSON is
+// returned as is.
+func stackTemplateBody(data []byte) ([]byte, bool) {
+	var stack struct {
+		TemplateBody json.RawMessage
+	}
+
+	err := json.Unmarshal(data, &stack)
+	if err != nil || len(stack.TemplateBody) == 0 {
+		return data, false
+	}
+
+	var body string
+	if err := json.Unmarshal(stack.TemplateBody, &body); err == nil {
+		return []byte(body), true
+	}
+
+	return stack.TemplateBody, false
+}
+
+// setParameterValues sets the default of each parameter to the value from the parameters
+// file, if any. Parameter values are often strings, so the values of Number parameters
+// are converted to numbers so they can be used for numeric properties.
+func setParameterValues(raw map[string]interface{}, values map[string]interface{}) {
+	params, ok := raw["Parameters"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, p := range params {
+		param, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if v, ok := values[name]; ok {
+			param["Default"] = v
+		}
+
+		if s, ok := param["Default"].(string); ok && param["Type"] == "Number" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				param["Default"] = f
+			}
+		}
+	}
+}
+
+// evaluateConditions returns the value of each condition in the Conditions section.
+func evaluateConditions(raw map[string]interface{}, opts *intrinsics.ProcessorOptions) (map[string]bool, error) {
+	conditions := make(map[string]bool)
+
+	if _, ok := raw["Conditions"]; !ok {
+		return conditions, nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"Parameters": raw["Parameters"],
+		"Mappings":   raw["Mappings"],
+		"Conditions": raw["Conditions"],
+	})
+	if err != nil {
+		return conditions, err
+	}
+
+	processed, err := intrinsics.ProcessJSON(data, opts)
+	if err != nil {
+		return conditions, errors.Wrap(err, "Error evaluating conditions")
+	}
+
+	var result struct {
+		Conditions map[string]interface{}
+	}
+	err = json.Unmarshal(processed, &result)
+	if err != nil {
+		return conditions, errors.Wrap(err, "Error evaluating conditions")
+	}
+
+	for name, v := range result.Conditions {
+		if b, ok := v.(bool); ok {
+			conditions[name] = b
+		} else {
+			log.Debugf("Could not evaluate CloudFormation condition %s", name)
+		}
+	}
+
+	return conditions, nil
+}
+
+// removeConditionalResources removes the resources whose condition is false. The
+// Condition key is removed from the other resources since the intrinsic function
+// processor would otherwise replace the whole resource with the condition value.
+func removeConditionalResources(raw map[string]interface{}, conditions map[string]bool) {
+	resources, ok := raw["Resources"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, r := range resources {
+		res, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		condition, ok := res["Condition"].(string)
+		if !ok {
+			continue
+		}
+
+		if v, ok := conditions[condition]; ok && !v {
+			log.Debugf("Skipping resource %s as condition %s is false", name, condition)
+			delete(resources, name)
+			continue
+		}
+
+		delete(res, "Condition")
+	}
+}
+
+// refHandler resolves Ref the same as the default handler, except the AWS::Region pseudo
+// parameter is resolved to the given region instead of us-east-1, and a Ref to a resource
+// is resolved to the logical ID of the resource so the resources can be linked.
+func refHandler(region string) intrinsics.IntrinsicHandler {
+	return func(name string, input interface{}, template interface{}) interface{} {
+		s, ok := input.(string)
+		if ok && s == "AWS::Region" {
+			return region
+		}
+
+		v := intrinsics.Ref(name, input, template)
+		if v == nil && ok && isTemplateResource(template, s) {
+			return s
+		}
+
+		return v
+	}
+}
+
+func isTemplateResource(template interface{}, name string) bool {
+	t, ok := template.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	resources, ok := t["Resources"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, ok = resources[name]
+	return ok
+}
+
+// subHandler resolves Fn::Sub the same as the default handler, except ${AWS::Region} is
+// replaced with the given region.
+func subHandler(region string) intrinsics.IntrinsicHandler {
+	return func(name string, input interface{}, template interface{}) interface{} {
+		switch v := input.(type) {
+		case string:
+			input = strings.ReplaceAll(v, "${AWS::Region}", region)
+		case []interface{}:
+			if len(v) > 0 {
+				if s, ok := v[0].(string); ok {
+					v[0] = strings.ReplaceAll(s, "${AWS::Region}", region)
+				}
+			}
+		}
+
+		return intrinsics.FnSub(name, input, template)
+	}
+}
+
+// loadParametersFile reads parameter values from a file in either the AWS CLI format:
+//
+//	[{"ParameterKey": "InstanceType", "ParameterValue": "t3.micro"}]
+//
+// or the CodePipeline template configuration format:
+//
+//	{"Parameters": {"InstanceType": "t3.micro"}}
+func loadParametersFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading CloudFormation parameters file")
+	}
+
+	params := make(map[string]interface{})
+
+	var cliParams []struct {
+		ParameterKey   string
+		ParameterValue interface{}
+	}
+	if err := json.Unmarshal(data, &cliParams); err == nil {
+		for _, p := range cliParams {
+			params[p.ParameterKey] = p.ParameterValue
+		}
+
+		return params, nil
+	}
+
+	var configParams struct {
+		Parameters map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &configParams); err != nil {
+		return nil, errors.Wrap(err, "Error parsing CloudFormation parameters file")
+	}
+
+	for k, v := range configParams.Parameters {
+		params[k] = v
+	}
+
+	return params, nil
+}
@@ -1,22 +1,47 @@
 package cloudformation
 
 import (
-	"github.com/awslabs/goformation/v4"
+	"os"
+
 	"github.com/infracost/infracost/internal/config"
 	"github.com/infracost/infracost/internal/schema"
 	"github.com/pkg/errors"
 )
 
+const defaultRegion = "us-east-1"
+
 type TemplateProvider struct {
-	ctx  *config.ProjectContext
-	Path string
+	ctx            *config.ProjectContext
+	Path           string
+	Region         string
+	ParametersFile string
+	CompareTo      string
+
+	CompareToParametersFile string
 }
 
 func NewTemplateProvider(ctx *config.ProjectContext) schema.Provider {
 	return &TemplateProvider{
-		ctx:  ctx,
-		Path: ctx.ProjectConfig.Path,
+		ctx:            ctx,
+		Path:           ctx.ProjectConfig.Path,
+		Region:         templateRegion(ctx.ProjectConfig.CloudFormationRegion),
+		ParametersFile: ctx.ProjectConfig.CloudFormationParametersFile,
+		CompareTo:      ctx.ProjectConfig.CloudFormationCompareTo,
+
+		CompareToParametersFile: ctx.ProjectConfig.CloudFormationCompareToParametersFile,
+	}
+}
+
+// templateRegion returns the region the stack is deployed to. If it's not configured
+// the region from the environment is used, the same as the AWS CLI.
+func templateRegion(region string) string {
+	for _, r := range []string{region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if r != "" {
+			return r
+		}
 	}
+
+	return defaultRegion
 }
 
 func (p *TemplateProvider) Type() string {
@@ -32,17 +57,54 @@ func (p *TemplateProvider) AddMetadata(metadata *schema.ProjectMetadata) {
 }
 
 func (p *TemplateProvider) LoadResources(project *schema.Project, usage map[string]*schema.UsageData) error {
-	template, err := goformation.Open(p.Path)
+	var parameters map[string]interface{}
+	if p.ParametersFile != "" {
+		var err error
+		parameters, err = loadParametersFile(p.ParametersFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	template, err := loadTemplate(p.Path, p.Region, parameters)
 	if err != nil {
 		return errors.Wrap(err, "Error reading Cloudformation template file")
 	}
 
-	parser := NewParser(p.ctx)
+	parser := NewParser(p.ctx, p.Region)
 	pastResources, resources, err := parser.parseTemplate(template, usage)
 	if err != nil {
 		return errors.Wrap(err, "Error parsing Cloudformation template file")
 	}
 
+	// When comparing to a previous template, e.g. the template of the deployed stack, the
+	// past resources are the resources from that template so the diff shows the cost
+	// impact of the stack update.
+	if p.CompareTo != "" {
+		// The stack parameters often change with the template, so the compare to template
+		// can have its own parameters file. Otherwise both templates use the same parameters.
+		compareParameters := parameters
+		if p.CompareToParametersFile != "" {
+			compareParameters, err = loadParametersFile(p.CompareToParametersFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		compareTemplate, err := loadTemplate(p.CompareTo, p.Region, compareParameters)
+		if err != nil {
+			return errors.Wrap(err, "Error reading Cloudformation compare to template file")
+		}
+
+		_, pastResources, err = parser.parseTemplate(compareTemplate, usage)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing Cloudformation compare to template file")
+		}
+	}
+
+	// There's only a past breakdown to diff against if the template is compared to a
+	// previous template, e.g. so budgets on the increase aren't checked on the first run.
+	project.HasDiff = p.CompareTo != ""
 	project.PastResources = pastResources
 	project.Resources = resources
 
@@ -0,0 +1,222 @@
+package cloudformation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awslabs/goformation/v4/cloudformation/dynamodb"
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplate = `
+Parameters:
+  Env:
+    Type: String
+    Default: dev
+  ReadCapacity:
+    Type: Number
+    Default: 5
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+Resources:
+  Table:
+    Type: AWS::DynamoDB::Table
+    Properties:
+      TableName: !Sub "${AWS::Region}-table"
+      BillingMode: !If [IsProd, PROVISIONED, PAY_PER_REQUEST]
+      ProvisionedThroughput:
+        ReadCapacityUnits: !Ref ReadCapacity
+        WriteCapacityUnits: 5
+      Tags:
+        - Key: Env
+          Value: !Ref Env
+  ProdTable:
+    Type: AWS::DynamoDB::Table
+    Condition: IsProd
+    Properties:
+      BillingMode: PAY_PER_REQUEST
+`
+
+func TestLoadTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "template.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testTemplate), 0600))
+
+	tmpl, err := loadTemplate(path, "eu-west-2", nil)
+	require.NoError(t, err)
+
+	assert.Len(t, tmpl.Resources, 1)
+	table, ok := tmpl.Resources["Table"].(*dynamodb.Table)
+	require.True(t, ok)
+	assert.Equal(t, "eu-west-2-table", table.TableName)
+	assert.Equal(t, "PAY_PER_REQUEST", table.BillingMode)
+
+	paramsPath := filepath.Join(dir, "params.json")
+	require.NoError(t, ioutil.WriteFile(paramsPath, []byte(`[{"ParameterKey": "Env", "ParameterValue": "prod"}, {"ParameterKey": "ReadCapacity", "ParameterValue": "10"}]`), 0600))
+
+	params, err := loadParametersFile(paramsPath)
+	require.NoError(t, err)
+
+	tmpl, err = loadTemplate(path, "eu-west-2", params)
+	require.NoError(t, err)
+
+	assert.Len(t, tmpl.Resources, 2)
+	table, ok = tmpl.Resources["Table"].(*dynamodb.Table)
+	require.True(t, ok)
+	assert.Equal(t, "PROVISIONED", table.BillingMode)
+	assert.Equal(t, int64(10), table.ProvisionedThroughput.ReadCapacityUnits)
+
+	p := NewParser(config.EmptyProjectContext(), "eu-west-2")
+	_, resources, err := p.parseTemplate(tmpl, map[string]*schema.UsageData{})
+	require.NoError(t, err)
+
+	for _, r := range resources {
+		if r.Name == "Table" {
+			assert.Equal(t, map[string]string{"Env": "prod"}, r.Tags)
+		}
+	}
+}
+
+func TestLoadParametersFileConfigFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "params.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"Parameters": {"Env": "staging"}}`), 0600))
+
+	params, err := loadParametersFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Env": "staging"}, params)
+}
+
+func TestLoadResourcesWithoutCompareTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "template.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+Resources:
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      Size: 100
+`), 0600))
+
+	p := &TemplateProvider{
+		ctx:    config.EmptyProjectContext(),
+		Path:   path,
+		Region: "eu-west-2",
+	}
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	require.NoError(t, p.LoadResources(project, map[string]*schema.UsageData{}))
+
+	assert.Len(t, project.Resources, 1)
+	assert.Equal(t, project.Resources, project.PastResources)
+	assert.False(t, project.HasDiff)
+}
+
+func TestLoadResourcesCompareTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "template.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+Resources:
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      Size: 100
+      VolumeType: gp3
+`), 0600))
+
+	// The output of `aws cloudformation get-template` for a stack created from a YAML template
+	comparePath := filepath.Join(dir, "stack.json")
+	require.NoError(t, ioutil.WriteFile(comparePath, []byte(`{"TemplateBody": "Resources:\n  Volume:\n    Type: AWS::EC2::Volume\n    Properties:\n      Size: 50\n  OldVolume:\n    Type: AWS::EC2::Volume\n    Properties:\n      Size: 10\n", "StagesAvailable": ["Original", "Processed"]}`), 0600))
+
+	p := &TemplateProvider{
+		ctx:       config.EmptyProjectContext(),
+		Path:      path,
+		Region:    "eu-west-2",
+		CompareTo: comparePath,
+	}
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	require.NoError(t, p.LoadResources(project, map[string]*schema.UsageData{}))
+
+	sizes := func(resources []*schema.Resource) map[string]string {
+		m := make(map[string]string)
+		for _, r := range resources {
+			m[r.Name] = r.CostComponents[0].MonthlyQuantity.String()
+		}
+		return m
+	}
+
+	assert.Equal(t, map[string]string{"Volume": "100"}, sizes(project.Resources))
+	assert.Equal(t, map[string]string{"Volume": "50", "OldVolume": "10"}, sizes(project.PastResources))
+	assert.True(t, project.HasDiff)
+}
+
+func TestLoadResourcesCompareToParametersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-cfn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	template := []byte(`
+Parameters:
+  Size:
+    Type: Number
+    Default: 10
+Resources:
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      Size: !Ref Size
+`)
+
+	path := filepath.Join(dir, "template.yaml")
+	require.NoError(t, ioutil.WriteFile(path, template, 0600))
+	comparePath := filepath.Join(dir, "previous.yaml")
+	require.NoError(t, ioutil.WriteFile(comparePath, template, 0600))
+
+	paramsPath := filepath.Join(dir, "params.json")
+	require.NoError(t, ioutil.WriteFile(paramsPath, []byte(`[{"ParameterKey": "Size", "ParameterValue": "100"}]`), 0600))
+	compareParamsPath := filepath.Join(dir, "previous-params.json")
+	require.NoError(t, ioutil.WriteFile(compareParamsPath, []byte(`[{"ParameterKey": "Size", "ParameterValue": "50"}]`), 0600))
+
+	volumeSize := func(resources []*schema.Resource) string {
+		require.Len(t, resources, 1)
+		return resources[0].CostComponents[0].MonthlyQuantity.String()
+	}
+
+	p := &TemplateProvider{
+		ctx:            config.EmptyProjectContext(),
+		Path:           path,
+		Region:         "eu-west-2",
+		ParametersFile: paramsPath,
+		CompareTo:      comparePath,
+	}
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	require.NoError(t, p.LoadResources(project, map[string]*schema.UsageData{}))
+	assert.Equal(t, "100", volumeSize(project.Resources))
+	assert.Equal(t, "100", volumeSize(project.PastResources))
+
+	p.CompareToParametersFile = compareParamsPath
+
+	project = schema.NewProject("test", &schema.ProjectMetadata{})
+	require.NoError(t, p.LoadResources(project, map[string]*schema.UsageData{}))
+	assert.Equal(t, "100", volumeSize(project.Resources))
+	assert.Equal(t, "50", volumeSize(project.PastResources))
+}
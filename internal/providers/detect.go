@@ -39,6 +39,10 @@ func Detect(ctx *config.ProjectContext) (schema.Provider, error) {
 	}
 
 	if isTerraformDir(path) {
+		if ctx.ProjectConfig.TerraformParseHCL {
+			return terraform.NewHCLProvider(ctx), nil
+		}
+
 		return terraform.NewDirProvider(ctx), nil
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/infracost/infracost/internal/resources/aws"
 	"github.com/infracost/infracost/internal/schema"
 	log "github.com/sirupsen/logrus"
 
@@ -160,7 +161,7 @@ func newLaunchTemplate(name string, d *schema.ResourceData, u *schema.UsageData,
 	}
 
 	if d.Get("instance_type").Exists() && d.Get("instance_type").Type != gjson.Null {
-		if isInstanceBurstable(d.Get("instance_type").String(), []string{"t2.", "t3.", "t4."}) {
+		if aws.IsInstanceBurstable(d.Get("instance_type").String(), []string{"t2.", "t3.", "t4."}) {
 			c := newCPUCredit(d, u)
 			if c != nil {
 				costComponents = append(costComponents, c)
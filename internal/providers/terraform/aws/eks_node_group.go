@@ -4,6 +4,7 @@ import (
 	"github.com/tidwall/gjson"
 	"strings"
 
+	"github.com/infracost/infracost/internal/resources/aws"
 	"github.com/infracost/infracost/internal/schema"
 	"github.com/shopspring/decimal"
 )
@@ -51,7 +52,7 @@ func NewEKSNodeGroup(d *schema.ResourceData, u *schema.UsageData) *schema.Resour
 		costComponents = append(costComponents, computeCostComponent(d, u, purchaseOptionLabel, instanceType, "Shared", desiredSize))
 
 		var cpuCreditQuantity decimal.Decimal
-		if isInstanceBurstable(instanceType, []string{"t3", "t4"}) {
+		if aws.IsInstanceBurstable(instanceType, []string{"t3", "t4"}) {
 			instanceCPUCreditHours := decimal.Zero
 			if u != nil && u.Get("monthly_cpu_credit_hrs").Exists() {
 				instanceCPUCreditHours = decimal.NewFromInt(u.Get("monthly_cpu_credit_hrs").Int())
@@ -64,7 +65,7 @@ func NewEKSNodeGroup(d *schema.ResourceData, u *schema.UsageData) *schema.Resour
 
 			cpuCreditQuantity = instanceVCPUCount.Mul(instanceCPUCreditHours).Mul(decimal.NewFromInt(desiredSize))
 			instancePrefix := strings.SplitN(instanceType, ".", 2)[0]
-			costComponents = append(costComponents, cpuCreditsCostComponent(region, cpuCreditQuantity, instancePrefix))
+			costComponents = append(costComponents, aws.CPUCreditsCostComponent(region, cpuCreditQuantity, instancePrefix))
 		}
 
 		costComponents = append(costComponents, newEksRootBlockDevice(d))
@@ -105,17 +106,19 @@ func newEksRootBlockDevice(d *schema.ResourceData) *schema.CostComponent {
 }
 
 func newEksEbsBlockDevice(name string, d *schema.ResourceData, region string) *schema.CostComponent {
-	volumeAPIName := "gp2"
-	defaultVolumeSize := 20
+	defaultVolumeSize := float64(20)
 
-	gbVal := decimal.NewFromInt(int64(defaultVolumeSize))
-	if d.Get("disk_size").Exists() {
-		gbVal = decimal.NewFromFloat(d.Get("disk_size").Float())
+	args := &aws.EBSVolumeArguments{
+		Address: name,
+		Region:  region,
+		Type:    strPtr("gp2"),
+		Size:    &defaultVolumeSize,
 	}
 
-	iopsVal := decimal.Zero
-
-	var unknown *decimal.Decimal
+	if d.Get("disk_size").Exists() {
+		size := d.Get("disk_size").Float()
+		args.Size = &size
+	}
 
-	return ebsVolumeCostComponents(region, volumeAPIName, unknown, gbVal, iopsVal, unknown)[0]
+	return aws.NewEBSVolume(args).CostComponents[0]
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/infracost/infracost/internal/clierror"
 	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/providers/terraform/hcleval"
 	"github.com/infracost/infracost/internal/schema"
 	"github.com/infracost/infracost/internal/ui"
 	"github.com/kballard/go-shellquote"
@@ -132,6 +133,11 @@ func (p *DirProvider) LoadResources(project *schema.Project, usage map[string]*s
 		return errors.Wrap(err, "Error parsing Terraform JSON")
 	}
 
+	if p.ctx.RunContext.Config.IncludeSourceLocations() {
+		hcleval.AddSourceLocations(p.Path, pastResources)
+		hcleval.AddSourceLocations(p.Path, resources)
+	}
+
 	project.HasDiff = !p.UseState
 	if project.HasDiff {
 		project.PastResources = pastResources
@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"github.com/infracost/infracost/internal/config"
+	"github.com/infracost/infracost/internal/providers/terraform/hcleval"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/infracost/infracost/internal/ui"
+	"github.com/pkg/errors"
+)
+
+// HCLProvider parses the Terraform files in a directory directly instead of running
+// terraform plan, so it doesn't need Terraform, cloud credentials or backend access.
+type HCLProvider struct {
+	ctx         *config.ProjectContext
+	Path        string
+	spinnerOpts ui.SpinnerOptions
+	PlanFlags   string
+	Workspace   string
+}
+
+func NewHCLProvider(ctx *config.ProjectContext) schema.Provider {
+	return &HCLProvider{
+		ctx:  ctx,
+		Path: ctx.ProjectConfig.Path,
+		spinnerOpts: ui.SpinnerOptions{
+			EnableLogging: ctx.RunContext.Config.IsLogging(),
+			NoColor:       ctx.RunContext.Config.NoColor,
+			Indent:        "  ",
+		},
+		PlanFlags: ctx.ProjectConfig.TerraformPlanFlags,
+		Workspace: ctx.ProjectConfig.TerraformWorkspace,
+	}
+}
+
+func (p *HCLProvider) Type() string {
+	return "terraform_hcl"
+}
+
+func (p *HCLProvider) DisplayType() string {
+	return "Terraform directory (HCL)"
+}
+
+func (p *HCLProvider) AddMetadata(metadata *schema.ProjectMetadata) {
+	metadata.TerraformWorkspace = p.Workspace
+	if metadata.TerraformWorkspace == "" {
+		metadata.TerraformWorkspace = "default"
+	}
+}
+
+func (p *HCLProvider) LoadResources(project *schema.Project, usage map[string]*schema.UsageData) error {
+	spinner := ui.NewSpinner("Parsing Terraform HCL files", p.spinnerOpts)
+
+	j, err := p.generatePlanJSON()
+	if err != nil {
+		spinner.Fail()
+		return err
+	}
+
+	parser := NewParser(p.ctx)
+	pastResources, resources, err := parser.parseJSON(j, usage)
+	if err != nil {
+		spinner.Fail()
+		return errors.Wrap(err, "Error parsing Terraform HCL files")
+	}
+
+	if p.ctx.RunContext.Config.IncludeSourceLocations() {
+		hcleval.AddSourceLocations(p.Path, resources)
+	}
+
+	project.PastResources = pastResources
+	project.Resources = resources
+
+	spinner.Success()
+	return nil
+}
+
+// generatePlanJSON evaluates the Terraform files and returns them in the plan JSON
+// format, so they can be parsed the same way as a plan.
+func (p *HCLProvider) generatePlanJSON() ([]byte, error) {
+	return hcleval.PlanJSON(p.Path, p.Workspace, p.PlanFlags)
+}
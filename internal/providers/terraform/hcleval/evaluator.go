@@ -0,0 +1,929 @@
+// Package hcleval evaluates the Terraform files in a directory without running
+// Terraform, so resources can be priced without Terraform, cloud credentials or
+// backend access.
+package hcleval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxHCLEvalPasses bounds the number of times a module is evaluated while waiting for
+// the values of locals, resources and module outputs to settle.
+const maxHCLEvalPasses = 20
+
+// hclEvaluator evaluates a Terraform directory without running Terraform. Values that
+// are only known after apply, or that need a provider to compute, are unknown and are
+// left out of the resource values.
+type hclEvaluator struct {
+	rootDir    string
+	workspace  string
+	functions  map[string]function.Function
+	moduleDirs map[string]string
+	modules    map[string]*hclModule
+}
+
+type hclModuleInstance struct {
+	evaluator    *hclEvaluator
+	module       *hclModule
+	parent       *hclModuleInstance
+	address      string
+	callPath     []string
+	providerKeys map[string]string
+	evaluated    bool
+	inputs       map[string]cty.Value
+	vars         map[string]cty.Value
+	locals       map[string]cty.Value
+	outputs      map[string]cty.Value
+	resources    map[string]*hclInstances
+	children     map[string]*hclInstances
+	childModules map[string]*hclModuleInstance
+}
+
+// hclInstances are the instances of a resource or module call after expanding count and for_each.
+type hclInstances struct {
+	expanded  bool
+	isForEach bool
+	keys      []interface{}
+	addresses []string
+	values    []cty.Value
+	modules   []*hclModuleInstance
+}
+
+type hclInstance struct {
+	key interface{}
+	ctx *hcl.EvalContext
+}
+
+// PlanJSON evaluates the Terraform files in the directory and returns them in the same
+// format as the JSON output of terraform show for a plan, so they can be parsed the same
+// way as a plan. The plan flags are used for any -var and -var-file flags.
+func PlanJSON(dir string, workspace string, planFlags string) ([]byte, error) {
+	inputs, err := loadHCLInputVariables(dir, planFlags)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	e := newHCLEvaluator(dir, workspace)
+
+	root, err := e.evaluate(inputs)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return e.planJSON(root)
+}
+
+func newHCLEvaluator(rootDir string, workspace string) *hclEvaluator {
+	if workspace == "" {
+		workspace = "default"
+	}
+
+	return &hclEvaluator{
+		rootDir:    rootDir,
+		workspace:  workspace,
+		functions:  hclFunctions(rootDir),
+		moduleDirs: loadHCLModulesManifest(rootDir),
+		modules:    make(map[string]*hclModule),
+	}
+}
+
+func (e *hclEvaluator) loadModule(dir string) (*hclModule, error) {
+	if m, ok := e.modules[dir]; ok {
+		return m, nil
+	}
+
+	m, err := loadHCLModule(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	e.modules[dir] = m
+	return m, nil
+}
+
+// evaluate evaluates the root module with the given input variables.
+func (e *hclEvaluator) evaluate(inputs map[string]cty.Value) (*hclModuleInstance, error) {
+	m, err := e.loadModule(e.rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := e.newModuleInstance(m, nil, "", []string{})
+	err = root.evaluate(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+func (e *hclEvaluator) newModuleInstance(m *hclModule, parent *hclModuleInstance, address string, callPath []string) *hclModuleInstance {
+	return &hclModuleInstance{
+		evaluator:    e,
+		module:       m,
+		parent:       parent,
+		address:      address,
+		callPath:     callPath,
+		providerKeys: make(map[string]string),
+		locals:       make(map[string]cty.Value),
+		outputs:      make(map[string]cty.Value),
+		resources:    make(map[string]*hclInstances),
+		children:     make(map[string]*hclInstances),
+		childModules: make(map[string]*hclModuleInstance),
+	}
+}
+
+// moduleDir returns the directory of the module called by call, or an empty string if the
+// module is remote and hasn't been downloaded.
+func (e *hclEvaluator) moduleDir(m *hclModuleInstance, call *hclModuleCall) string {
+	return e.moduleCallDir(m.module.dir, m.callPath, call)
+}
+
+// moduleCallDir returns the directory of a module call made from the module in
+// parentDir, which was called through the module calls in callPath.
+func (e *hclEvaluator) moduleCallDir(parentDir string, callPath []string, call *hclModuleCall) string {
+	if strings.HasPrefix(call.source, "./") || strings.HasPrefix(call.source, "../") {
+		return filepath.Join(parentDir, call.source)
+	}
+
+	key := strings.Join(append(append([]string{}, callPath...), call.name), ".")
+	return e.moduleDirs[key]
+}
+
+// evaluate evaluates the module until its values stop changing. Modules are only
+// re-evaluated if their inputs have changed since they were last evaluated.
+func (m *hclModuleInstance) evaluate(inputs map[string]cty.Value) error {
+	if m.evaluated && ctyMapsEqual(m.inputs, inputs) {
+		return nil
+	}
+
+	m.evaluated = true
+	m.inputs = inputs
+	m.evaluateVariables()
+
+	var prev cty.Value
+	for i := 0; i < maxHCLEvalPasses; i++ {
+		// The context is built once per pass and updated as values are evaluated, so later
+		// blocks in the same pass can reference them without rebuilding it for every block.
+		ctx := m.evalContext()
+
+		m.evaluateLocals(ctx)
+
+		err := m.evaluateResources(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = m.evaluateModuleCalls(ctx)
+		if err != nil {
+			return err
+		}
+
+		m.evaluateOutputs(ctx)
+
+		state := cty.ObjectVal(ctx.Variables)
+		if i > 0 && state.RawEquals(prev) {
+			break
+		}
+		prev = state
+	}
+
+	return nil
+}
+
+func (m *hclModuleInstance) evaluateVariables() {
+	m.vars = make(map[string]cty.Value)
+
+	for _, block := range m.module.variables {
+		name := block.Labels[0]
+		attrs := hclAttributes(block.Body)
+
+		v, hasInput := m.inputs[name]
+		if !hasInput || v.IsNull() {
+			if attr, ok := attrs["default"]; ok {
+				v = evalHCLExpression(attr.Expr, nil)
+			} else if !hasInput {
+				log.Debugf("No value for variable %s in %s", name, m.module.dir)
+				v = cty.DynamicVal
+			}
+		}
+
+		if attr, ok := attrs["type"]; ok {
+			ty, diags := typeexpr.TypeConstraint(attr.Expr)
+			if !diags.HasErrors() {
+				if converted, err := convert.Convert(v, ty); err == nil {
+					v = converted
+				}
+			}
+		}
+
+		m.vars[name] = v
+	}
+}
+
+func (m *hclModuleInstance) evaluateLocals(ctx *hcl.EvalContext) {
+	for _, attr := range m.module.locals {
+		m.locals[attr.Name] = evalHCLExpression(attr.Expr, ctx)
+	}
+
+	ctx.Variables["local"] = ctyObjectVal(m.locals)
+}
+
+func (m *hclModuleInstance) evaluateOutputs(ctx *hcl.EvalContext) {
+	for _, block := range m.module.outputs {
+		if attr, ok := hclAttributes(block.Body)["value"]; ok {
+			m.outputs[block.Labels[0]] = evalHCLExpression(attr.Expr, ctx)
+		}
+	}
+}
+
+func (m *hclModuleInstance) evaluateResources(ctx *hcl.EvalContext) error {
+	for _, r := range m.module.resources {
+		instances, diags := expandHCLInstances(r.key(), r.count, r.forEach, ctx)
+		if diags.HasErrors() {
+			return errors.Wrapf(diags, "Error evaluating %s%s", m.addressPrefix(), r.key())
+		}
+
+		res := &hclInstances{
+			expanded:  true,
+			isForEach: r.forEach != nil,
+		}
+
+		for _, inst := range instances {
+			addr := m.addressPrefix() + r.key() + hclIndexSuffix(inst.key)
+
+			vals := hclBodyValues(r.body, inst.ctx, hclResourceMetaArguments)
+			// The ID of a resource is only known after apply, so the address is used instead. This
+			// means resources that reference each other by ID can still be matched up.
+			if _, ok := vals["id"]; !ok {
+				vals["id"] = cty.StringVal(addr)
+			}
+
+			res.keys = append(res.keys, inst.key)
+			res.addresses = append(res.addresses, addr)
+			res.values = append(res.values, cty.ObjectVal(vals))
+		}
+
+		m.resources[r.key()] = res
+		m.setResourceValue(ctx, r)
+	}
+
+	return nil
+}
+
+func (m *hclModuleInstance) evaluateModuleCalls(ctx *hcl.EvalContext) error {
+	for _, call := range m.module.calls {
+		dir := m.evaluator.moduleDir(m, call)
+		if dir == "" {
+			if m.children[call.name] == nil {
+				log.Warnf("Skipping module %s%s: source %s is not a local path and has not been downloaded", m.addressPrefix(), call.name, call.source)
+			}
+			m.children[call.name] = &hclInstances{}
+			m.setModuleValue(ctx, call)
+			continue
+		}
+
+		mod, err := m.evaluator.loadModule(dir)
+		if err != nil {
+			return err
+		}
+
+		instances, diags := expandHCLInstances("module."+call.name, call.count, call.forEach, ctx)
+		if diags.HasErrors() {
+			return errors.Wrapf(diags, "Error evaluating %smodule.%s", m.addressPrefix(), call.name)
+		}
+
+		res := &hclInstances{
+			expanded:  true,
+			isForEach: call.forEach != nil,
+		}
+
+		for _, inst := range instances {
+			addr := m.addressPrefix() + "module." + call.name + hclIndexSuffix(inst.key)
+
+			child, ok := m.childModules[addr]
+			if !ok {
+				child = m.evaluator.newModuleInstance(mod, m, addr, append(append([]string{}, m.callPath...), call.name))
+				child.providerKeys = m.moduleProviderKeys(call)
+				m.childModules[addr] = child
+			}
+
+			err := child.evaluate(hclBodyValues(call.body, inst.ctx, hclModuleMetaArguments))
+			if err != nil {
+				return err
+			}
+
+			res.keys = append(res.keys, inst.key)
+			res.addresses = append(res.addresses, addr)
+			res.values = append(res.values, ctyObjectVal(child.outputs))
+			res.modules = append(res.modules, child)
+		}
+
+		m.children[call.name] = res
+		m.setModuleValue(ctx, call)
+	}
+
+	return nil
+}
+
+// moduleProviderKeys returns the providers passed to a module call, mapped to the
+// provider keys of the root module.
+func (m *hclModuleInstance) moduleProviderKeys(call *hclModuleCall) map[string]string {
+	keys := make(map[string]string)
+	if call.providers == nil {
+		return keys
+	}
+
+	pairs, diags := hcl.ExprMap(call.providers)
+	if diags.HasErrors() {
+		return keys
+	}
+
+	for _, pair := range pairs {
+		k := hclProviderKey(pair.Key)
+		v := hclProviderKey(pair.Value)
+
+		if k != "" && v != "" {
+			keys[k] = m.rootProviderKey(v)
+		}
+	}
+
+	return keys
+}
+
+// rootProviderKey returns the key of the root module provider configuration that is used
+// for the given provider key in this module.
+func (m *hclModuleInstance) rootProviderKey(key string) string {
+	if k, ok := m.providerKeys[key]; ok {
+		return k
+	}
+
+	if m.parent == nil {
+		return key
+	}
+
+	return m.parent.rootProviderKey(key)
+}
+
+func (m *hclModuleInstance) addressPrefix() string {
+	if m.address == "" {
+		return ""
+	}
+
+	return m.address + "."
+}
+
+func (m *hclModuleInstance) evalContext() *hcl.EvalContext {
+	cwd, _ := os.Getwd()
+
+	vars := map[string]cty.Value{
+		"var":   ctyObjectVal(m.vars),
+		"local": ctyObjectVal(m.locals),
+		"path": cty.ObjectVal(map[string]cty.Value{
+			"module": cty.StringVal(m.module.dir),
+			"root":   cty.StringVal(m.evaluator.rootDir),
+			"cwd":    cty.StringVal(cwd),
+		}),
+		"terraform": cty.ObjectVal(map[string]cty.Value{
+			"workspace": cty.StringVal(m.evaluator.workspace),
+		}),
+	}
+
+	resourceTypes := make(map[string]map[string]cty.Value)
+	dataTypes := make(map[string]map[string]cty.Value)
+
+	for _, r := range m.module.resources {
+		types := resourceTypes
+		if r.mode == "data" {
+			types = dataTypes
+		}
+
+		if _, ok := types[r.typ]; !ok {
+			types[r.typ] = make(map[string]cty.Value)
+		}
+		types[r.typ][r.name] = m.resources[r.key()].value()
+	}
+
+	for t, names := range resourceTypes {
+		vars[t] = cty.ObjectVal(names)
+	}
+
+	data := make(map[string]cty.Value, len(dataTypes))
+	for t, names := range dataTypes {
+		data[t] = cty.ObjectVal(names)
+	}
+	vars["data"] = ctyObjectVal(data)
+
+	modules := make(map[string]cty.Value, len(m.module.calls))
+	for _, call := range m.module.calls {
+		modules[call.name] = m.children[call.name].value()
+	}
+	vars["module"] = ctyObjectVal(modules)
+
+	return &hcl.EvalContext{
+		Variables: vars,
+		Functions: m.evaluator.functions,
+	}
+}
+
+// setResourceValue updates the value of a resource in a context built by evalContext.
+func (m *hclModuleInstance) setResourceValue(ctx *hcl.EvalContext, r *hclResource) {
+	v := m.resources[r.key()].value()
+
+	if r.mode == "data" {
+		data := ctyValueMap(ctx.Variables["data"])
+		data[r.typ] = ctyObjectVal(ctyWithAttr(data[r.typ], r.name, v))
+		ctx.Variables["data"] = ctyObjectVal(data)
+		return
+	}
+
+	ctx.Variables[r.typ] = ctyObjectVal(ctyWithAttr(ctx.Variables[r.typ], r.name, v))
+}
+
+// setModuleValue updates the value of a module call in a context built by evalContext.
+func (m *hclModuleInstance) setModuleValue(ctx *hcl.EvalContext, call *hclModuleCall) {
+	ctx.Variables["module"] = ctyObjectVal(ctyWithAttr(ctx.Variables["module"], call.name, m.children[call.name].value()))
+}
+
+// value returns the value used when the instances are referenced in an expression.
+func (i *hclInstances) value() cty.Value {
+	if i == nil || !i.expanded {
+		return cty.DynamicVal
+	}
+
+	if i.isForEach {
+		m := make(map[string]cty.Value, len(i.values))
+		for j, k := range i.keys {
+			if s, ok := k.(string); ok {
+				m[s] = i.values[j]
+			}
+		}
+
+		return ctyObjectVal(m)
+	}
+
+	if len(i.keys) == 1 && i.keys[0] == nil {
+		return i.values[0]
+	}
+
+	if len(i.values) == 0 {
+		return cty.EmptyTupleVal
+	}
+
+	return cty.TupleVal(i.values)
+}
+
+// expandHCLInstances returns an evaluation context for each instance of a resource or
+// module call. If the count is unknown a single instance is assumed, and if the
+// for_each is unknown there are no instances.
+func expandHCLInstances(addr string, count hcl.Expression, forEach hcl.Expression, ctx *hcl.EvalContext) ([]hclInstance, hcl.Diagnostics) {
+	if count != nil {
+		n := 1
+
+		v := evalHCLExpression(count, ctx)
+		if v, err := convert.Convert(v, cty.Number); err == nil && v.IsKnown() && !v.IsNull() {
+			i, _ := v.AsBigFloat().Int64()
+			n = int(i)
+		} else {
+			log.Debugf("Could not evaluate count for %s, assuming 1", addr)
+		}
+
+		if n < 0 {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid count argument",
+				Detail:   `The given "count" argument value is unsuitable: must be greater than or equal to zero.`,
+				Subject:  count.Range().Ptr(),
+			}}
+		}
+
+		instances := make([]hclInstance, 0, n)
+		for i := 0; i < n; i++ {
+			child := ctx.NewChild()
+			child.Variables = map[string]cty.Value{
+				"count": cty.ObjectVal(map[string]cty.Value{
+					"index": cty.NumberIntVal(int64(i)),
+				}),
+			}
+			instances = append(instances, hclInstance{i, child})
+		}
+
+		return instances, nil
+	}
+
+	if forEach != nil {
+		v := evalHCLExpression(forEach, ctx)
+		if !v.IsWhollyKnown() || v.IsNull() || !(v.CanIterateElements()) {
+			log.Debugf("Could not evaluate for_each for %s, skipping", addr)
+			return []hclInstance{}, nil
+		}
+
+		isSet := v.Type().IsSetType() || v.Type().IsListType() || v.Type().IsTupleType()
+
+		instances := make([]hclInstance, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, val := it.Element()
+			if isSet {
+				k = val
+			}
+
+			k, err := convert.Convert(k, cty.String)
+			if err != nil || k.IsNull() {
+				continue
+			}
+
+			child := ctx.NewChild()
+			child.Variables = map[string]cty.Value{
+				"each": cty.ObjectVal(map[string]cty.Value{
+					"key":   k,
+					"value": val,
+				}),
+			}
+			instances = append(instances, hclInstance{k.AsString(), child})
+		}
+
+		return instances, nil
+	}
+
+	return []hclInstance{{nil, ctx}}, nil
+}
+
+// hclBodyValues evaluates the attributes and nested blocks of a body. Nested blocks are
+// a list of objects, the same as in the plan JSON, and dynamic blocks are expanded.
+func hclBodyValues(body hcl.Body, ctx *hcl.EvalContext, skip map[string]bool) map[string]cty.Value {
+	vals := make(map[string]cty.Value)
+
+	for name, attr := range hclAttributes(body) {
+		if skip[name] {
+			continue
+		}
+
+		vals[name] = evalHCLExpression(attr.Expr, ctx)
+	}
+
+	syn, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return vals
+	}
+
+	blocks := make(map[string][]cty.Value)
+	var blockTypes []string
+
+	for _, b := range syn.Blocks {
+		var blockType string
+		var blockVals []cty.Value
+
+		switch b.Type {
+		case "lifecycle", "provisioner", "connection":
+			continue
+		case "dynamic":
+			if len(b.Labels) == 0 {
+				continue
+			}
+			blockType = b.Labels[0]
+			blockVals = hclDynamicBlockValues(b, ctx)
+		default:
+			blockType = b.Type
+			blockVals = []cty.Value{ctyObjectVal(hclBodyValues(b.Body, ctx, nil))}
+		}
+
+		if _, ok := blocks[blockType]; !ok {
+			blockTypes = append(blockTypes, blockType)
+		}
+		blocks[blockType] = append(blocks[blockType], blockVals...)
+	}
+
+	for _, t := range blockTypes {
+		if len(blocks[t]) == 0 {
+			vals[t] = cty.EmptyTupleVal
+			continue
+		}
+		vals[t] = cty.TupleVal(blocks[t])
+	}
+
+	return vals
+}
+
+func hclDynamicBlockValues(b *hclsyntax.Block, ctx *hcl.EvalContext) []cty.Value {
+	attrs := hclAttributes(b.Body)
+
+	forEach, ok := attrs["for_each"]
+	if !ok {
+		return nil
+	}
+
+	iterator := b.Labels[0]
+	if attr, ok := attrs["iterator"]; ok {
+		if k := hcl.ExprAsKeyword(attr.Expr); k != "" {
+			iterator = k
+		}
+	}
+
+	var content *hclsyntax.Block
+	for _, c := range b.Body.Blocks {
+		if c.Type == "content" {
+			content = c
+		}
+	}
+	if content == nil {
+		return nil
+	}
+
+	v := evalHCLExpression(forEach.Expr, ctx)
+	if !v.IsWhollyKnown() || v.IsNull() || !v.CanIterateElements() {
+		return nil
+	}
+
+	vals := make([]cty.Value, 0, v.LengthInt())
+	for it := v.ElementIterator(); it.Next(); {
+		k, val := it.Element()
+
+		child := ctx.NewChild()
+		child.Variables = map[string]cty.Value{
+			iterator: cty.ObjectVal(map[string]cty.Value{
+				"key":   k,
+				"value": val,
+			}),
+		}
+
+		vals = append(vals, ctyObjectVal(hclBodyValues(content.Body, child, nil)))
+	}
+
+	return vals
+}
+
+// evalHCLExpression evaluates an expression, returning an unknown value if it can't be
+// evaluated, e.g. because it references an attribute that is only known after apply.
+func evalHCLExpression(expr hcl.Expression, ctx *hcl.EvalContext) cty.Value {
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal
+	}
+
+	return v
+}
+
+func ctyObjectVal(m map[string]cty.Value) cty.Value {
+	if len(m) == 0 {
+		return cty.EmptyObjectVal
+	}
+
+	return cty.ObjectVal(m)
+}
+
+// ctyValueMap returns a copy of the attributes of an object, or an empty map if the
+// value isn't a known object.
+func ctyValueMap(v cty.Value) map[string]cty.Value {
+	m := make(map[string]cty.Value)
+	if !v.Type().IsObjectType() || !v.IsKnown() || v.IsNull() {
+		return m
+	}
+
+	for k, attr := range v.AsValueMap() {
+		m[k] = attr
+	}
+
+	return m
+}
+
+// ctyWithAttr returns the attributes of an object with the given attribute set.
+func ctyWithAttr(v cty.Value, name string, attr cty.Value) map[string]cty.Value {
+	m := ctyValueMap(v)
+	m[name] = attr
+
+	return m
+}
+
+func ctyMapsEqual(a, b map[string]cty.Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if o, ok := b[k]; !ok || !v.RawEquals(o) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ctyToJSON converts a value to the equivalent JSON value. Unknown and null values are nil
+// and are left out of objects.
+func ctyToJSON(v cty.Value) interface{} {
+	if !v.IsKnown() || v.IsNull() {
+		return nil
+	}
+
+	t := v.Type()
+
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1))
+	case t == cty.Bool:
+		return v.True()
+	case t.IsListType() || t.IsSetType() || t.IsTupleType():
+		l := make([]interface{}, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			l = append(l, ctyToJSON(ev))
+		}
+		return l
+	case t.IsMapType() || t.IsObjectType():
+		m := make(map[string]interface{})
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if j := ctyToJSON(ev); j != nil {
+				m[k.AsString()] = j
+			}
+		}
+		return m
+	}
+
+	return nil
+}
+
+// planJSON returns the evaluated module in the same format as the JSON output of
+// terraform show for a plan, with the parts of the format that the parser uses.
+func (e *hclEvaluator) planJSON(root *hclModuleInstance) ([]byte, error) {
+	vars := make(map[string]interface{})
+	for name, v := range root.vars {
+		if j := ctyToJSON(v); j != nil {
+			vars[name] = map[string]interface{}{"value": j}
+		}
+	}
+
+	plan := map[string]interface{}{
+		"format_version": "0.1",
+		"variables":      vars,
+		"planned_values": map[string]interface{}{
+			"root_module": root.plannedValues(),
+		},
+		"configuration": map[string]interface{}{
+			"provider_config": root.providerConfig(),
+			"root_module":     root.configuration(),
+		},
+	}
+
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return []byte{}, errors.Wrap(err, "Error generating plan JSON")
+	}
+
+	return b, nil
+}
+
+func (m *hclModuleInstance) plannedValues() map[string]interface{} {
+	resources := make([]interface{}, 0)
+
+	for _, r := range m.module.resources {
+		if r.mode == "data" {
+			continue
+		}
+
+		insts := m.resources[r.key()]
+		for i, addr := range insts.addresses {
+			res := map[string]interface{}{
+				"address":       addr,
+				"mode":          r.mode,
+				"type":          r.typ,
+				"name":          r.name,
+				"provider_name": m.module.providerName(r.providerKey),
+				"values":        ctyToJSON(insts.values[i]),
+			}
+			if insts.keys[i] != nil {
+				res["index"] = insts.keys[i]
+			}
+
+			resources = append(resources, res)
+		}
+	}
+
+	childModules := make([]interface{}, 0)
+	for _, call := range m.module.calls {
+		for _, child := range m.children[call.name].modules {
+			childModules = append(childModules, child.plannedValues())
+		}
+	}
+
+	vals := map[string]interface{}{
+		"resources": resources,
+	}
+	if m.address != "" {
+		vals["address"] = m.address
+	}
+	if len(childModules) > 0 {
+		vals["child_modules"] = childModules
+	}
+
+	return vals
+}
+
+func (m *hclModuleInstance) configuration() map[string]interface{} {
+	resources := make([]interface{}, 0, len(m.module.resources))
+	for _, r := range m.module.resources {
+		resources = append(resources, map[string]interface{}{
+			"address":             r.key(),
+			"mode":                r.mode,
+			"type":                r.typ,
+			"name":                r.name,
+			"provider_config_key": m.rootProviderKey(r.providerKey),
+			"expressions":         r.expressions,
+		})
+	}
+
+	calls := make(map[string]interface{})
+	for _, call := range m.module.calls {
+		children := m.children[call.name].modules
+		if len(children) == 0 {
+			continue
+		}
+
+		calls[call.name] = map[string]interface{}{
+			"source": call.source,
+			"module": children[0].configuration(),
+		}
+	}
+
+	return map[string]interface{}{
+		"resources":    resources,
+		"module_calls": calls,
+	}
+}
+
+// providerConfig returns the provider configurations of the root module. If the AWS
+// provider has no region the region from the environment is used, like the provider does.
+func (m *hclModuleInstance) providerConfig() map[string]interface{} {
+	conf := make(map[string]interface{})
+	ctx := m.evalContext()
+
+	for _, b := range m.module.providers {
+		name := b.Labels[0]
+		attrs := hclAttributes(b.Body)
+
+		key := name
+		c := map[string]interface{}{
+			"name": name,
+		}
+
+		if attr, ok := attrs["alias"]; ok {
+			if v := evalHCLExpression(attr.Expr, ctx); v.IsKnown() && v.Type() == cty.String && !v.IsNull() {
+				key = fmt.Sprintf("%s.%s", name, v.AsString())
+				c["alias"] = v.AsString()
+			}
+		}
+
+		exprs := make(map[string]interface{})
+		if attr, ok := attrs["region"]; ok {
+			if v := evalHCLExpression(attr.Expr, ctx); v.IsKnown() && v.Type() == cty.String && !v.IsNull() {
+				exprs["region"] = map[string]interface{}{"constant_value": v.AsString()}
+			}
+		}
+
+		if name == "aws" && exprs["region"] == nil {
+			if region := awsRegionFromEnv(); region != "" {
+				exprs["region"] = map[string]interface{}{"constant_value": region}
+			}
+		}
+
+		c["expressions"] = exprs
+		conf[key] = c
+	}
+
+	if _, ok := conf["aws"]; !ok {
+		if region := awsRegionFromEnv(); region != "" {
+			conf["aws"] = map[string]interface{}{
+				"name": "aws",
+				"expressions": map[string]interface{}{
+					"region": map[string]interface{}{"constant_value": region},
+				},
+			}
+		}
+	}
+
+	return conf
+}
+
+func awsRegionFromEnv() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
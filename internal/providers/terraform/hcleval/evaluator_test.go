@@ -0,0 +1,249 @@
+package hcleval
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestPlanJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-hcl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.tf": `
+			provider "aws" {
+				region = var.region
+			}
+
+			provider "aws" {
+				alias  = "west"
+				region = "us-west-2"
+			}
+
+			variable "region" {
+				default = "eu-west-1"
+			}
+
+			variable "size" {
+				default = "micro"
+			}
+
+			variable "instance_count" {
+				type = number
+			}
+
+			locals {
+				instance_type = "t3.${var.size}"
+				volume_names  = toset(["a", "b"])
+			}
+
+			resource "aws_instance" "web" {
+				count         = var.instance_count
+				ami           = "ami-12345678"
+				instance_type = local.instance_type
+
+				root_block_device {
+					volume_size = 20 * (count.index + 1)
+				}
+
+				dynamic "ebs_block_device" {
+					for_each = [10, 30]
+					content {
+						device_name = "/dev/sd${ebs_block_device.key}"
+						volume_size = ebs_block_device.value
+					}
+				}
+			}
+
+			resource "aws_ebs_volume" "volume" {
+				for_each          = local.volume_names
+				availability_zone = "eu-west-1a"
+				size              = 10
+
+				tags = {
+					Name     = each.key
+					Instance = aws_instance.web[0].id
+				}
+			}
+
+			module "app" {
+				source        = "./modules/app"
+				instance_type = module.sizes.large
+
+				providers = {
+					aws = aws.west
+				}
+			}
+
+			module "sizes" {
+				source = "./modules/sizes"
+			}
+
+			module "remote" {
+				source = "terraform-aws-modules/vpc/aws"
+			}
+		`,
+		"terraform.tfvars": `instance_count = 2`,
+		"modules/app/main.tf": `
+			variable "instance_type" {}
+
+			resource "aws_instance" "app" {
+				ami           = "ami-12345678"
+				instance_type = var.instance_type
+			}
+		`,
+		"modules/sizes/main.tf": `
+			output "large" {
+				value = "m5.large"
+			}
+		`,
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+
+	j, err := PlanJSON(dir, "", "-var size=large")
+	require.NoError(t, err)
+
+	parsed := gjson.ParseBytes(j)
+	resources := parsed.Get("planned_values.root_module.resources")
+
+	web0 := resources.Get(`#(address="aws_instance.web[0]")`)
+	assert.Equal(t, "t3.large", web0.Get("values.instance_type").String())
+	assert.Equal(t, int64(20), web0.Get("values.root_block_device.0.volume_size").Int())
+	assert.Equal(t, int64(30), web0.Get("values.ebs_block_device.1.volume_size").Int())
+	assert.Equal(t, int64(40), resources.Get(`#(address="aws_instance.web[1]").values.root_block_device.0.volume_size`).Int())
+
+	volume := resources.Get(`#(address="aws_ebs_volume.volume[\"a\"]")`)
+	assert.Equal(t, "a", volume.Get("values.tags.Name").String())
+	assert.Equal(t, "aws_instance.web[0]", volume.Get("values.tags.Instance").String())
+
+	app := parsed.Get(`planned_values.root_module.child_modules.#(address="module.app").resources.0`)
+	assert.Equal(t, "module.app.aws_instance.app", app.Get("address").String())
+	assert.Equal(t, "m5.large", app.Get("values.instance_type").String())
+
+	conf := parsed.Get("configuration")
+	assert.Equal(t, "eu-west-1", conf.Get("provider_config.aws.expressions.region.constant_value").String())
+	assert.Equal(t, "aws.west", conf.Get(`root_module.module_calls.app.module.resources.0.provider_config_key`).String())
+	assert.Equal(t, `aws_instance.web[0]`, conf.Get(`root_module.resources.#(address="aws_ebs_volume.volume").expressions.tags.references.1`).String())
+	assert.False(t, conf.Get("root_module.module_calls.remote").Exists())
+
+	addresses := make([]string, 0)
+	for _, r := range resources.Array() {
+		addresses = append(addresses, r.Get("address").String())
+	}
+	for _, r := range parsed.Get("planned_values.root_module.child_modules.#.resources|@flatten").Array() {
+		addresses = append(addresses, r.Get("address").String())
+	}
+
+	assert.ElementsMatch(t, []string{
+		"aws_instance.web[0]",
+		"aws_instance.web[1]",
+		`aws_ebs_volume.volume["a"]`,
+		`aws_ebs_volume.volume["b"]`,
+		"module.app.aws_instance.app",
+	}, addresses)
+}
+
+func TestPlanJSONNegativeCount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-hcl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+		variable "instance_count" {
+			default = -1
+		}
+
+		resource "aws_instance" "web" {
+			count         = var.instance_count
+			instance_type = "t3.micro"
+		}
+	`), 0600))
+
+	_, err = PlanJSON(dir, "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "aws_instance.web")
+	assert.Contains(t, err.Error(), "Invalid count argument")
+}
+
+func TestLoadHCLInputVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-hcl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte(`a = "tfvars"
+b = "tfvars"
+c = "tfvars"`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "prod.auto.tfvars"), []byte(`b = "auto"`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "extra.tfvars"), []byte(`d = ["x", "y"]`), 0600))
+
+	vals, err := loadHCLInputVariables(dir, `-var-file=extra.tfvars -var "c=flag" -var 'e={"k" = "v"}'`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tfvars", vals["a"].AsString())
+	assert.Equal(t, "auto", vals["b"].AsString())
+	assert.Equal(t, "flag", vals["c"].AsString())
+	assert.Equal(t, 2, vals["d"].LengthInt())
+	assert.Equal(t, "v", vals["e"].GetAttr("k").AsString())
+}
+
+func TestAddSourceLocations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "infracost-hcl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.tf": `resource "aws_instance" "web" {
+  ami = "ami-12345678"
+}
+
+module "storage" {
+  source = "./modules/storage"
+  count  = 2
+}
+`,
+		"modules/storage/main.tf": `variable "size" {
+  default = 10
+}
+
+resource "aws_ebs_volume" "data" {
+  size = var.size
+}
+`,
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+
+	resources := []*schema.Resource{
+		{Name: "aws_instance.web"},
+		{Name: "module.storage[1].aws_ebs_volume.data"},
+		{Name: "aws_instance.missing"},
+	}
+
+	AddSourceLocations(dir, resources)
+
+	assert.Equal(t, &schema.SourceLocation{Filename: "main.tf", StartLine: 1}, resources[0].SourceLocation)
+	assert.Equal(t, &schema.SourceLocation{Filename: "modules/storage/main.tf", StartLine: 5}, resources[1].SourceLocation)
+	assert.Nil(t, resources[2].SourceLocation)
+}
+
+func TestSplitAddress(t *testing.T) {
+	assert.Equal(t, []string{"module", `a["b.c"]`, "aws_instance", "d"}, splitAddress(`module.a["b.c"].aws_instance.d`))
+	assert.Equal(t, []string{"aws_instance", "web[0]"}, splitAddress("aws_instance.web[0]"))
+}
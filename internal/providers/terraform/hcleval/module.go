@@ -0,0 +1,584 @@
+package hcleval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclparse"
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var hclFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "locals"},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+	},
+}
+
+var hclTerraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+	},
+}
+
+// These are the resource and module arguments that Terraform handles itself, so they
+// are not part of the resource values or module inputs.
+var hclResourceMetaArguments = map[string]bool{
+	"count":      true,
+	"for_each":   true,
+	"provider":   true,
+	"depends_on": true,
+}
+
+var hclModuleMetaArguments = map[string]bool{
+	"count":      true,
+	"for_each":   true,
+	"providers":  true,
+	"depends_on": true,
+	"source":     true,
+	"version":    true,
+}
+
+// hclModule is a Terraform module directory parsed into its top-level blocks.
+type hclModule struct {
+	dir             string
+	variables       []*hcl.Block
+	locals          []*hcl.Attribute
+	resources       []*hclResource
+	calls           []*hclModuleCall
+	providers       []*hcl.Block
+	outputs         []*hcl.Block
+	providerSources map[string]string
+}
+
+type hclResource struct {
+	mode        string
+	typ         string
+	name        string
+	body        hcl.Body
+	count       hcl.Expression
+	forEach     hcl.Expression
+	providerKey string
+	expressions map[string]interface{}
+	declRange   hcl.Range
+}
+
+type hclModuleCall struct {
+	name      string
+	source    string
+	body      hcl.Body
+	count     hcl.Expression
+	forEach   hcl.Expression
+	providers hcl.Expression
+}
+
+// key returns the address of the resource within its module, e.g. aws_instance.web or data.aws_ami.ubuntu.
+func (r *hclResource) key() string {
+	if r.mode == "data" {
+		return fmt.Sprintf("data.%s.%s", r.typ, r.name)
+	}
+
+	return fmt.Sprintf("%s.%s", r.typ, r.name)
+}
+
+// loadHCLModule parses the Terraform files in dir. Override files are not supported
+// and are skipped.
+func loadHCLModule(dir string) (*hclModule, error) {
+	m := &hclModule{
+		dir:             dir,
+		providerSources: make(map[string]string),
+	}
+
+	var paths []string
+	for _, ext := range []string{"tf", "tf.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*.%s", ext)))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	parser := hclparse.NewParser()
+
+	for _, path := range paths {
+		base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".json"), ".tf")
+		if base == "override" || strings.HasSuffix(base, "_override") {
+			log.Debugf("Skipping Terraform override file %s", path)
+			continue
+		}
+
+		var f *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(path, ".json") {
+			f, diags = parser.ParseJSONFile(path)
+		} else {
+			f, diags = parser.ParseHCLFile(path)
+		}
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "Error parsing %s", path)
+		}
+
+		content, _, _ := f.Body.PartialContent(hclFileSchema)
+		for _, block := range content.Blocks {
+			m.addBlock(block)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *hclModule) addBlock(block *hcl.Block) {
+	switch block.Type {
+	case "terraform":
+		content, _, _ := block.Body.PartialContent(hclTerraformBlockSchema)
+		for _, b := range content.Blocks {
+			for name, attr := range hclAttributes(b.Body) {
+				v, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() || !v.IsKnown() || v.IsNull() || !v.Type().IsObjectType() || !v.Type().HasAttribute("source") {
+					continue
+				}
+
+				if source := v.GetAttr("source"); source.IsKnown() && source.Type() == cty.String && !source.IsNull() {
+					m.providerSources[name] = source.AsString()
+				}
+			}
+		}
+	case "provider":
+		m.providers = append(m.providers, block)
+	case "variable":
+		m.variables = append(m.variables, block)
+	case "locals":
+		m.locals = append(m.locals, sortedHCLAttributes(hclAttributes(block.Body))...)
+	case "output":
+		m.outputs = append(m.outputs, block)
+	case "module":
+		attrs := hclAttributes(block.Body)
+		call := &hclModuleCall{
+			name: block.Labels[0],
+			body: block.Body,
+		}
+
+		if attr, ok := attrs["source"]; ok {
+			v, diags := attr.Expr.Value(nil)
+			if !diags.HasErrors() && v.Type() == cty.String && !v.IsNull() {
+				call.source = v.AsString()
+			}
+		}
+		if attr, ok := attrs["count"]; ok {
+			call.count = attr.Expr
+		}
+		if attr, ok := attrs["for_each"]; ok {
+			call.forEach = attr.Expr
+		}
+		if attr, ok := attrs["providers"]; ok {
+			call.providers = attr.Expr
+		}
+
+		m.calls = append(m.calls, call)
+	case "resource", "data":
+		attrs := hclAttributes(block.Body)
+		r := &hclResource{
+			mode:        "managed",
+			typ:         block.Labels[0],
+			name:        block.Labels[1],
+			body:        block.Body,
+			providerKey: strings.Split(block.Labels[0], "_")[0],
+			expressions: hclBodyExpressions(block.Body, hclResourceMetaArguments),
+			declRange:   block.DefRange,
+		}
+		if block.Type == "data" {
+			r.mode = "data"
+		}
+
+		if attr, ok := attrs["count"]; ok {
+			r.count = attr.Expr
+		}
+		if attr, ok := attrs["for_each"]; ok {
+			r.forEach = attr.Expr
+		}
+		if attr, ok := attrs["provider"]; ok {
+			if k := hclProviderKey(attr.Expr); k != "" {
+				r.providerKey = k
+			}
+		}
+
+		m.resources = append(m.resources, r)
+	}
+}
+
+// providerName returns the name of the provider as it would appear in the plan JSON.
+func (m *hclModule) providerName(providerKey string) string {
+	name := strings.Split(providerKey, ".")[0]
+
+	source, ok := m.providerSources[name]
+	if !ok {
+		return name
+	}
+
+	if strings.Count(source, "/") < 2 {
+		source = "registry.terraform.io/" + source
+	}
+
+	return strings.ToLower(source)
+}
+
+// hclAttributes returns the attributes of a body, ignoring any nested blocks.
+func hclAttributes(body hcl.Body) hcl.Attributes {
+	if syn, ok := body.(*hclsyntax.Body); ok {
+		attrs := make(hcl.Attributes, len(syn.Attributes))
+		for name, attr := range syn.Attributes {
+			attrs[name] = attr.AsHCLAttribute()
+		}
+
+		return attrs
+	}
+
+	attrs, _ := body.JustAttributes()
+	return attrs
+}
+
+func sortedHCLAttributes(attrs hcl.Attributes) []*hcl.Attribute {
+	l := make([]*hcl.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		l = append(l, attr)
+	}
+
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Name < l[j].Name
+	})
+
+	return l
+}
+
+// hclProviderKey returns the provider key for a provider reference such as aws.west.
+func hclProviderKey(expr hcl.Expression) string {
+	t, diags := hcl.AbsTraversalForExpr(expr)
+	if diags.HasErrors() {
+		return ""
+	}
+
+	parts := []string{t.RootName()}
+	for _, step := range t[1:] {
+		if attr, ok := step.(hcl.TraverseAttr); ok {
+			parts = append(parts, attr.Name)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// hclBodyExpressions returns the references of the attributes in a body in the same
+// structure as the expressions in the plan JSON configuration. Nested blocks are a list
+// of objects and the content of dynamic blocks is treated as a nested block.
+func hclBodyExpressions(body hcl.Body, skip map[string]bool) map[string]interface{} {
+	exprs := make(map[string]interface{})
+
+	for name, attr := range hclAttributes(body) {
+		if skip[name] {
+			continue
+		}
+
+		if refs := hclExpressionReferences(attr.Expr); len(refs) > 0 {
+			exprs[name] = map[string]interface{}{"references": refs}
+		}
+	}
+
+	syn, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return exprs
+	}
+
+	for _, b := range syn.Blocks {
+		blockType := b.Type
+		blockBody := b.Body
+
+		if b.Type == "dynamic" && len(b.Labels) > 0 {
+			blockType = b.Labels[0]
+			blockBody = nil
+
+			for _, c := range b.Body.Blocks {
+				if c.Type == "content" {
+					blockBody = c.Body
+				}
+			}
+		}
+
+		if blockBody == nil {
+			continue
+		}
+
+		l, _ := exprs[blockType].([]interface{})
+		exprs[blockType] = append(l, hclBodyExpressions(blockBody, nil))
+	}
+
+	return exprs
+}
+
+// hclExpressionReferences returns the addresses referenced by an expression, in the
+// same format as the references in the plan JSON configuration.
+func hclExpressionReferences(expr hcl.Expression) []string {
+	refs := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, t := range expr.Variables() {
+		ref := hclTraversalReference(t)
+		if ref == "" || seen[ref] {
+			continue
+		}
+
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+func hclTraversalReference(t hcl.Traversal) string {
+	root := t.RootName()
+
+	n := 2
+	switch root {
+	case "self":
+		return ""
+	case "data":
+		n = 3
+	}
+
+	ref := root
+	parts := 1
+
+	for _, step := range t[1:] {
+		switch s := step.(type) {
+		case hcl.TraverseAttr:
+			if parts >= n {
+				return ref
+			}
+			ref += "." + s.Name
+			parts++
+		case hcl.TraverseIndex:
+			if parts == n {
+				ref += hclIndexSuffix(ctyIndexKey(s.Key))
+			}
+			return ref
+		default:
+			return ref
+		}
+	}
+
+	return ref
+}
+
+// hclIndexSuffix returns the suffix that is added to an address for a count or for_each index.
+func hclIndexSuffix(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		return fmt.Sprintf("[%d]", k)
+	case string:
+		return fmt.Sprintf("[%q]", k)
+	}
+
+	return ""
+}
+
+func ctyIndexKey(v cty.Value) interface{} {
+	if !v.IsKnown() || v.IsNull() {
+		return nil
+	}
+
+	switch v.Type() {
+	case cty.Number:
+		i, _ := v.AsBigFloat().Int64()
+		return int(i)
+	case cty.String:
+		return v.AsString()
+	}
+
+	return nil
+}
+
+// loadHCLInputVariables returns the values of the root module variables set using
+// TF_VAR_ environment variables, the tfvars files Terraform loads automatically and
+// any -var and -var-file flags, in the same order of precedence as Terraform.
+func loadHCLInputVariables(dir string, planFlags string) (map[string]cty.Value, error) {
+	vals := make(map[string]cty.Value)
+
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "TF_VAR_") {
+			continue
+		}
+
+		p := strings.SplitN(strings.TrimPrefix(env, "TF_VAR_"), "=", 2)
+		if len(p) == 2 {
+			vals[p[0]] = cty.StringVal(p[1])
+		}
+	}
+
+	var varFiles []string
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			varFiles = append(varFiles, filepath.Join(dir, name))
+		}
+	}
+
+	var autoVarFiles []string
+	for _, pattern := range []string{"*.auto.tfvars", "*.auto.tfvars.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return vals, err
+		}
+		autoVarFiles = append(autoVarFiles, matches...)
+	}
+	sort.Strings(autoVarFiles)
+	varFiles = append(varFiles, autoVarFiles...)
+
+	for _, f := range varFiles {
+		err := loadHCLVarFile(f, vals)
+		if err != nil {
+			return vals, err
+		}
+	}
+
+	flags, err := shellquote.Split(planFlags)
+	if err != nil {
+		return vals, errors.Wrap(err, "Error parsing terraform plan flags")
+	}
+
+	for i := 0; i < len(flags); i++ {
+		name := strings.TrimLeft(flags[i], "-")
+		if name == flags[i] {
+			continue
+		}
+
+		var value string
+		if p := strings.SplitN(name, "=", 2); len(p) == 2 {
+			name, value = p[0], p[1]
+		} else if i+1 < len(flags) {
+			i++
+			value = flags[i]
+		}
+
+		switch name {
+		case "var":
+			p := strings.SplitN(value, "=", 2)
+			if len(p) != 2 {
+				return vals, errors.Errorf("Invalid -var flag %s in terraform plan flags", value)
+			}
+			vals[p[0]] = parseHCLVarFlag(p[1])
+		case "var-file":
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(dir, value)
+			}
+
+			err := loadHCLVarFile(value, vals)
+			if err != nil {
+				return vals, err
+			}
+		}
+	}
+
+	return vals, nil
+}
+
+func loadHCLVarFile(path string, vals map[string]cty.Value) error {
+	parser := hclparse.NewParser()
+
+	var f *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		f, diags = parser.ParseJSONFile(path)
+	} else {
+		f, diags = parser.ParseHCLFile(path)
+	}
+	if diags.HasErrors() {
+		return errors.Wrapf(diags, "Error parsing variables file %s", path)
+	}
+
+	attrs, diags := f.Body.JustAttributes()
+	if diags.HasErrors() {
+		return errors.Wrapf(diags, "Error parsing variables file %s", path)
+	}
+
+	for name, attr := range attrs {
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return errors.Wrapf(diags, "Error parsing variable %s in %s", name, path)
+		}
+		vals[name] = v
+	}
+
+	return nil
+}
+
+// parseHCLVarFlag parses the value of a -var flag. Like Terraform, list and map values
+// are parsed as HCL and any other values are strings.
+func parseHCLVarFlag(s string) cty.Value {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+		return cty.StringVal(s)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(trimmed), "<var>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.StringVal(s)
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.StringVal(s)
+	}
+
+	return v
+}
+
+type hclModulesManifest struct {
+	Modules []struct {
+		Key string `json:"Key"`
+		Dir string `json:"Dir"`
+	} `json:"Modules"`
+}
+
+// loadHCLModulesManifest returns the directories of any modules that have already been
+// downloaded by terraform init or terraform get, keyed by the module path.
+func loadHCLModulesManifest(rootDir string) map[string]string {
+	dirs := make(map[string]string)
+
+	b, err := ioutil.ReadFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return dirs
+	}
+
+	var manifest hclModulesManifest
+	err = json.Unmarshal(b, &manifest)
+	if err != nil {
+		log.Debugf("Error parsing Terraform modules manifest: %v", err)
+		return dirs
+	}
+
+	for _, m := range manifest.Modules {
+		dir := m.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(rootDir, dir)
+		}
+		dirs[m.Key] = dir
+	}
+
+	return dirs
+}
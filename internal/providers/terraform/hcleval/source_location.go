@@ -0,0 +1,144 @@
+package hcleval
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AddSourceLocations sets the file and line of the resource block for each of the
+// resources, so findings can be shown against the code that defines them. Resources
+// in remote modules that haven't been downloaded are left without a location.
+func AddSourceLocations(dir string, resources []*schema.Resource) {
+	e := newHCLEvaluator(dir, "")
+
+	for _, r := range resources {
+		loc, err := e.resourceLocation(r.Name)
+		if err != nil {
+			log.Debugf("Could not find source location for %s: %v", r.Name, err)
+			continue
+		}
+
+		r.SourceLocation = loc
+	}
+}
+
+// resourceLocation finds the resource block for a resource address by following its
+// module calls from the root module.
+func (e *hclEvaluator) resourceLocation(addr string) (*schema.SourceLocation, error) {
+	parts := splitAddress(addr)
+	if len(parts) < 2 {
+		return nil, nil
+	}
+
+	dir := e.rootDir
+	var callPath []string
+
+	for len(parts) > 2 && parts[0] == "module" {
+		m, err := e.loadModule(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		name := removeAddressIndex(parts[1])
+
+		var call *hclModuleCall
+		for _, c := range m.calls {
+			if c.name == name {
+				call = c
+				break
+			}
+		}
+		if call == nil {
+			return nil, nil
+		}
+
+		dir = e.moduleCallDir(dir, callPath, call)
+		if dir == "" {
+			return nil, nil
+		}
+
+		callPath = append(callPath, name)
+		parts = parts[2:]
+	}
+
+	mode := "managed"
+	if parts[0] == "data" {
+		mode = "data"
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return nil, nil
+	}
+
+	m, err := e.loadModule(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, name := parts[0], removeAddressIndex(parts[1])
+
+	for _, r := range m.resources {
+		if r.mode != mode || r.typ != typ || r.name != name {
+			continue
+		}
+
+		filename := r.declRange.Filename
+		if rel, err := filepath.Rel(e.rootDir, filename); err == nil {
+			filename = rel
+		}
+
+		return &schema.SourceLocation{
+			Filename:  filepath.ToSlash(filename),
+			StartLine: r.declRange.Start.Line,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// splitAddress splits a resource address on dots, ignoring any dots in the index keys,
+// e.g. `module.a["b.c"].aws_instance.d` is split into `module`, `a["b.c"]`,
+// `aws_instance` and `d`.
+func splitAddress(addr string) []string {
+	var parts []string
+	var b strings.Builder
+	depth := 0
+	inQuotes := false
+
+	for i := 0; i < len(addr); i++ {
+		c := addr[i]
+
+		switch {
+		case c == '\\' && inQuotes && i+1 < len(addr):
+			b.WriteByte(c)
+			i++
+			c = addr[i]
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '[' && !inQuotes:
+			depth++
+		case c == ']' && !inQuotes:
+			depth--
+		case c == '.' && !inQuotes && depth == 0:
+			parts = append(parts, b.String())
+			b.Reset()
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return append(parts, b.String())
+}
+
+func removeAddressIndex(part string) string {
+	if i := strings.Index(part, "["); i >= 0 {
+		return part[:i]
+	}
+
+	return part
+}
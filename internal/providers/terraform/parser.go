@@ -37,6 +37,10 @@ var arnAttributeMap = map[string]string{
 	"aws_dms_replication_task":     "replication_task_arn",
 }
 
+// identifierAttributes are the attributes that can identify a deployed resource. The
+// ARN attribute from arnAttributeMap is also used for resources that have one.
+var identifierAttributes = []string{"id", "arn", "name", "function_name", "bucket"}
+
 type Parser struct {
 	ctx *config.ProjectContext
 }
@@ -58,6 +62,7 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 				Name:         d.Address,
 				ResourceType: d.Type,
 				Tags:         d.Tags,
+				Identifiers:  resourceIdentifiers(d),
 				IsSkipped:    true,
 				NoPrice:      true,
 				SkipMessage:  "Free resource.",
@@ -68,6 +73,10 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 		if res != nil {
 			res.ResourceType = d.Type
 			res.Tags = d.Tags
+			res.Identifiers = resourceIdentifiers(d)
+			res.Range = schema.NewResourceRange(u, func(u *schema.UsageData) *schema.Resource {
+				return p.createResource(d, u)
+			})
 			return res
 		}
 	}
@@ -76,11 +85,31 @@ func (p *Parser) createResource(d *schema.ResourceData, u *schema.UsageData) *sc
 		Name:         d.Address,
 		ResourceType: d.Type,
 		Tags:         d.Tags,
+		Identifiers:  resourceIdentifiers(d),
 		IsSkipped:    true,
 		SkipMessage:  "This resource is not currently supported",
 	}
 }
 
+// resourceIdentifiers returns the values of the resource's identifier attributes that
+// are known.
+func resourceIdentifiers(d *schema.ResourceData) []string {
+	attrs := identifierAttributes
+	if arnAttr, ok := arnAttributeMap[d.Type]; ok {
+		attrs = append([]string{arnAttr}, attrs...)
+	}
+
+	identifiers := make([]string, 0)
+	for _, attr := range attrs {
+		v := d.Get(attr)
+		if v.Type == gjson.String && v.String() != "" && !containsString(identifiers, v.String()) {
+			identifiers = append(identifiers, v.String())
+		}
+	}
+
+	return identifiers
+}
+
 func (p *Parser) parseJSONResources(parsePrior bool, baseResources []*schema.Resource, usage map[string]*schema.UsageData, parsed, providerConf, conf, vars gjson.Result) []*schema.Resource {
 	var resources []*schema.Resource
 	resources = append(resources, baseResources...)
@@ -100,18 +129,9 @@ func (p *Parser) parseJSONResources(parsePrior bool, baseResources []*schema.Res
 	p.loadInfracostProviderUsageData(usage, resData)
 	p.stripDataResources(resData)
 
+	usageIndex := schema.NewUsageIndex(usage)
 	for _, d := range resData {
-		var usageData *schema.UsageData
-
-		if ud := usage[d.Address]; ud != nil {
-			usageData = ud
-		} else if strings.HasSuffix(d.Address, "]") {
-			lastIndexOfOpenBracket := strings.LastIndex(d.Address, "[")
-
-			if arrayUsageData := usage[fmt.Sprintf("%s[*]", d.Address[:lastIndexOfOpenBracket])]; arrayUsageData != nil {
-				usageData = arrayUsageData
-			}
-		}
+		usageData := usageIndex.Find(d.Address, d.Type)
 		if r := p.createResource(d, usageData); r != nil {
 			resources = append(resources, r)
 		}
@@ -414,7 +434,7 @@ func (p *Parser) parseConfReferences(resData map[string]*schema.ResourceData, co
 
 		var refData *schema.ResourceData
 
-		m := addressModulePart(d.Address)
+		m := schema.AddressModulePart(d.Address)
 		refAddr := fmt.Sprintf("%s%s", m, ref)
 
 		// see if there's a resource that's an exact match on the address
@@ -507,29 +527,9 @@ func addressResourcePart(addr string) string {
 	return strings.Join(p[len(p)-2:], ".")
 }
 
-// addressModulePart parses a resource addr and returns module prefix.
-// For example: `module.name1.module.name2.resource` will return `module.name1.module.name2.`.
-func addressModulePart(addr string) string {
-	ap := strings.Split(addr, ".")
-
-	var mp []string
-
-	if len(ap) >= 3 && ap[len(ap)-3] == "data" {
-		mp = ap[:len(ap)-3]
-	} else {
-		mp = ap[:len(ap)-2]
-	}
-
-	if len(mp) == 0 {
-		return ""
-	}
-
-	return fmt.Sprintf("%s.", strings.Join(mp, "."))
-}
-
 func getModuleNames(addr string) []string {
 	r := regexp.MustCompile(`module\.([^\.\[]*)`)
-	matches := r.FindAllStringSubmatch(addressModulePart(addr), -1)
+	matches := r.FindAllStringSubmatch(schema.AddressModulePart(addr), -1)
 
 	if matches == nil {
 		return []string{}
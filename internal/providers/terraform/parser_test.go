@@ -355,6 +355,20 @@ func TestCreateResource(t *testing.T) {
 	}
 }
 
+func TestResourceIdentifiers(t *testing.T) {
+	d := schema.NewResourceData("aws_lambda_function", "aws", "aws_lambda_function.api", nil, gjson.Parse(`{
+		"function_name": "api",
+		"arn": "arn:aws:lambda:us-east-1:123456789012:function:api",
+		"id": "api"
+	}`))
+	assert.Equal(t, []string{"api", "arn:aws:lambda:us-east-1:123456789012:function:api"}, resourceIdentifiers(d))
+
+	d = schema.NewResourceData("aws_dms_endpoint", "aws", "aws_dms_endpoint.endpoint", nil, gjson.Parse(`{
+		"endpoint_arn": "arn:aws:dms:us-east-1:123456789012:endpoint:abc"
+	}`))
+	assert.Equal(t, []string{"arn:aws:dms:us-east-1:123456789012:endpoint:abc"}, resourceIdentifiers(d))
+}
+
 func TestParseResourceData(t *testing.T) {
 	providerConf := gjson.Result{
 		Type: gjson.JSON,
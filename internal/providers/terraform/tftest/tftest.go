@@ -190,7 +190,7 @@ func GoldenFileResourceTests(t *testing.T, testName string) {
 	usageFilePath := filepath.Join("testdata", testName, testName+".usage.yml")
 	if _, err := os.Stat(usageFilePath); err == nil || !os.IsNotExist(err) {
 		// usage file exists, load the data
-		usageData, err = usage.LoadFromFile(usageFilePath, false)
+		usageData, err = usage.LoadFromFile(usageFilePath, "", false)
 		require.NoError(t, err)
 	}
 
@@ -198,7 +198,7 @@ func GoldenFileResourceTests(t *testing.T, testName string) {
 	project, err := RunCostCalculations(t, cfg, tfProject, usageData)
 	require.NoError(t, err)
 
-	r := output.ToOutputFormat([]*schema.Project{project})
+	r := output.ToOutputFormat([]*schema.Project{project}, cfg.Currency)
 
 	opts := output.Options{
 		ShowSkipped: true,
@@ -252,7 +252,7 @@ func RunCostCalculations(t *testing.T, cfg *config.Config, tfProject TerraformPr
 	if err != nil {
 		return project, err
 	}
-	err = prices.PopulatePrices(cfg, project)
+	err = prices.PopulatePrices(cfg, []*schema.Project{project})
 	if err != nil {
 		return project, err
 	}
@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+type DBInstanceArguments struct {
+	Address          string   `json:"address,omitempty"`
+	Region           string   `json:"region,omitempty"`
+	InstanceClass    string   `json:"instanceClass,omitempty"`
+	Engine           string   `json:"engine,omitempty"`
+	LicenseModel     string   `json:"licenseModel,omitempty"`
+	MultiAZ          bool     `json:"multiAZ,omitempty"`
+	StorageType      *string  `json:"storageType,omitempty"`
+	AllocatedStorage *float64 `json:"allocatedStorage,omitempty"`
+	IOPS             *float64 `json:"iops,omitempty"`
+}
+
+func NewDBInstance(args *DBInstanceArguments) *schema.Resource {
+	deploymentOption := "Single-AZ"
+	if args.MultiAZ {
+		deploymentOption = "Multi-AZ"
+	}
+
+	engine := strings.ToLower(args.Engine)
+
+	var databaseEngine *string
+	switch engine {
+	case "postgres":
+		databaseEngine = strPtr("PostgreSQL")
+	case "mysql":
+		databaseEngine = strPtr("MySQL")
+	case "mariadb":
+		databaseEngine = strPtr("MariaDB")
+	case "aurora", "aurora-mysql":
+		databaseEngine = strPtr("Aurora MySQL")
+	case "aurora-postgresql":
+		databaseEngine = strPtr("Aurora PostgreSQL")
+	case "oracle-se", "oracle-se1", "oracle-se2", "oracle-ee":
+		databaseEngine = strPtr("Oracle")
+	case "sqlserver-ex", "sqlserver-web", "sqlserver-se", "sqlserver-ee":
+		databaseEngine = strPtr("SQL Server")
+	}
+
+	var databaseEdition *string
+	switch engine {
+	case "oracle-se", "sqlserver-se":
+		databaseEdition = strPtr("Standard")
+	case "oracle-se1":
+		databaseEdition = strPtr("Standard One")
+	case "oracle-se2":
+		databaseEdition = strPtr("Standard Two")
+	case "oracle-ee", "sqlserver-ee":
+		databaseEdition = strPtr("Enterprise")
+	case "sqlserver-ex":
+		databaseEdition = strPtr("Express")
+	case "sqlserver-web":
+		databaseEdition = strPtr("Web")
+	}
+
+	var licenseModel *string
+	if engine == "oracle-se1" || engine == "oracle-se2" || strings.HasPrefix(engine, "sqlserver-") {
+		licenseModel = strPtr("License included")
+	}
+	if strings.ToLower(args.LicenseModel) == "bring-your-own-license" {
+		licenseModel = strPtr("Bring your own license")
+	}
+
+	volumeType := "General Purpose"
+	if args.StorageType != nil {
+		if args.IOPS != nil {
+			volumeType = "Provisioned IOPS"
+		} else if strings.ToLower(*args.StorageType) == "standard" {
+			volumeType = "Magnetic"
+		} else if strings.ToLower(*args.StorageType) == "io1" {
+			volumeType = "Provisioned IOPS"
+		}
+	}
+
+	allocatedStorageVal := decimal.Zero
+	if args.AllocatedStorage != nil {
+		allocatedStorageVal = decimal.NewFromFloat(*args.AllocatedStorage)
+	}
+
+	iopsVal := decimal.Zero
+	if args.IOPS != nil {
+		iopsVal = decimal.NewFromFloat(*args.IOPS)
+	}
+
+	instanceAttributeFilters := []*schema.AttributeFilter{
+		{Key: "instanceType", Value: strPtr(args.InstanceClass)},
+		{Key: "deploymentOption", Value: strPtr(deploymentOption)},
+		{Key: "databaseEngine", Value: databaseEngine},
+	}
+	if databaseEdition != nil {
+		instanceAttributeFilters = append(instanceAttributeFilters, &schema.AttributeFilter{
+			Key:   "databaseEdition",
+			Value: databaseEdition,
+		})
+	}
+	if licenseModel != nil {
+		instanceAttributeFilters = append(instanceAttributeFilters, &schema.AttributeFilter{
+			Key:   "licenseModel",
+			Value: licenseModel,
+		})
+	}
+
+	costComponents := []*schema.CostComponent{
+		{
+			Name:           "Database instance",
+			Unit:           "hours",
+			UnitMultiplier: 1,
+			HourlyQuantity: decimalPtr(decimal.NewFromInt(1)),
+			ProductFilter: &schema.ProductFilter{
+				VendorName:       strPtr("aws"),
+				Region:           strPtr(args.Region),
+				Service:          strPtr("AmazonRDS"),
+				ProductFamily:    strPtr("Database Instance"),
+				AttributeFilters: instanceAttributeFilters,
+			},
+			PriceFilter: &schema.PriceFilter{
+				PurchaseOption: strPtr("on_demand"),
+			},
+		},
+		{
+			Name:            "Database storage",
+			Unit:            "GB",
+			UnitMultiplier:  1,
+			MonthlyQuantity: &allocatedStorageVal,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(args.Region),
+				Service:       strPtr("AmazonRDS"),
+				ProductFamily: strPtr("Database Storage"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "volumeType", Value: strPtr(volumeType)},
+					{Key: "deploymentOption", Value: strPtr(deploymentOption)},
+				},
+			},
+		},
+	}
+
+	if strings.ToLower(volumeType) == "provisioned iops" {
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:            "Database storage IOPS",
+			Unit:            "IOPS",
+			UnitMultiplier:  1,
+			MonthlyQuantity: &iopsVal,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(args.Region),
+				Service:       strPtr("AmazonRDS"),
+				ProductFamily: strPtr("Provisioned IOPS"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "deploymentOption", Value: strPtr(deploymentOption)},
+				},
+			},
+		})
+	}
+
+	return &schema.Resource{
+		Name:           args.Address,
+		CostComponents: costComponents,
+	}
+}
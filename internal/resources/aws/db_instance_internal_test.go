@@ -0,0 +1,40 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBInstanceStorageType(t *testing.T) {
+	t.Parallel()
+
+	empty := ""
+	io1 := "io1"
+	standard := "standard"
+	iops := float64(1000)
+
+	tests := []struct {
+		name        string
+		storageType *string
+		iops        *float64
+		expected    string
+	}{
+		{"unset", nil, nil, "General Purpose"},
+		{"unset with iops", nil, &iops, "General Purpose"},
+		{"empty with iops", &empty, &iops, "Provisioned IOPS"},
+		{"io1", &io1, nil, "Provisioned IOPS"},
+		{"standard", &standard, nil, "Magnetic"},
+	}
+
+	for _, test := range tests {
+		r := NewDBInstance(&DBInstanceArguments{
+			Region:      "us-east-1",
+			Engine:      "postgres",
+			StorageType: test.storageType,
+			IOPS:        test.iops,
+		})
+
+		assert.Equal(t, test.expected, *r.CostComponents[1].ProductFilter.AttributeFilters[0].Value, test.name)
+	}
+}
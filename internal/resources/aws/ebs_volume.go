@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+var defaultVolumeSize = 8
+
+type EBSVolumeArguments struct {
+	Address    string   `json:"address,omitempty"`
+	Region     string   `json:"region,omitempty"`
+	Type       *string  `json:"type,omitempty"`
+	Size       *float64 `json:"size,omitempty"`
+	IOPS       float64  `json:"iops,omitempty"`
+	Throughput *int64   `json:"throughput,omitempty"`
+
+	MonthlyStandardIORequests *int64 `json:"monthlyStandardIORequests,omitempty"`
+}
+
+func (args *EBSVolumeArguments) PopulateUsage(u *schema.UsageData) {
+	if u != nil {
+		args.MonthlyStandardIORequests = u.GetInt("monthly_standard_io_requests")
+	}
+}
+
+func NewEBSVolume(args *EBSVolumeArguments) *schema.Resource {
+	volumeAPIName := "gp2"
+	if args.Type != nil {
+		volumeAPIName = *args.Type
+	}
+
+	gbVal := decimal.NewFromInt(int64(defaultVolumeSize))
+	if args.Size != nil {
+		gbVal = decimal.NewFromFloat(*args.Size)
+	}
+
+	iopsVal := decimal.NewFromFloat(args.IOPS)
+
+	var throughputVal *decimal.Decimal
+	if args.Throughput != nil {
+		throughputVal = decimalPtr(decimal.NewFromInt(*args.Throughput))
+	}
+
+	return &schema.Resource{
+		Name:           args.Address,
+		CostComponents: ebsVolumeCostComponents(args.Region, volumeAPIName, throughputVal, gbVal, iopsVal, intPtrToDecimalPtr(args.MonthlyStandardIORequests)),
+	}
+}
+
+func ebsVolumeCostComponents(region string, volumeAPIName string, throughputVal *decimal.Decimal, gbVal decimal.Decimal, iopsVal decimal.Decimal, ioRequests *decimal.Decimal) []*schema.CostComponent {
+	if volumeAPIName == "" {
+		volumeAPIName = "gp2"
+	}
+
+	var name, usageType string
+	switch strings.ToLower(volumeAPIName) {
+	case "standard":
+		name = "Storage (magnetic)"
+		usageType = "EBS:VolumeIOUsage"
+	case "io1":
+		name = "Storage (provisioned IOPS SSD, io1)"
+		usageType = "EBS:VolumeP-IOPS.piops"
+	case "io2":
+		name = "Storage (provisioned IOPS SSD, io2)"
+		usageType = "EBS:VolumeP-IOPS.io2$"
+	case "st1":
+		name = "Storage (throughput optimized HDD, st1)"
+	case "sc1":
+		name = "Storage (cold HDD, sc1)"
+	case "gp3":
+		name = "Storage (general purpose SSD, gp3)"
+	default:
+		name = "Storage (general purpose SSD, gp2)"
+	}
+
+	costComponents := []*schema.CostComponent{
+		{
+			Name:            name,
+			Unit:            "GB",
+			UnitMultiplier:  1,
+			MonthlyQuantity: &gbVal,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(region),
+				Service:       strPtr("AmazonEC2"),
+				ProductFamily: strPtr("Storage"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "volumeApiName", ValueRegex: strPtr(fmt.Sprintf("/%s/i", volumeAPIName))},
+				},
+			},
+		},
+	}
+
+	if strings.ToLower(volumeAPIName) == "io1" || strings.ToLower(volumeAPIName) == "io2" {
+		costComponents = append(costComponents, ebsProvisionedIops(region, volumeAPIName, usageType, &iopsVal))
+	}
+
+	if strings.ToLower(volumeAPIName) == "standard" {
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:            "I/O requests",
+			Unit:            "1M request",
+			UnitMultiplier:  1000000,
+			MonthlyQuantity: ioRequests,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(region),
+				Service:       strPtr("AmazonEC2"),
+				ProductFamily: strPtr("System Operation"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "volumeApiName", ValueRegex: strPtr(fmt.Sprintf("/%s/i", volumeAPIName))},
+					{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+				},
+			},
+		})
+	}
+
+	if strings.ToLower(volumeAPIName) == "gp3" {
+		if throughputVal != nil && throughputVal.GreaterThan(decimal.NewFromInt(125)) {
+			throughputVal = decimalPtr(throughputVal.Sub(decimal.NewFromInt(125)))
+			costComponents = append(costComponents, &schema.CostComponent{
+				Name:            "Provisioned throughput",
+				Unit:            "Mbps",
+				UnitMultiplier:  1,
+				MonthlyQuantity: throughputVal,
+				ProductFilter: &schema.ProductFilter{
+					VendorName:    strPtr("aws"),
+					Region:        strPtr(region),
+					Service:       strPtr("AmazonEC2"),
+					ProductFamily: strPtr("Provisioned Throughput"),
+					AttributeFilters: []*schema.AttributeFilter{
+						{Key: "volumeApiName", ValueRegex: strPtr(fmt.Sprintf("/%s/i", volumeAPIName))},
+						{Key: "usagetype", ValueRegex: strPtr("/VolumeP-Throughput.gp3/")},
+					},
+				},
+				PriceFilter: &schema.PriceFilter{
+					Unit: strPtr("MiBps-Mo"),
+				},
+			})
+		}
+
+		if iopsVal.GreaterThan(decimal.NewFromInt(3000)) {
+			iopsVal = iopsVal.Sub(decimal.NewFromInt(3000))
+			costComponents = append(costComponents, ebsProvisionedIops(region, volumeAPIName, "VolumeP-IOPS.gp3", &iopsVal))
+		}
+	}
+
+	return costComponents
+}
+
+func ebsProvisionedIops(region string, volumeAPIName string, usageType string, iopsVal *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            "Provisioned IOPS",
+		Unit:            "IOPS",
+		UnitMultiplier:  1,
+		MonthlyQuantity: iopsVal,
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(region),
+			Service:       strPtr("AmazonEC2"),
+			ProductFamily: strPtr("System Operation"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "volumeApiName", ValueRegex: strPtr(fmt.Sprintf("/%s/i", volumeAPIName))},
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+type ECSServiceArguments struct {
+	Address                        string  `json:"address,omitempty"`
+	Region                         string  `json:"region,omitempty"`
+	LaunchType                     string  `json:"launchType,omitempty"`
+	DesiredCount                   int64   `json:"desiredCount,omitempty"`
+	Memory                         string  `json:"memory,omitempty"`
+	CPU                            string  `json:"cpu,omitempty"`
+	InferenceAcceleratorDeviceType *string `json:"inferenceAcceleratorDeviceType,omitempty"`
+}
+
+// NewECSService returns the Fargate costs of an ECS service. The memory and CPU are the
+// values from the task definition, e.g. "1 vCPU" or "1024".
+func NewECSService(args *ECSServiceArguments) *schema.Resource {
+	if args.LaunchType != "FARGATE" {
+		return &schema.Resource{
+			Name:      args.Address,
+			IsSkipped: true,
+			NoPrice:   true,
+		}
+	}
+
+	desiredCount := decimal.NewFromInt(args.DesiredCount)
+	memory := convertResourceString(args.Memory)
+	cpu := convertResourceString(args.CPU)
+
+	costComponents := []*schema.CostComponent{
+		{
+			Name:           "Per GB per hour",
+			Unit:           "GB",
+			UnitMultiplier: schema.HourToMonthUnitMultiplier,
+			HourlyQuantity: decimalPtr(desiredCount.Mul(memory)),
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(args.Region),
+				Service:       strPtr("AmazonECS"),
+				ProductFamily: strPtr("Compute"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "usagetype", ValueRegex: strPtr("/Fargate-GB-Hours/")},
+				},
+			},
+		},
+		{
+			Name:           "Per vCPU per hour",
+			Unit:           "CPU",
+			UnitMultiplier: schema.HourToMonthUnitMultiplier,
+			HourlyQuantity: decimalPtr(desiredCount.Mul(cpu)),
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(args.Region),
+				Service:       strPtr("AmazonECS"),
+				ProductFamily: strPtr("Compute"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "usagetype", ValueRegex: strPtr("/Fargate-vCPU-Hours:perCPU/")},
+				},
+			},
+		},
+	}
+
+	if args.InferenceAcceleratorDeviceType != nil {
+		deviceType := *args.InferenceAcceleratorDeviceType
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:           fmt.Sprintf("Inference accelerator (%s)", deviceType),
+			Unit:           "hours",
+			UnitMultiplier: 1,
+			HourlyQuantity: decimalPtr(desiredCount),
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(args.Region),
+				Service:       strPtr("AmazonEI"),
+				ProductFamily: strPtr("Elastic Inference"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", deviceType))},
+				},
+			},
+		})
+	}
+
+	return &schema.Resource{
+		Name:           args.Address,
+		CostComponents: costComponents,
+	}
+}
+
+func convertResourceString(rawValue string) decimal.Decimal {
+	var quantity decimal.Decimal
+	noSpaceString := strings.ReplaceAll(rawValue, " ", "")
+	reg := regexp.MustCompile(`(?i)vcpu|gb`)
+	if reg.MatchString(noSpaceString) {
+		quantity, _ = decimal.NewFromString(reg.ReplaceAllString(noSpaceString, ""))
+	} else {
+		quantity, _ = decimal.NewFromString(noSpaceString)
+		quantity = quantity.Div(decimal.NewFromInt(1024))
+	}
+	return quantity
+}
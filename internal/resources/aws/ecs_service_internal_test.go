@@ -36,3 +36,21 @@ func TestConvertResourceString(t *testing.T) {
 		assert.Equal(t, test.expected.String(), actual.String())
 	}
 }
+
+func TestECSServiceInferenceAccelerator(t *testing.T) {
+	t.Parallel()
+
+	args := &ECSServiceArguments{
+		Region:     "us-east-1",
+		LaunchType: "FARGATE",
+		Memory:     "2 GB",
+		CPU:        "1 vCPU",
+	}
+	assert.Len(t, NewECSService(args).CostComponents, 2)
+
+	// The accelerator is priced even if the device type isn't known, e.g. if it comes from
+	// a variable that isn't set.
+	deviceType := ""
+	args.InferenceAcceleratorDeviceType = &deviceType
+	assert.Len(t, NewECSService(args).CostComponents, 3)
+}
@@ -0,0 +1,304 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+var defaultEC2InstanceMetricCount = 7
+
+type InstanceArguments struct {
+	Address          string                `json:"address,omitempty"`
+	Region           string                `json:"region,omitempty"`
+	Tenancy          string                `json:"tenancy,omitempty"`
+	PurchaseOption   string                `json:"purchaseOption,omitempty"`
+	InstanceType     string                `json:"instanceType,omitempty"`
+	EBSOptimized     bool                  `json:"ebsOptimized,omitempty"`
+	EnableMonitoring bool                  `json:"enableMonitoring,omitempty"`
+	CPUCredits       string                `json:"cpuCredits,omitempty"`
+	RootBlockDevice  *EBSVolumeArguments   `json:"rootBlockDevice,omitempty"`
+	EBSBlockDevices  []*EBSVolumeArguments `json:"ebsBlockDevices,omitempty"`
+
+	OperatingSystem               *string `json:"operatingSystem,omitempty"`
+	ReservedInstanceType          *string `json:"reservedInstanceType,omitempty"`
+	ReservedInstanceTerm          *string `json:"reservedInstanceTerm,omitempty"`
+	ReservedInstancePaymentOption *string `json:"reservedInstancePaymentOption,omitempty"`
+	MonthlyCPUCreditHrs           *int64  `json:"monthlyCPUCreditHrs,omitempty"`
+	VCPUCount                     *int64  `json:"vcpuCount,omitempty"`
+}
+
+func (args *InstanceArguments) PopulateUsage(u *schema.UsageData) {
+	if u != nil {
+		args.OperatingSystem = u.GetString("operating_system")
+		args.ReservedInstanceType = u.GetString("reserved_instance_type")
+		args.ReservedInstanceTerm = u.GetString("reserved_instance_term")
+		args.ReservedInstancePaymentOption = u.GetString("reserved_instance_payment_option")
+		args.MonthlyCPUCreditHrs = u.GetInt("monthly_cpu_credit_hrs")
+		args.VCPUCount = u.GetInt("vcpu_count")
+	}
+}
+
+func NewInstance(args *InstanceArguments) *schema.Resource {
+	subResources := make([]*schema.Resource, 0)
+	if args.RootBlockDevice != nil {
+		subResources = append(subResources, NewEBSVolume(args.RootBlockDevice))
+	}
+	for _, b := range args.EBSBlockDevices {
+		subResources = append(subResources, NewEBSVolume(b))
+	}
+
+	costComponents := []*schema.CostComponent{InstanceComputeCostComponent(args, 1)}
+	if args.EBSOptimized {
+		costComponents = append(costComponents, EBSOptimizedCostComponent(args.Region, args.InstanceType))
+	}
+	if args.EnableMonitoring {
+		costComponents = append(costComponents, DetailedMonitoringCostComponent(args.Region))
+	}
+
+	if IsInstanceBurstable(args.InstanceType, []string{"t2.", "t3.", "t4."}) {
+		c := InstanceCPUCreditsCostComponent(args)
+		if c != nil {
+			costComponents = append(costComponents, c)
+		}
+	}
+
+	return &schema.Resource{
+		Name:           args.Address,
+		SubResources:   subResources,
+		CostComponents: costComponents,
+	}
+}
+
+// InstanceComputeCostComponent returns the instance usage cost component for the given
+// number of instances, using the reserved instance options from the usage if they are valid.
+func InstanceComputeCostComponent(args *InstanceArguments, count int64) *schema.CostComponent {
+	purchaseOption := args.PurchaseOption
+	if purchaseOption == "" {
+		purchaseOption = "on_demand"
+	}
+
+	purchaseOptionLabel := map[string]string{
+		"on_demand": "on-demand",
+		"spot":      "spot",
+	}[purchaseOption]
+
+	osLabel := "Linux/UNIX"
+	operatingSystem := "Linux"
+
+	// Allow the operating system to be specified in the usage data until we can support it from the AMI directly.
+	if args.OperatingSystem != nil {
+		os := strings.ToLower(*args.OperatingSystem)
+		switch os {
+		case "windows":
+			osLabel = "Windows"
+			operatingSystem = "Windows"
+		case "rhel":
+			osLabel = "RHEL"
+			operatingSystem = "RHEL"
+		case "suse":
+			osLabel = "SUSE"
+			operatingSystem = "SUSE"
+		default:
+			if os != "linux" {
+				log.Warnf("Unrecognized operating system %s, defaulting to Linux/UNIX", os)
+			}
+		}
+	}
+
+	if args.ReservedInstanceType != nil && args.ReservedInstanceTerm != nil && args.ReservedInstancePaymentOption != nil {
+		reservedType := *args.ReservedInstanceType
+		reservedTerm := *args.ReservedInstanceTerm
+		reservedPaymentOption := *args.ReservedInstancePaymentOption
+
+		valid, err := validateReserveInstanceParams(reservedType, reservedTerm, reservedPaymentOption)
+		if err != "" {
+			log.Warnf(err)
+		}
+		if valid {
+			purchaseOptionLabel = "reserved"
+			return reservedInstanceCostComponent(args.Region, osLabel, purchaseOptionLabel, reservedType, reservedTerm, reservedPaymentOption, args.Tenancy, args.InstanceType, operatingSystem, 1)
+		}
+	}
+
+	return &schema.CostComponent{
+		Name:           fmt.Sprintf("Instance usage (%s, %s, %s)", osLabel, purchaseOptionLabel, args.InstanceType),
+		Unit:           "hours",
+		UnitMultiplier: 1,
+		HourlyQuantity: decimalPtr(decimal.NewFromInt(count)),
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(args.Region),
+			Service:       strPtr("AmazonEC2"),
+			ProductFamily: strPtr("Compute Instance"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "instanceType", Value: strPtr(args.InstanceType)},
+				{Key: "tenancy", Value: strPtr(args.Tenancy)},
+				{Key: "operatingSystem", Value: strPtr(operatingSystem)},
+				{Key: "preInstalledSw", Value: strPtr("NA")},
+				{Key: "licenseModel", Value: strPtr("No License required")},
+				{Key: "capacitystatus", Value: strPtr("Used")},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			PurchaseOption: &purchaseOption,
+		},
+	}
+}
+
+func validateReserveInstanceParams(typeName, term, option string) (bool, string) {
+	validTypes := []string{"convertible", "standard"}
+	if !stringInSlice(validTypes, typeName) {
+		return false, fmt.Sprintf("Invalid reserved_instance_type, ignoring reserved options. Expected: convertible, standard. Got: %s", typeName)
+	}
+
+	validTerms := []string{"1_year", "3_year"}
+	if !stringInSlice(validTerms, term) {
+		return false, fmt.Sprintf("Invalid reserved_instance_term, ignoring reserved options. Expected: 1_year, 3_year. Got: %s", term)
+	}
+
+	validOptions := []string{"no_upfront", "partial_upfront", "all_upfront"}
+	if !stringInSlice(validOptions, option) {
+		return false, fmt.Sprintf("Invalid reserved_instance_payment_option, ignoring reserved options. Expected: no_upfront, partial_upfront, all_upfront. Got: %s", option)
+	}
+
+	return true, ""
+}
+
+func reservedInstanceCostComponent(region, osLabel, purchaseOptionLabel, reservedType, reservedTerm, reservedPaymentOption, tenancy, instanceType, operatingSystem string, count int64) *schema.CostComponent {
+	reservedTermName := map[string]string{
+		"1_year": "1yr",
+		"3_year": "3yr",
+	}[reservedTerm]
+
+	reservedPaymentOptionName := map[string]string{
+		"no_upfront":      "No Upfront",
+		"partial_upfront": "Partial Upfront",
+		"all_upfront":     "All Upfront",
+	}[reservedPaymentOption]
+
+	return &schema.CostComponent{
+		Name:           fmt.Sprintf("Instance usage (%s, %s, %s)", osLabel, purchaseOptionLabel, instanceType),
+		Unit:           "hours",
+		UnitMultiplier: 1,
+		HourlyQuantity: decimalPtr(decimal.NewFromInt(count)),
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(region),
+			Service:       strPtr("AmazonEC2"),
+			ProductFamily: strPtr("Compute Instance"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "instanceType", Value: strPtr(instanceType)},
+				{Key: "tenancy", Value: strPtr(tenancy)},
+				{Key: "operatingSystem", Value: strPtr(operatingSystem)},
+				{Key: "preInstalledSw", Value: strPtr("NA")},
+				{Key: "capacitystatus", Value: strPtr("Used")},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount:   strPtr("0"),
+			TermOfferingClass:  &reservedType,
+			TermLength:         &reservedTermName,
+			TermPurchaseOption: &reservedPaymentOptionName,
+		},
+	}
+}
+
+func EBSOptimizedCostComponent(region string, instanceType string) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:                 "EBS-optimized usage",
+		Unit:                 "hours",
+		UnitMultiplier:       1,
+		HourlyQuantity:       decimalPtr(decimal.NewFromInt(1)),
+		IgnoreIfMissingPrice: true,
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(region),
+			Service:       strPtr("AmazonEC2"),
+			ProductFamily: strPtr("Compute Instance"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "instanceType", Value: strPtr(instanceType)},
+				{Key: "usagetype", ValueRegex: strPtr("/EBSOptimized/")},
+			},
+		},
+	}
+}
+
+func DetailedMonitoringCostComponent(region string) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:                 "EC2 detailed monitoring",
+		Unit:                 "metrics",
+		UnitMultiplier:       1,
+		MonthlyQuantity:      decimalPtr(decimal.NewFromInt(int64(defaultEC2InstanceMetricCount))),
+		IgnoreIfMissingPrice: true,
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(region),
+			Service:       strPtr("AmazonCloudWatch"),
+			ProductFamily: strPtr("Metric"),
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount: strPtr("0"),
+		},
+	}
+}
+
+func CPUCreditsCostComponent(region string, vCPUCount decimal.Decimal, prefix string) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            "CPU credits",
+		Unit:            "vCPU-hours",
+		UnitMultiplier:  1,
+		MonthlyQuantity: &vCPUCount,
+		ProductFilter: &schema.ProductFilter{
+			VendorName:    strPtr("aws"),
+			Region:        strPtr(region),
+			Service:       strPtr("AmazonEC2"),
+			ProductFamily: strPtr("CPU Credits"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "operatingSystem", Value: strPtr("Linux")},
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/CPUCredits:%s$/", prefix))},
+			},
+		},
+	}
+}
+
+// InstanceCPUCreditsCostComponent returns the CPU credits cost component for an instance
+// in unlimited mode, or nil if the instance is in standard mode.
+func InstanceCPUCreditsCostComponent(args *InstanceArguments) *schema.CostComponent {
+	cpuCredits := args.CPUCredits
+	if cpuCredits == "" && (strings.HasPrefix(args.InstanceType, "t3.") || strings.HasPrefix(args.InstanceType, "t4g.")) {
+		cpuCredits = "unlimited"
+	}
+
+	if cpuCredits != "unlimited" {
+		return nil
+	}
+
+	prefix := strings.SplitN(args.InstanceType, ".", 2)[0]
+
+	instanceCPUCreditHours := decimal.Zero
+	if args.MonthlyCPUCreditHrs != nil {
+		instanceCPUCreditHours = decimal.NewFromInt(*args.MonthlyCPUCreditHrs)
+	}
+
+	instanceVCPUCount := decimal.Zero
+	if args.VCPUCount != nil {
+		instanceVCPUCount = decimal.NewFromInt(*args.VCPUCount)
+	}
+
+	cpuCreditQuantity := instanceVCPUCount.Mul(instanceCPUCreditHours)
+
+	return CPUCreditsCostComponent(args.Region, cpuCreditQuantity, prefix)
+}
+
+func IsInstanceBurstable(instanceType string, burstableInstanceTypes []string) bool {
+	for _, instance := range burstableInstanceTypes {
+		if strings.HasPrefix(instanceType, instance) {
+			return true
+		}
+	}
+	return false
+}
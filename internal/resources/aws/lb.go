@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+type LBArguments struct {
+	Address          string `json:"address,omitempty"`
+	Region           string `json:"region,omitempty"`
+	LoadBalancerType string `json:"loadBalancerType,omitempty"`
+
+	NewConnections    *int64 `json:"newConnections,omitempty"`
+	ActiveConnections *int64 `json:"activeConnections,omitempty"`
+	ProcessedBytesGB  *int64 `json:"processedBytesGB,omitempty"`
+	RuleEvaluations   *int64 `json:"ruleEvaluations,omitempty"`
+}
+
+func (args *LBArguments) PopulateUsage(u *schema.UsageData) {
+	if u != nil {
+		args.NewConnections = u.GetInt("new_connections")
+		args.ActiveConnections = u.GetInt("active_connections")
+		args.ProcessedBytesGB = u.GetInt("processed_bytes_gb")
+		args.RuleEvaluations = u.GetInt("rule_evaluations")
+	}
+}
+
+// NewLB returns an application or network load balancer. Any load balancer type other
+// than application is priced as a network load balancer.
+func NewLB(args *LBArguments) *schema.Resource {
+	var maxLCU *decimal.Decimal
+
+	if args.NewConnections != nil {
+		maxLCU = maxDecimalPtr(maxLCU, decimal.NewFromInt(*args.NewConnections).Div(decimal.NewFromInt(100)))
+	}
+
+	if args.ActiveConnections != nil {
+		maxLCU = maxDecimalPtr(maxLCU, decimal.NewFromInt(*args.ActiveConnections).Div(decimal.NewFromInt(3000)))
+	}
+
+	if args.ProcessedBytesGB != nil {
+		maxLCU = maxDecimalPtr(maxLCU, decimal.NewFromInt(*args.ProcessedBytesGB))
+	}
+
+	if strings.ToLower(args.LoadBalancerType) == "application" {
+		if args.RuleEvaluations != nil {
+			maxLCU = maxDecimalPtr(maxLCU, decimal.NewFromInt(*args.RuleEvaluations).Div(decimal.NewFromInt(1000)))
+		}
+
+		return newLBResource(args.Address, args.Region, "Load Balancer-Application", "Application load balancer", &decimal.Zero, maxLCU)
+	}
+
+	return newLBResource(args.Address, args.Region, "Load Balancer-Network", "Network load balancer", &decimal.Zero, maxLCU)
+}
+
+type ELBArguments struct {
+	Address string `json:"address,omitempty"`
+	Region  string `json:"region,omitempty"`
+
+	MonthlyDataProcessedGB *int64 `json:"monthlyDataProcessedGB,omitempty"`
+}
+
+func (args *ELBArguments) PopulateUsage(u *schema.UsageData) {
+	if u != nil {
+		args.MonthlyDataProcessedGB = u.GetInt("monthly_data_processed_gb")
+	}
+}
+
+// NewELB returns a classic load balancer.
+func NewELB(args *ELBArguments) *schema.Resource {
+	return newLBResource(args.Address, args.Region, "Load Balancer", "Classic load balancer", intPtrToDecimalPtr(args.MonthlyDataProcessedGB), nil)
+}
+
+func newLBResource(address string, region string, productFamily string, costComponentName string, dataProcessed *decimal.Decimal, maxLCU *decimal.Decimal) *schema.Resource {
+	costComponents := []*schema.CostComponent{
+		{
+			Name:           costComponentName,
+			Unit:           "hours",
+			HourlyQuantity: decimalPtr(decimal.NewFromInt(1)),
+			UnitMultiplier: 1,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(region),
+				Service:       strPtr("AWSELB"),
+				ProductFamily: strPtr(productFamily),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "locationType", Value: strPtr("AWS Region")},
+					{Key: "usagetype", ValueRegex: strPtr("/LoadBalancerUsage/")},
+				},
+			},
+		},
+	}
+
+	if strings.ToLower(productFamily) == "load balancer" {
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:            "Data processed",
+			Unit:            "GB",
+			UnitMultiplier:  1,
+			MonthlyQuantity: dataProcessed,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(region),
+				Service:       strPtr("AWSELB"),
+				ProductFamily: strPtr(productFamily),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "usagetype", ValueRegex: strPtr("/DataProcessing-Bytes/")},
+				},
+			},
+		})
+	}
+
+	if strings.ToLower(productFamily) == "load balancer-application" || strings.ToLower(productFamily) == "load balancer-network" {
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:            "Load balancer capacity units",
+			Unit:            "LCU",
+			UnitMultiplier:  schema.HourToMonthUnitMultiplier,
+			MonthlyQuantity: maxLCU,
+			ProductFilter: &schema.ProductFilter{
+				VendorName:    strPtr("aws"),
+				Region:        strPtr(region),
+				Service:       strPtr("AWSELB"),
+				ProductFamily: strPtr(productFamily),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "locationType", Value: strPtr("AWS Region")},
+					{Key: "usagetype", ValueRegex: strPtr("/LCUUsage/")},
+				},
+			},
+		})
+	}
+
+	return &schema.Resource{
+		Name:           address,
+		CostComponents: costComponents,
+	}
+}
+
+func maxDecimalPtr(current *decimal.Decimal, d decimal.Decimal) *decimal.Decimal {
+	if current == nil {
+		return decimalPtr(d)
+	}
+	return decimalPtr(decimal.Max(*current, d))
+}
@@ -0,0 +1,488 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/shopspring/decimal"
+)
+
+type S3BucketArguments struct {
+	Address string `json:"address,omitempty"`
+	Region  string `json:"region,omitempty"`
+	// ObjectTagsEnabled is true if any lifecycle rule filters objects by tags.
+	ObjectTagsEnabled bool `json:"objectTagsEnabled,omitempty"`
+	// LifecycleStorageClasses are the storage classes objects are transitioned to by
+	// enabled lifecycle rules, e.g. GLACIER.
+	LifecycleStorageClasses []string `json:"lifecycleStorageClasses,omitempty"`
+
+	ObjectTags               *int64                                `json:"objectTags,omitempty"`
+	Standard                 S3StandardStorageClassUsage           `json:"standard"`
+	IntelligentTiering       S3IntelligentTieringStorageClassUsage `json:"intelligentTiering"`
+	StandardInfrequentAccess S3InfrequentAccessStorageClassUsage   `json:"standardInfrequentAccess"`
+	OneZoneInfrequentAccess  S3InfrequentAccessStorageClassUsage   `json:"oneZoneInfrequentAccess"`
+	Glacier                  S3GlacierStorageClassUsage            `json:"glacier"`
+	GlacierDeepArchive       S3GlacierDeepArchiveStorageClassUsage `json:"glacierDeepArchive"`
+}
+
+type S3StandardStorageClassUsage struct {
+	StorageGB                   *int64 `json:"storageGB,omitempty"`
+	MonthlyTier1Requests        *int64 `json:"monthlyTier1Requests,omitempty"`
+	MonthlyTier2Requests        *int64 `json:"monthlyTier2Requests,omitempty"`
+	MonthlySelectDataScannedGB  *int64 `json:"monthlySelectDataScannedGB,omitempty"`
+	MonthlySelectDataReturnedGB *int64 `json:"monthlySelectDataReturnedGB,omitempty"`
+}
+
+type S3IntelligentTieringStorageClassUsage struct {
+	FrequentAccessStorageGB            *int64 `json:"frequentAccessStorageGB,omitempty"`
+	InfrequentAccessStorageGB          *int64 `json:"infrequentAccessStorageGB,omitempty"`
+	MonitoredObjects                   *int64 `json:"monitoredObjects,omitempty"`
+	MonthlyTier1Requests               *int64 `json:"monthlyTier1Requests,omitempty"`
+	MonthlyTier2Requests               *int64 `json:"monthlyTier2Requests,omitempty"`
+	MonthlyLifecycleTransitionRequests *int64 `json:"monthlyLifecycleTransitionRequests,omitempty"`
+	MonthlySelectDataScannedGB         *int64 `json:"monthlySelectDataScannedGB,omitempty"`
+	MonthlySelectDataReturnedGB        *int64 `json:"monthlySelectDataReturnedGB,omitempty"`
+	EarlyDeleteGB                      *int64 `json:"earlyDeleteGB,omitempty"`
+}
+
+// S3InfrequentAccessStorageClassUsage is the usage of the standard and one zone
+// infrequent access storage classes.
+type S3InfrequentAccessStorageClassUsage struct {
+	StorageGB                          *int64 `json:"storageGB,omitempty"`
+	MonthlyTier1Requests               *int64 `json:"monthlyTier1Requests,omitempty"`
+	MonthlyTier2Requests               *int64 `json:"monthlyTier2Requests,omitempty"`
+	MonthlyLifecycleTransitionRequests *int64 `json:"monthlyLifecycleTransitionRequests,omitempty"`
+	MonthlyRetrievalGB                 *int64 `json:"monthlyRetrievalGB,omitempty"`
+	MonthlySelectDataScannedGB         *int64 `json:"monthlySelectDataScannedGB,omitempty"`
+	MonthlySelectDataReturnedGB        *int64 `json:"monthlySelectDataReturnedGB,omitempty"`
+}
+
+type S3GlacierStorageClassUsage struct {
+	StorageGB                             *int64 `json:"storageGB,omitempty"`
+	MonthlyTier1Requests                  *int64 `json:"monthlyTier1Requests,omitempty"`
+	MonthlyTier2Requests                  *int64 `json:"monthlyTier2Requests,omitempty"`
+	MonthlyLifecycleTransitionRequests    *int64 `json:"monthlyLifecycleTransitionRequests,omitempty"`
+	EarlyDeleteGB                         *int64 `json:"earlyDeleteGB,omitempty"`
+	MonthlyStandardSelectDataScannedGB    *int64 `json:"monthlyStandardSelectDataScannedGB,omitempty"`
+	MonthlyStandardSelectDataReturnedGB   *int64 `json:"monthlyStandardSelectDataReturnedGB,omitempty"`
+	MonthlyBulkSelectDataScannedGB        *int64 `json:"monthlyBulkSelectDataScannedGB,omitempty"`
+	MonthlyBulkSelectDataReturnedGB       *int64 `json:"monthlyBulkSelectDataReturnedGB,omitempty"`
+	MonthlyExpeditedSelectDataScannedGB   *int64 `json:"monthlyExpeditedSelectDataScannedGB,omitempty"`
+	MonthlyExpeditedSelectDataReturnedGB  *int64 `json:"monthlyExpeditedSelectDataReturnedGB,omitempty"`
+	MonthlyStandardDataRetrievalGB        *int64 `json:"monthlyStandardDataRetrievalGB,omitempty"`
+	MonthlyStandardDataRetrievalRequests  *int64 `json:"monthlyStandardDataRetrievalRequests,omitempty"`
+	MonthlyBulkDataRetrievalGB            *int64 `json:"monthlyBulkDataRetrievalGB,omitempty"`
+	MonthlyBulkDataRetrievalRequests      *int64 `json:"monthlyBulkDataRetrievalRequests,omitempty"`
+	MonthlyExpeditedDataRetrievalGB       *int64 `json:"monthlyExpeditedDataRetrievalGB,omitempty"`
+	MonthlyExpeditedDataRetrievalRequests *int64 `json:"monthlyExpeditedDataRetrievalRequests,omitempty"`
+}
+
+type S3GlacierDeepArchiveStorageClassUsage struct {
+	StorageGB                            *int64 `json:"storageGB,omitempty"`
+	MonthlyTier1Requests                 *int64 `json:"monthlyTier1Requests,omitempty"`
+	MonthlyTier2Requests                 *int64 `json:"monthlyTier2Requests,omitempty"`
+	MonthlyLifecycleTransitionRequests   *int64 `json:"monthlyLifecycleTransitionRequests,omitempty"`
+	MonthlyStandardDataRetrievalGB       *int64 `json:"monthlyStandardDataRetrievalGB,omitempty"`
+	MonthlyStandardDataRetrievalRequests *int64 `json:"monthlyStandardDataRetrievalRequests,omitempty"`
+	MonthlyBulkDataRetrievalGB           *int64 `json:"monthlyBulkDataRetrievalGB,omitempty"`
+	MonthlyBulkDataRetrievalRequests     *int64 `json:"monthlyBulkDataRetrievalRequests,omitempty"`
+	EarlyDeleteGB                        *int64 `json:"earlyDeleteGB,omitempty"`
+}
+
+func (args *S3BucketArguments) PopulateUsage(u *schema.UsageData) {
+	if u == nil {
+		return
+	}
+
+	args.ObjectTags = u.GetInt("object_tags")
+
+	args.Standard = S3StandardStorageClassUsage{
+		StorageGB:                   u.GetInt("standard.storage_gb"),
+		MonthlyTier1Requests:        u.GetInt("standard.monthly_tier_1_requests"),
+		MonthlyTier2Requests:        u.GetInt("standard.monthly_tier_2_requests"),
+		MonthlySelectDataScannedGB:  u.GetInt("standard.monthly_select_data_scanned_gb"),
+		MonthlySelectDataReturnedGB: u.GetInt("standard.monthly_select_data_returned_gb"),
+	}
+
+	args.IntelligentTiering = S3IntelligentTieringStorageClassUsage{
+		FrequentAccessStorageGB:            u.GetInt("intelligent_tiering.frequent_access_storage_gb"),
+		InfrequentAccessStorageGB:          u.GetInt("intelligent_tiering.infrequent_access_storage_gb"),
+		MonitoredObjects:                   u.GetInt("intelligent_tiering.monitored_objects"),
+		MonthlyTier1Requests:               u.GetInt("intelligent_tiering.monthly_tier_1_requests"),
+		MonthlyTier2Requests:               u.GetInt("intelligent_tiering.monthly_tier_2_requests"),
+		MonthlyLifecycleTransitionRequests: u.GetInt("intelligent_tiering.monthly_lifecycle_transition_requests"),
+		MonthlySelectDataScannedGB:         u.GetInt("intelligent_tiering.monthly_select_data_scanned_gb"),
+		MonthlySelectDataReturnedGB:        u.GetInt("intelligent_tiering.monthly_select_data_returned_gb"),
+		EarlyDeleteGB:                      u.GetInt("intelligent_tiering.early_delete_gb"),
+	}
+
+	args.StandardInfrequentAccess = infrequentAccessStorageClassUsage(u, "standard_infrequent_access")
+	args.OneZoneInfrequentAccess = infrequentAccessStorageClassUsage(u, "one_zone_infrequent_access")
+
+	args.Glacier = S3GlacierStorageClassUsage{
+		StorageGB:                             u.GetInt("glacier.storage_gb"),
+		MonthlyTier1Requests:                  u.GetInt("glacier.monthly_tier_1_requests"),
+		MonthlyTier2Requests:                  u.GetInt("glacier.monthly_tier_2_requests"),
+		MonthlyLifecycleTransitionRequests:    u.GetInt("glacier.monthly_lifecycle_transition_requests"),
+		EarlyDeleteGB:                         u.GetInt("glacier.early_delete_gb"),
+		MonthlyStandardSelectDataScannedGB:    u.GetInt("glacier.monthly_standard_select_data_scanned_gb"),
+		MonthlyStandardSelectDataReturnedGB:   u.GetInt("glacier.monthly_standard_select_data_returned_gb"),
+		MonthlyBulkSelectDataScannedGB:        u.GetInt("glacier.monthly_bulk_select_data_scanned_gb"),
+		MonthlyBulkSelectDataReturnedGB:       u.GetInt("glacier.monthly_bulk_select_data_returned_gb"),
+		MonthlyExpeditedSelectDataScannedGB:   u.GetInt("glacier.monthly_expedited_select_data_scanned_gb"),
+		MonthlyExpeditedSelectDataReturnedGB:  u.GetInt("glacier.monthly_expedited_select_data_returned_gb"),
+		MonthlyStandardDataRetrievalGB:        u.GetInt("glacier.monthly_standard_data_retrieval_gb"),
+		MonthlyStandardDataRetrievalRequests:  u.GetInt("glacier.monthly_standard_data_retrieval_requests"),
+		MonthlyBulkDataRetrievalGB:            u.GetInt("glacier.monthly_bulk_data_retrieval_gb"),
+		MonthlyBulkDataRetrievalRequests:      u.GetInt("glacier.monthly_bulk_data_retrieval_requests"),
+		MonthlyExpeditedDataRetrievalGB:       u.GetInt("glacier.monthly_expedited_data_retrieval_gb"),
+		MonthlyExpeditedDataRetrievalRequests: u.GetInt("glacier.monthly_expedited_data_retrieval_requests"),
+	}
+
+	args.GlacierDeepArchive = S3GlacierDeepArchiveStorageClassUsage{
+		StorageGB:                            u.GetInt("glacier_deep_archive.storage_gb"),
+		MonthlyTier1Requests:                 u.GetInt("glacier_deep_archive.monthly_tier_1_requests"),
+		MonthlyTier2Requests:                 u.GetInt("glacier_deep_archive.monthly_tier_2_requests"),
+		MonthlyLifecycleTransitionRequests:   u.GetInt("glacier_deep_archive.monthly_lifecycle_transition_requests"),
+		MonthlyStandardDataRetrievalGB:       u.GetInt("glacier_deep_archive.monthly_standard_data_retrieval_gb"),
+		MonthlyStandardDataRetrievalRequests: u.GetInt("glacier_deep_archive.monthly_standard_data_retrieval_requests"),
+		MonthlyBulkDataRetrievalGB:           u.GetInt("glacier_deep_archive.monthly_bulk_data_retrieval_gb"),
+		MonthlyBulkDataRetrievalRequests:     u.GetInt("glacier_deep_archive.monthly_bulk_data_retrieval_requests"),
+		EarlyDeleteGB:                        u.GetInt("glacier_deep_archive.early_delete_gb"),
+	}
+}
+
+func infrequentAccessStorageClassUsage(u *schema.UsageData, prefix string) S3InfrequentAccessStorageClassUsage {
+	return S3InfrequentAccessStorageClassUsage{
+		StorageGB:                          u.GetInt(prefix + ".storage_gb"),
+		MonthlyTier1Requests:               u.GetInt(prefix + ".monthly_tier_1_requests"),
+		MonthlyTier2Requests:               u.GetInt(prefix + ".monthly_tier_2_requests"),
+		MonthlyLifecycleTransitionRequests: u.GetInt(prefix + ".monthly_lifecycle_transition_requests"),
+		MonthlyRetrievalGB:                 u.GetInt(prefix + ".monthly_retrieval_gb"),
+		MonthlySelectDataScannedGB:         u.GetInt(prefix + ".monthly_select_data_scanned_gb"),
+		MonthlySelectDataReturnedGB:        u.GetInt(prefix + ".monthly_select_data_returned_gb"),
+	}
+}
+
+func NewS3Bucket(args *S3BucketArguments) *schema.Resource {
+	return &schema.Resource{
+		Name:           args.Address,
+		SubResources:   s3SubResources(args),
+		CostComponents: s3CostComponents(args),
+	}
+}
+
+func s3CostComponents(args *S3BucketArguments) []*schema.CostComponent {
+	costComponents := make([]*schema.CostComponent, 0)
+
+	if args.ObjectTagsEnabled {
+		costComponents = append(costComponents, &schema.CostComponent{
+			Name:            "Object tagging",
+			Unit:            "10k tags",
+			UnitMultiplier:  10000,
+			MonthlyQuantity: intPtrToDecimalPtr(args.ObjectTags),
+			ProductFilter: &schema.ProductFilter{
+				VendorName: strPtr("aws"),
+				Region:     strPtr(args.Region),
+				Service:    strPtr("AmazonS3"),
+				AttributeFilters: []*schema.AttributeFilter{
+					{Key: "usagetype", ValueRegex: strPtr("/TagStorage-TagHrs/")},
+				},
+			},
+		})
+	}
+
+	return costComponents
+}
+
+func s3SubResources(args *S3BucketArguments) []*schema.Resource {
+	storageClasses := append([]string{"STANDARD"}, args.LifecycleStorageClasses...)
+
+	// Show the storage classes that have usage even if there is no lifecycle rule for them
+	if args.IntelligentTiering.FrequentAccessStorageGB != nil {
+		storageClasses = append(storageClasses, "INTELLIGENT_TIERING")
+	}
+	if args.StandardInfrequentAccess.StorageGB != nil {
+		storageClasses = append(storageClasses, "STANDARD_IA")
+	}
+	if args.OneZoneInfrequentAccess.StorageGB != nil {
+		storageClasses = append(storageClasses, "ONEZONE_IA")
+	}
+	if args.Glacier.StorageGB != nil {
+		storageClasses = append(storageClasses, "GLACIER")
+	}
+	if args.GlacierDeepArchive.StorageGB != nil {
+		storageClasses = append(storageClasses, "DEEP_ARCHIVE")
+	}
+
+	subResourceMap := make(map[string]*schema.Resource)
+	for _, storageClass := range storageClasses {
+		s := s3ResourceForStorageClass(args, storageClass)
+		if s != nil {
+			subResourceMap[s.Name] = s
+		}
+	}
+
+	subResources := make([]*schema.Resource, 0, len(subResourceMap))
+	for _, s := range subResourceMap {
+		subResources = append(subResources, s)
+	}
+
+	// Sort so we get consistent output (map iteration returns things in random order)
+	sort.Slice(subResources, func(i, j int) bool {
+		return subResources[i].Name < subResources[j].Name
+	})
+
+	return subResources
+}
+
+func s3ResourceForStorageClass(args *S3BucketArguments, storageClass string) *schema.Resource {
+	region := args.Region
+
+	switch storageClass {
+	case "STANDARD":
+		u := args.Standard
+
+		return &schema.Resource{
+			Name: "Standard",
+			CostComponents: []*schema.CostComponent{
+				s3StorageVolumeTypeCostComponent("Storage", "AmazonS3", region, "TimedStorage-ByteHrs", "Standard", intPtrToDecimalPtr(u.StorageGB)),
+				s3ApiCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3", region, "Requests-Tier1", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3DataGroupCostComponent("Select data scanned", "AmazonS3", region, "Select-Scanned-Bytes", "S3-API-Select-Scanned", intPtrToDecimalPtr(u.MonthlySelectDataScannedGB)),
+				s3DataGroupCostComponent("Select data returned", "AmazonS3", region, "Select-Returned-Bytes", "S3-API-Select-Returned", intPtrToDecimalPtr(u.MonthlySelectDataReturnedGB)),
+			},
+		}
+	case "INTELLIGENT_TIERING":
+		u := args.IntelligentTiering
+
+		return &schema.Resource{
+			Name: "Intelligent tiering",
+			CostComponents: []*schema.CostComponent{
+				s3StorageCostComponent("Storage (frequent access)", "AmazonS3", region, "TimedStorage-INT-FA-ByteHrs", intPtrToDecimalPtr(u.FrequentAccessStorageGB)),
+				s3StorageCostComponent("Storage (infrequent access)", "AmazonS3", region, "TimedStorage-INT-IA-ByteHrs", intPtrToDecimalPtr(u.InfrequentAccessStorageGB)),
+				s3MonitoringCostComponent(region, intPtrToDecimalPtr(u.MonitoredObjects)),
+				s3ApiCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3", region, "Requests-INT-Tier1", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-INT-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3LifecycleTransitionsCostComponent(region, "Requests-Tier4", "", intPtrToDecimalPtr(u.MonthlyLifecycleTransitionRequests)),
+				s3DataCostComponent("Select data scanned", "AmazonS3", region, "Select-Scanned-INT-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataScannedGB)),
+				s3DataCostComponent("Select data returned", "AmazonS3", region, "Select-Returned-INT-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataReturnedGB)),
+				s3DataCostComponent("Early delete (within 30 days)", "AmazonS3", region, "EarlyDelete-INT", intPtrToDecimalPtr(u.EarlyDeleteGB)),
+			},
+		}
+	case "STANDARD_IA":
+		u := args.StandardInfrequentAccess
+
+		return &schema.Resource{
+			Name: "Standard - infrequent access",
+			CostComponents: []*schema.CostComponent{
+				s3StorageCostComponent("Storage", "AmazonS3", region, "TimedStorage-SIA-ByteHrs", intPtrToDecimalPtr(u.StorageGB)),
+				s3ApiCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3", region, "Requests-SIA-Tier1", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-SIA-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3LifecycleTransitionsCostComponent(region, "Requests-Tier4", "", intPtrToDecimalPtr(u.MonthlyLifecycleTransitionRequests)),
+				s3DataCostComponent("Retrievals", "AmazonS3", region, "Retrieval-SIA", intPtrToDecimalPtr(u.MonthlyRetrievalGB)),
+				s3DataCostComponent("Select data scanned", "AmazonS3", region, "Select-Scanned-SIA-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataScannedGB)),
+				s3DataCostComponent("Select data returned", "AmazonS3", region, "Select-Returned-SIA-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataReturnedGB)),
+			},
+		}
+	case "ONEZONE_IA":
+		u := args.OneZoneInfrequentAccess
+
+		return &schema.Resource{
+			Name: "One zone - infrequent access",
+			CostComponents: []*schema.CostComponent{
+				s3StorageCostComponent("Storage", "AmazonS3", region, "TimedStorage-ZIA-ByteHrs", intPtrToDecimalPtr(u.StorageGB)),
+				s3ApiCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3", region, "Requests-ZIA-Tier1", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-ZIA-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3LifecycleTransitionsCostComponent(region, "Requests-Tier4", "", intPtrToDecimalPtr(u.MonthlyLifecycleTransitionRequests)),
+				s3DataCostComponent("Retrievals", "AmazonS3", region, "Retrieval-ZIA", intPtrToDecimalPtr(u.MonthlyRetrievalGB)),
+				s3DataCostComponent("Select data scanned", "AmazonS3", region, "Select-Scanned-ZIA-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataScannedGB)),
+				s3DataCostComponent("Select data returned", "AmazonS3", region, "Select-Returned-ZIA-Bytes", intPtrToDecimalPtr(u.MonthlySelectDataReturnedGB)),
+			},
+		}
+	case "GLACIER":
+		u := args.Glacier
+
+		return &schema.Resource{
+			Name: "Glacier",
+			CostComponents: []*schema.CostComponent{
+				s3StorageCostComponent("Storage", "AmazonGlacier", region, "TimedStorage-ByteHrs", intPtrToDecimalPtr(u.StorageGB)),
+				s3ApiOperationCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3", region, "Requests-GLACIER-Tier1", "PostObject", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-GLACIER-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3LifecycleTransitionsCostComponent(region, "Requests-Tier3", "S3-GlacierTransition", intPtrToDecimalPtr(u.MonthlyLifecycleTransitionRequests)),
+				s3ApiCostComponent("Retrieval requests (standard)", "AmazonGlacier", region, "Requests-Tier1", intPtrToDecimalPtr(u.MonthlyStandardDataRetrievalRequests)),
+				s3DataCostComponent("Retrievals (standard)", "AmazonGlacier", region, "Standard-Retrieval-Bytes", intPtrToDecimalPtr(u.MonthlyStandardDataRetrievalGB)),
+				s3DataCostComponent("Select data scanned (standard)", "AmazonGlacier", region, "Std-Select-Scanned-Bytes", intPtrToDecimalPtr(u.MonthlyStandardSelectDataScannedGB)),
+				s3DataCostComponent("Select data returned (standard)", "AmazonGlacier", region, "Std-Select-Returned-Bytes", intPtrToDecimalPtr(u.MonthlyStandardSelectDataReturnedGB)),
+				s3ApiCostComponent("Retrieval requests (expedited)", "AmazonGlacier", region, "Requests-Tier3", intPtrToDecimalPtr(u.MonthlyExpeditedDataRetrievalRequests)),
+				s3DataCostComponent("Retrievals (expedited)", "AmazonGlacier", region, "Expedited-Retrieval-Bytes", intPtrToDecimalPtr(u.MonthlyExpeditedDataRetrievalGB)),
+				s3DataCostComponent("Select data scanned (expedited)", "AmazonGlacier", region, "Exp-Select-Scanned-Bytes", intPtrToDecimalPtr(u.MonthlyExpeditedSelectDataScannedGB)),
+				s3DataCostComponent("Select data returned (expedited)", "AmazonGlacier", region, "Exp-Select-Returned-Bytes", intPtrToDecimalPtr(u.MonthlyExpeditedSelectDataReturnedGB)),
+				s3ApiCostComponent("Retrieval requests (bulk)", "AmazonGlacier", region, "Requests-Tier2", intPtrToDecimalPtr(u.MonthlyBulkDataRetrievalRequests)),
+				s3DataCostComponent("Retrievals (bulk)", "AmazonGlacier", region, "Bulk-Retrieval-Bytes", intPtrToDecimalPtr(u.MonthlyBulkDataRetrievalGB)),
+				s3DataCostComponent("Select data scanned (bulk)", "AmazonGlacier", region, "Bulk-Select-Scanned-Bytes", intPtrToDecimalPtr(u.MonthlyBulkSelectDataScannedGB)),
+				s3DataCostComponent("Select data returned (bulk)", "AmazonGlacier", region, "Bulk-Select-Returned-Bytes", intPtrToDecimalPtr(u.MonthlyBulkSelectDataReturnedGB)),
+				s3DataCostComponent("Early delete (within 90 days)", "AmazonGlacier", region, "EarlyDelete-ByteHrs", intPtrToDecimalPtr(u.EarlyDeleteGB)),
+			},
+		}
+	case "DEEP_ARCHIVE":
+		u := args.GlacierDeepArchive
+
+		return &schema.Resource{
+			Name: "Glacier deep archive",
+			CostComponents: []*schema.CostComponent{
+				s3StorageCostComponent("Storage", "AmazonS3GlacierDeepArchive", region, "TimedStorage-GDA-ByteHrs", intPtrToDecimalPtr(u.StorageGB)),
+				s3ApiOperationCostComponent("PUT, COPY, POST, LIST requests", "AmazonS3GlacierDeepArchive", region, "Requests-GDA-Tier1", "PostObject", intPtrToDecimalPtr(u.MonthlyTier1Requests)),
+				s3ApiCostComponent("GET, SELECT, and all other requests", "AmazonS3", region, "Requests-GDA-Tier2", intPtrToDecimalPtr(u.MonthlyTier2Requests)),
+				s3LifecycleTransitionsCostComponent(region, "Requests-Tier3", "S3-GDATransition", intPtrToDecimalPtr(u.MonthlyLifecycleTransitionRequests)),
+				s3ApiOperationCostComponent("Retrieval requests (standard)", "AmazonS3GlacierDeepArchive", region, "Requests-GDA-Tier3", "DeepArchiveRestoreObject", intPtrToDecimalPtr(u.MonthlyStandardDataRetrievalRequests)),
+				s3DataCostComponent("Retrievals (standard)", "AmazonS3GlacierDeepArchive", region, "Standard-Retrieval-Bytes", intPtrToDecimalPtr(u.MonthlyStandardDataRetrievalGB)),
+				s3ApiCostComponent("Retrieval requests (bulk)", "AmazonS3GlacierDeepArchive", region, "Requests-GDA-Tier5", intPtrToDecimalPtr(u.MonthlyBulkDataRetrievalRequests)),
+				s3DataCostComponent("Retrievals (bulk)", "AmazonS3GlacierDeepArchive", region, "Bulk-Retrieval-Bytes", intPtrToDecimalPtr(u.MonthlyBulkDataRetrievalGB)),
+				s3DataCostComponent("Early delete (within 180 days)", "AmazonS3GlacierDeepArchive", region, "EarlyDelete-GDA", intPtrToDecimalPtr(u.EarlyDeleteGB)),
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+func s3StorageCostComponent(name string, service string, region string, usageType string, dataStorage *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            name,
+		Unit:            "GB",
+		UnitMultiplier:  1,
+		MonthlyQuantity: dataStorage,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr(service),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount: strPtr("0"),
+		},
+	}
+}
+
+func s3StorageVolumeTypeCostComponent(name string, service string, region string, usageType string, volumeType string, dataStorage *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            name,
+		Unit:            "GB",
+		UnitMultiplier:  1,
+		MonthlyQuantity: dataStorage,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr(service),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+				{Key: "volumeType", ValueRegex: strPtr(fmt.Sprintf("/%s/i", volumeType))},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount: strPtr("0"),
+		},
+	}
+}
+
+func s3ApiCostComponent(name string, service string, region string, usageType string, requests *decimal.Decimal) *schema.CostComponent {
+	return s3ApiOperationCostComponent(name, service, region, usageType, "", requests)
+}
+
+func s3ApiOperationCostComponent(name string, service string, region string, usageType string, operation string, requests *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            name,
+		Unit:            "1k requests",
+		UnitMultiplier:  1000,
+		MonthlyQuantity: requests,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr(service),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+				{Key: "operation", ValueRegex: strPtr(fmt.Sprintf("/%s/i", operation))},
+			},
+		},
+	}
+}
+
+func s3DataCostComponent(name string, service string, region string, usageType string, data *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            name,
+		Unit:            "GB",
+		UnitMultiplier:  1,
+		MonthlyQuantity: data,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr(service),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount: strPtr("0"),
+		},
+	}
+}
+
+func s3DataGroupCostComponent(name string, service string, region string, usageType string, group string, data *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            name,
+		Unit:            "GB",
+		UnitMultiplier:  1,
+		MonthlyQuantity: data,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr(service),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+				{Key: "group", ValueRegex: strPtr(fmt.Sprintf("/%s/i", group))},
+			},
+		},
+		PriceFilter: &schema.PriceFilter{
+			StartUsageAmount: strPtr("0"),
+		},
+	}
+}
+
+func s3LifecycleTransitionsCostComponent(region string, usageType string, operation string, requests *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            "Lifecycle transition",
+		Unit:            "1k requests",
+		UnitMultiplier:  1000,
+		MonthlyQuantity: requests,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr("AmazonS3"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr(fmt.Sprintf("/%s/i", usageType))},
+				{Key: "operation", ValueRegex: strPtr(fmt.Sprintf("/%s/i", operation))},
+			},
+		},
+	}
+}
+
+func s3MonitoringCostComponent(region string, objects *decimal.Decimal) *schema.CostComponent {
+	return &schema.CostComponent{
+		Name:            "Monitoring and automation",
+		Unit:            "1k objects",
+		UnitMultiplier:  1000,
+		MonthlyQuantity: objects,
+		ProductFilter: &schema.ProductFilter{
+			VendorName: strPtr("aws"),
+			Region:     strPtr(region),
+			Service:    strPtr("AmazonS3"),
+			AttributeFilters: []*schema.AttributeFilter{
+				{Key: "usagetype", ValueRegex: strPtr("/Monitoring-Automation-INT/")},
+			},
+		},
+	}
+}
@@ -9,3 +9,19 @@ func strPtr(s string) *string {
 func decimalPtr(d decimal.Decimal) *decimal.Decimal {
 	return &d
 }
+
+func intPtrToDecimalPtr(i *int64) *decimal.Decimal {
+	if i == nil {
+		return nil
+	}
+	return decimalPtr(decimal.NewFromInt(*i))
+}
+
+func stringInSlice(slice []string, s string) bool {
+	for _, b := range slice {
+		if b == s {
+			return true
+		}
+	}
+	return false
+}
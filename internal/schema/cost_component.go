@@ -4,6 +4,37 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// PriceMatchStatus records problems matching a cost component's filters to a price.
+// An empty status means exactly one price was matched.
+type PriceMatchStatus string
+
+const (
+	PriceMatchOK               PriceMatchStatus = ""
+	PriceMatchNoProducts       PriceMatchStatus = "no_products"
+	PriceMatchMultipleProducts PriceMatchStatus = "multiple_products"
+	PriceMatchNoPrices         PriceMatchStatus = "no_prices"
+	PriceMatchMultiplePrices   PriceMatchStatus = "multiple_prices"
+	PriceMatchInvalidPrice     PriceMatchStatus = "invalid_price"
+)
+
+// Message returns a human readable description of the status.
+func (s PriceMatchStatus) Message() string {
+	switch s {
+	case PriceMatchNoProducts:
+		return "No products found, using 0.00"
+	case PriceMatchMultipleProducts:
+		return "Multiple products found, using the first product"
+	case PriceMatchNoPrices:
+		return "No prices found, using 0.00"
+	case PriceMatchMultiplePrices:
+		return "Multiple prices found, using the first price"
+	case PriceMatchInvalidPrice:
+		return "Invalid price, using 0.00"
+	}
+
+	return ""
+}
+
 type CostComponent struct {
 	Name                 string
 	Unit                 string
@@ -16,6 +47,7 @@ type CostComponent struct {
 	MonthlyDiscountPerc  float64
 	price                decimal.Decimal
 	priceHash            string
+	priceMatchStatus     PriceMatchStatus
 	HourlyCost           *decimal.Decimal
 	MonthlyCost          *decimal.Decimal
 }
@@ -55,6 +87,14 @@ func (c *CostComponent) PriceHash() string {
 	return c.priceHash
 }
 
+func (c *CostComponent) SetPriceMatchStatus(status PriceMatchStatus) {
+	c.priceMatchStatus = status
+}
+
+func (c *CostComponent) PriceMatchStatus() PriceMatchStatus {
+	return c.priceMatchStatus
+}
+
 func (c *CostComponent) UnitMultiplierPrice() decimal.Decimal {
 	return c.Price().Mul(decimal.NewFromInt(int64(c.UnitMultiplier)))
 }
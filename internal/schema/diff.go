@@ -79,9 +79,17 @@ func diffResourcesByKey(resourceKey string, pastResMap, currentResMap map[string
 		ResourceType: baseResource.ResourceType,
 		Tags:         baseResource.Tags,
 
+		SourceLocation: baseResource.SourceLocation,
+
 		HourlyCost:  diffDecimals(current.HourlyCost, past.HourlyCost),
 		MonthlyCost: diffDecimals(current.MonthlyCost, past.MonthlyCost),
 	}
+	if past.Range != nil || current.Range != nil {
+		diff.Range = &ResourceRange{
+			Low:  &Resource{Name: baseResource.Name, MonthlyCost: diffDecimals(current.LowMonthlyCost(), past.LowMonthlyCost())},
+			High: &Resource{Name: baseResource.Name, MonthlyCost: diffDecimals(current.HighMonthlyCost(), past.HighMonthlyCost())},
+		}
+	}
 	for _, subResource := range past.SubResources {
 		subKey := fmt.Sprintf("%v.%v", resourceKey, subResource.Name)
 		subChanged, subDiff := diffResourcesByKey(subKey, pastResMap, currentResMap)
@@ -172,6 +180,7 @@ func diffCostComponentsByKey(key string, pastCCMap, currentCCMap map[string]*Cos
 		ProductFilter:        baseCostComponent.ProductFilter,
 		PriceFilter:          baseCostComponent.PriceFilter,
 		priceHash:            baseCostComponent.priceHash,
+		priceMatchStatus:     baseCostComponent.priceMatchStatus,
 
 		HourlyQuantity:      diffDecimals(current.HourlyQuantity, past.HourlyQuantity),
 		MonthlyQuantity:     diffDecimals(current.MonthlyQuantity, past.MonthlyQuantity),
@@ -180,9 +189,9 @@ func diffCostComponentsByKey(key string, pastCCMap, currentCCMap map[string]*Cos
 		HourlyCost:          diffDecimals(current.HourlyCost, past.HourlyCost),
 		MonthlyCost:         diffDecimals(current.MonthlyCost, past.MonthlyCost),
 	}
-	if !diff.HourlyQuantity.IsZero() || !diff.MonthlyQuantity.IsZero() ||
+	if !isZeroOrNil(diff.HourlyQuantity) || !isZeroOrNil(diff.MonthlyQuantity) ||
 		diff.MonthlyDiscountPerc != 0 || !diff.price.IsZero() ||
-		!diff.HourlyCost.IsZero() || !diff.MonthlyCost.IsZero() {
+		!isZeroOrNil(diff.HourlyCost) || !isZeroOrNil(diff.MonthlyCost) {
 		changed = true
 	}
 	if pastOk {
@@ -195,11 +204,12 @@ func diffCostComponentsByKey(key string, pastCCMap, currentCCMap map[string]*Cos
 	return changed, diff
 }
 
-// diffDecimals calculates the diff between two decimals.
+// diffDecimals calculates the diff between two decimals. It returns nil if neither
+// decimal is set, so a value that has no cost isn't shown as a zero cost.
 func diffDecimals(current *decimal.Decimal, past *decimal.Decimal) *decimal.Decimal {
 	var diff decimal.Decimal
 	if past == nil && current == nil {
-		diff = decimal.Zero
+		return nil
 	} else if past == nil {
 		diff = *current
 	} else if current == nil {
@@ -213,6 +223,10 @@ func diffDecimals(current *decimal.Decimal, past *decimal.Decimal) *decimal.Deci
 	return &diff
 }
 
+func isZeroOrNil(d *decimal.Decimal) bool {
+	return d == nil || d.IsZero()
+}
+
 // fillResourcesMap fills a given resource map with the structure: {resource_name.sub_resource_name: *Resource}
 func fillResourcesMap(resourcesMap map[string]*Resource, rootKey string, resources []*Resource) {
 	for _, resource := range resources {
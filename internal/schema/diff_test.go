@@ -223,7 +223,7 @@ func TestDiffDecimals(t *testing.T) {
 	assert.Equal(t, decimal.Zero, *diffDecimals(dc1, dc1))
 	assert.Equal(t, decimal.NewFromInt(10), *diffDecimals(dc2, dc1))
 	assert.Equal(t, decimal.NewFromInt(-10), *diffDecimals(dc1, dc2))
-	assert.Equal(t, decimal.Zero, *diffDecimals(nil, nil))
+	assert.Nil(t, diffDecimals(nil, nil))
 }
 
 func TestGetResourcesMap(t *testing.T) {
@@ -1,7 +1,9 @@
 package schema
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -23,6 +25,65 @@ type Resource struct {
 	ResourceType   string
 	Tags           map[string]string
 	UsageSchema    []*UsageSchemaItem
+	SourceLocation *SourceLocation
+
+	// Range is the resource priced with its low and high usage estimates, if any of its
+	// usage values are ranges.
+	Range *ResourceRange
+
+	// Identifiers are the known values that identify the deployed resource in the
+	// cloud, such as its name, ID or ARN. They are used to match the resource to its
+	// usage in exported metrics and billing reports.
+	Identifiers []string
+}
+
+// SourceLocation is where a resource is defined in the IaC files. The filename is
+// relative to the project path.
+type SourceLocation struct {
+	Filename  string `json:"filename"`
+	StartLine int    `json:"startLine"`
+}
+
+// ResourceRange is a resource priced with its low and high usage estimates.
+type ResourceRange struct {
+	Low  *Resource
+	High *Resource
+}
+
+// NewResourceRange creates the resource with the low and high estimates of its usage if
+// any of the usage values are ranges, otherwise it returns nil.
+func NewResourceRange(u *UsageData, create func(*UsageData) *Resource) *ResourceRange {
+	if !u.HasRanges() {
+		return nil
+	}
+
+	low := create(u.Scenario(UsageLow))
+	high := create(u.Scenario(UsageHigh))
+	if low == nil || high == nil {
+		return nil
+	}
+
+	return &ResourceRange{Low: low, High: high}
+}
+
+// LowMonthlyCost returns the monthly cost with the low usage estimates, or the monthly
+// cost if the resource doesn't have a range.
+func (r *Resource) LowMonthlyCost() *decimal.Decimal {
+	if r.Range == nil {
+		return r.MonthlyCost
+	}
+
+	return r.Range.Low.MonthlyCost
+}
+
+// HighMonthlyCost returns the monthly cost with the high usage estimates, or the monthly
+// cost if the resource doesn't have a range.
+func (r *Resource) HighMonthlyCost() *decimal.Decimal {
+	if r.Range == nil {
+		return r.MonthlyCost
+	}
+
+	return r.Range.High.MonthlyCost
 }
 
 func CalculateCosts(project *Project) {
@@ -66,6 +127,11 @@ func (r *Resource) CalculateCosts() {
 		r.HourlyCost = &h
 		r.MonthlyCost = &m
 	}
+
+	if r.Range != nil {
+		r.Range.Low.CalculateCosts()
+		r.Range.High.CalculateCosts()
+	}
 }
 
 func (r *Resource) FlattenedSubResources() []*Resource {
@@ -117,3 +183,26 @@ func MultiplyQuantities(resource *Resource, multiplier decimal.Decimal) {
 func decimalPtr(d decimal.Decimal) *decimal.Decimal {
 	return &d
 }
+
+// AddressModulePart parses a resource addr and returns module prefix.
+// For example: `module.name1.module.name2.resource` will return `module.name1.module.name2.`.
+func AddressModulePart(addr string) string {
+	ap := strings.Split(addr, ".")
+	if len(ap) < 2 {
+		return ""
+	}
+
+	var mp []string
+
+	if len(ap) >= 3 && ap[len(ap)-3] == "data" {
+		mp = ap[:len(ap)-3]
+	} else {
+		mp = ap[:len(ap)-2]
+	}
+
+	if len(mp) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s.", strings.Join(mp, "."))
+}
@@ -3,6 +3,9 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/tidwall/gjson"
@@ -29,16 +32,63 @@ type UsageSchemaItem struct {
 	// Description   string
 }
 
+// UsageScenario is one of the estimates of a usage value that is a range.
+type UsageScenario string
+
+const (
+	UsageLow      UsageScenario = "low"
+	UsageExpected UsageScenario = "expected"
+	UsageHigh     UsageScenario = "high"
+)
+
+// UsageRange is the low and high estimates of a usage value. The expected estimate is
+// the value of the attribute.
+type UsageRange struct {
+	Low  gjson.Result
+	High gjson.Result
+}
+
 type UsageData struct {
 	Address    string
 	Attributes map[string]gjson.Result
+	// Ranges are the low and high estimates of the attributes that are ranges
+	Ranges map[string]UsageRange
+
+	// pattern is the compiled address if it is a wildcard pattern
+	pattern *regexp.Regexp
 }
 
 func NewUsageData(address string, attributes map[string]gjson.Result) *UsageData {
 	return &UsageData{
 		Address:    address,
 		Attributes: attributes,
+		Ranges:     map[string]UsageRange{},
+	}
+}
+
+// HasRanges returns true if any of the usage values are ranges.
+func (u *UsageData) HasRanges() bool {
+	return u != nil && len(u.Ranges) > 0
+}
+
+// Scenario returns the usage data with the values that are ranges replaced by their
+// estimate for the scenario.
+func (u *UsageData) Scenario(scenario UsageScenario) *UsageData {
+	attributes := make(map[string]gjson.Result, len(u.Attributes))
+	for k, v := range u.Attributes {
+		attributes[k] = v
 	}
+
+	for k, r := range u.Ranges {
+		switch scenario {
+		case UsageLow:
+			attributes[k] = r.Low
+		case UsageHigh:
+			attributes[k] = r.High
+		}
+	}
+
+	return NewUsageData(u.Address, attributes)
 }
 
 func (u *UsageData) Get(key string) gjson.Result {
@@ -69,6 +119,15 @@ func (u *UsageData) GetInt(key string) *int64 {
 	return nil
 }
 
+func (u *UsageData) GetString(key string) *string {
+	if u.Get(key).Type != gjson.Null {
+		val := u.Get(key).String()
+		return &val
+	}
+
+	return nil
+}
+
 func convertArrayKeyToWildcard(key string) string {
 	lastOpenBracket := strings.LastIndex(key, "[")
 	lastCloseBracket := strings.LastIndex(key, "]")
@@ -76,14 +135,185 @@ func convertArrayKeyToWildcard(key string) string {
 	return key[:lastOpenBracket+1] + "*" + key[lastCloseBracket:]
 }
 
+// usageDefaultsPrefix is the prefix of the keys in the usage map that hold the default
+// usage for a resource type. It can't clash with a resource address since there is no
+// resource type called defaults.
+const usageDefaultsPrefix = "defaults."
+
+// UsageDefaultsKey returns the key in the usage map of the default usage for the resource type.
+func UsageDefaultsKey(resourceType string) string {
+	return usageDefaultsPrefix + resourceType
+}
+
+// IsUsageDefaultsKey returns true if the key in the usage map holds the default usage for
+// a resource type, and returns the resource type.
+func IsUsageDefaultsKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, usageDefaultsPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(key, usageDefaultsPrefix), true
+}
+
+// IsUsagePattern returns true if the key in the usage map is a wildcard pattern that can
+// match many resource addresses, e.g. `module.*.aws_lambda_function.*`. Keys that only
+// have a `[*]` suffix are not patterns since they are looked up directly.
+func IsUsagePattern(key string) bool {
+	return strings.Contains(strings.TrimSuffix(key, "[*]"), "*")
+}
+
+// CompileUsagePattern compiles the wildcard pattern to a regexp that matches addresses.
+// A `*` matches any characters, including dots, so `module.*.aws_lambda_function.*`
+// also matches resources in nested modules.
+func CompileUsagePattern(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+}
+
+// UsageIndex finds the usage for resources. The wildcard patterns are indexed by the
+// resource type in the pattern, so only the patterns that can match a resource are
+// checked rather than every key in the usage map.
+type UsageIndex struct {
+	usage map[string]*UsageData
+
+	// typePatterns are the patterns with a literal resource type, by the resource type
+	typePatterns map[string][]usagePattern
+	// anyTypePatterns are the patterns that can match any resource type, e.g. `module.*.*`
+	anyTypePatterns []usagePattern
+}
+
+type usagePattern struct {
+	key string
+	re  *regexp.Regexp
+}
+
+var usagePatternIndexRegex = regexp.MustCompile(`\[[^\]]*\]`)
+var usagePatternTypeRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// NewUsageIndex indexes the wildcard patterns in the usage map.
+func NewUsageIndex(usage map[string]*UsageData) *UsageIndex {
+	idx := &UsageIndex{
+		usage:        usage,
+		typePatterns: make(map[string][]usagePattern),
+	}
+
+	for key, ud := range usage {
+		if !IsUsagePattern(key) {
+			continue
+		}
+
+		// Patterns are compiled when the usage map is created, but not if the map was
+		// built some other way
+		re := ud.pattern
+		if re == nil {
+			re = CompileUsagePattern(key)
+		}
+
+		p := usagePattern{key: key, re: re}
+
+		// The resource type is the second to last part of the address. Indexes are
+		// removed first since they can contain dots.
+		parts := strings.Split(usagePatternIndexRegex.ReplaceAllString(key, ""), ".")
+		if len(parts) < 2 || !usagePatternTypeRegex.MatchString(parts[len(parts)-2]) {
+			idx.anyTypePatterns = append(idx.anyTypePatterns, p)
+			continue
+		}
+
+		resourceType := parts[len(parts)-2]
+		idx.typePatterns[resourceType] = append(idx.typePatterns[resourceType], p)
+	}
+
+	return idx
+}
+
+// FindUsageData returns the usage for the resource. It creates an index of the usage
+// map for the lookup so NewUsageIndex should be used when finding the usage of many
+// resources.
+func FindUsageData(usage map[string]*UsageData, address string, resourceType string) *UsageData {
+	return NewUsageIndex(usage).Find(address, resourceType)
+}
+
+// Find returns the usage for the resource. The usage is merged from the defaults for
+// the resource type, any matching wildcard patterns from the least to the most
+// specific, and then the resource's own usage or the `[*]` usage of its array. Later
+// values override earlier ones. Returns nil if there is no usage for the resource.
+func (idx *UsageIndex) Find(address string, resourceType string) *UsageData {
+	usage := idx.usage
+	sources := make([]*UsageData, 0)
+
+	if ud := usage[UsageDefaultsKey(resourceType)]; ud != nil {
+		sources = append(sources, ud)
+	}
+
+	patterns := make([]string, 0)
+	for _, candidates := range [][]usagePattern{idx.typePatterns[resourceType], idx.anyTypePatterns} {
+		for _, p := range candidates {
+			if p.re.MatchString(address) {
+				patterns = append(patterns, p.key)
+			}
+		}
+	}
+
+	// Patterns with more literal characters are more specific
+	sort.Slice(patterns, func(i, j int) bool {
+		li := len(strings.ReplaceAll(patterns[i], "*", ""))
+		lj := len(strings.ReplaceAll(patterns[j], "*", ""))
+		if li != lj {
+			return li < lj
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	for _, p := range patterns {
+		sources = append(sources, usage[p])
+	}
+
+	if ud := usage[address]; ud != nil {
+		sources = append(sources, ud)
+	} else if strings.HasSuffix(address, "]") {
+		lastIndexOfOpenBracket := strings.LastIndex(address, "[")
+
+		if ud := usage[fmt.Sprintf("%s[*]", address[:lastIndexOfOpenBracket])]; ud != nil {
+			sources = append(sources, ud)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	if len(sources) == 1 {
+		return sources[0]
+	}
+
+	merged := NewUsageData(address, make(map[string]gjson.Result))
+	for _, ud := range sources {
+		for k, v := range ud.Attributes {
+			merged.Attributes[k] = v
+			delete(merged.Ranges, k)
+		}
+		for k, r := range ud.Ranges {
+			merged.Ranges[k] = r
+		}
+	}
+
+	return merged
+}
+
 func NewUsageMap(m map[string]interface{}) map[string]*UsageData {
 	usageMap := make(map[string]*UsageData)
 
 	for addr, v := range m {
+		value, ranges := extractRanges(v)
+
 		usageMap[addr] = NewUsageData(
 			addr,
-			ParseAttributes(v),
+			ParseAttributes(value),
 		)
+		usageMap[addr].Ranges = ranges
+
+		if IsUsagePattern(addr) {
+			usageMap[addr].pattern = CompileUsagePattern(addr)
+		}
 	}
 
 	return usageMap
@@ -103,6 +333,106 @@ func ParseAttributes(i interface{}) map[string]gjson.Result {
 	return a
 }
 
+// IsUsageRange returns true if the value is a range, i.e. a map that only has `low`,
+// `expected` and `high` estimates and has a low or high estimate. Maps that have other
+// keys, or whose values are maps, are nested usage values rather than ranges.
+func IsUsageRange(m map[string]interface{}) bool {
+	hasLowOrHigh := false
+
+	for k, v := range m {
+		switch UsageScenario(k) {
+		case UsageLow, UsageHigh:
+			hasLowOrHigh = true
+		case UsageExpected:
+		default:
+			return false
+		}
+
+		if _, ok := usageValueMap(v); ok {
+			return false
+		}
+	}
+
+	return hasLowOrHigh
+}
+
+// extractRanges finds the values that are ranges and replaces each of them with its
+// expected estimate. If there is no expected estimate it defaults to the midpoint of
+// the low and high estimates, and the low and high estimates default to the expected
+// estimate if they aren't given. The ranges are keyed by the flattened attribute key.
+func extractRanges(v interface{}) (interface{}, map[string]UsageRange) {
+	ranges := make(map[string]UsageRange)
+	return extractRangesHelper(v, []string{}, ranges), ranges
+}
+
+func extractRangesHelper(v interface{}, keys []string, ranges map[string]UsageRange) interface{} {
+	m, ok := usageValueMap(v)
+	if !ok {
+		return v
+	}
+
+	if len(keys) > 0 && IsUsageRange(m) {
+		j, _ := json.Marshal(m)
+		r := gjson.ParseBytes(j)
+
+		low, high := r.Get(string(UsageLow)), r.Get(string(UsageHigh))
+		expected := r.Get(string(UsageExpected))
+		if !expected.Exists() {
+			expected = ExpectedUsageEstimate(low, high)
+		}
+
+		if !low.Exists() {
+			low = expected
+		}
+		if !high.Exists() {
+			high = expected
+		}
+
+		ranges[strings.Join(keys, ".")] = UsageRange{Low: low, High: high}
+
+		return expected.Value()
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, child := range m {
+		result[k] = extractRangesHelper(child, append(keys[:len(keys):len(keys)], k), ranges)
+	}
+
+	return result
+}
+
+// usageValueMap returns the value as a map with string keys if it is a map.
+func usageValueMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(m))
+		for k, child := range m {
+			result[fmt.Sprintf("%v", k)] = child
+		}
+		return result, true
+	}
+
+	return nil, false
+}
+
+// ExpectedUsageEstimate returns the expected estimate of a range that doesn't have
+// one. It is the midpoint of the low and high estimates if they are both numbers,
+// otherwise whichever of them is given.
+func ExpectedUsageEstimate(low gjson.Result, high gjson.Result) gjson.Result {
+	if low.Type == gjson.Number && high.Type == gjson.Number {
+		mid := (low.Float() + high.Float()) / 2
+		return gjson.Parse(strconv.FormatFloat(mid, 'f', -1, 64))
+	}
+
+	if low.Exists() {
+		return low
+	}
+
+	return high
+}
+
 func flatten(i interface{}) map[string]interface{} {
 	keys := make([]string, 0)
 	result := make(map[string]interface{})
@@ -0,0 +1,337 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// expression is a usage value that hasn't been evaluated yet.
+type expression string
+
+// expressionLocation is where an expression is in the usage file, so it can be
+// replaced by its value once it has been evaluated.
+type expressionLocation struct {
+	defaults bool
+	address  string
+	path     []string
+}
+
+func (l expressionLocation) String() string {
+	addr := l.address
+	if l.defaults {
+		addr = fmt.Sprintf("defaults.%s", l.address)
+	}
+
+	return fmt.Sprintf("%s.%s", addr, strings.Join(l.path, "."))
+}
+
+var expressionFunctions = map[string]function.Function{
+	"abs":   stdlib.AbsoluteFunc,
+	"ceil":  stdlib.CeilFunc,
+	"floor": stdlib.FloorFunc,
+	"max":   stdlib.MaxFunc,
+	"min":   stdlib.MinFunc,
+}
+
+// evaluateExpressions replaces any values that contain `${...}` with the result of
+// evaluating them as HCL templates, e.g. `${monthly_requests * 0.2}`. Expressions can
+// refer to the other keys of the same resource, including the defaults for its type,
+// and to the keys of other resources by their address. Since expressions can refer to
+// other expressions, they're evaluated in passes until no more can be evaluated.
+func evaluateExpressions(defaults map[string]interface{}, resourceUsage map[string]interface{}) error {
+	locations := make([]expressionLocation, 0)
+
+	for addr, v := range defaults {
+		locations = append(locations, findExpressions(v, expressionLocation{defaults: true, address: addr})...)
+	}
+
+	for addr, v := range resourceUsage {
+		locations = append(locations, findExpressions(v, expressionLocation{address: addr})...)
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].String() < locations[j].String()
+	})
+
+	for len(locations) > 0 {
+		remaining := make([]expressionLocation, 0, len(locations))
+
+		for _, l := range locations {
+			val, err := evaluateExpression(l, defaults, resourceUsage)
+			if err != nil {
+				remaining = append(remaining, l)
+				continue
+			}
+
+			section := resourceUsage
+			if l.defaults {
+				section = defaults
+			}
+			setValue(section[l.address], l.path, val)
+		}
+
+		if len(remaining) == len(locations) {
+			_, err := evaluateExpression(remaining[0], defaults, resourceUsage)
+			return errors.Wrapf(err, "Error evaluating %s", remaining[0])
+		}
+
+		locations = remaining
+	}
+
+	return nil
+}
+
+// findExpressions replaces the template strings in the value with expressions and
+// returns their locations.
+func findExpressions(v interface{}, loc expressionLocation) []expressionLocation {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	locations := make([]expressionLocation, 0)
+
+	for k, child := range m {
+		path := append(append([]string{}, loc.path...), k)
+
+		switch c := child.(type) {
+		case string:
+			if strings.Contains(c, "${") {
+				m[k] = expression(c)
+				locations = append(locations, expressionLocation{defaults: loc.defaults, address: loc.address, path: path})
+			}
+		case map[string]interface{}:
+			locations = append(locations, findExpressions(c, expressionLocation{defaults: loc.defaults, address: loc.address, path: path})...)
+		}
+	}
+
+	return locations
+}
+
+func evaluateExpression(l expressionLocation, defaults map[string]interface{}, resourceUsage map[string]interface{}) (interface{}, error) {
+	section := resourceUsage
+	if l.defaults {
+		section = defaults
+	}
+
+	expr, _ := getValue(section[l.address], l.path).(expression)
+
+	tmpl, diags := hclsyntax.ParseTemplate([]byte(expr), l.String(), hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ctx, err := expressionContext(l, defaults, resourceUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	val, diags := tmpl.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return fromCtyValue(val)
+}
+
+// expressionContext returns the variables an expression can refer to. The resources are
+// nested by the parts of their address, and the keys of the expression's own resource
+// are added on top so they can be referred to directly. Values that haven't been
+// evaluated yet are left out so referring to them is an error until they have been.
+func expressionContext(l expressionLocation, defaults map[string]interface{}, resourceUsage map[string]interface{}) (*hcl.EvalContext, error) {
+	vars := make(map[string]interface{})
+
+	for addr, v := range resourceUsage {
+		parts := strings.Split(addr, ".")
+
+		valid := true
+		for _, p := range parts {
+			if !hclsyntax.ValidIdentifier(p) {
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			setNestedValue(vars, parts, withoutExpressions(v))
+		}
+	}
+
+	own := make(map[string]interface{})
+	if l.defaults {
+		d, _ := withoutExpressions(defaults[l.address]).(map[string]interface{})
+		mergeMaps(own, d)
+	} else {
+		d, _ := withoutExpressions(defaults[resourceTypeFromAddress(l.address)]).(map[string]interface{})
+		mergeMaps(own, d)
+		v, _ := withoutExpressions(resourceUsage[l.address]).(map[string]interface{})
+		mergeMaps(own, v)
+	}
+
+	for k, v := range own {
+		if hclsyntax.ValidIdentifier(k) {
+			vars[k] = v
+		}
+	}
+
+	variables := make(map[string]cty.Value, len(vars))
+	for k, v := range vars {
+		val, err := toCtyValue(v)
+		if err != nil {
+			return nil, err
+		}
+		variables[k] = val
+	}
+
+	return &hcl.EvalContext{
+		Variables: variables,
+		Functions: expressionFunctions,
+	}, nil
+}
+
+// withoutExpressions returns a copy of the value without any expressions that haven't
+// been evaluated yet. Ranges are replaced by their expected estimate, so expressions
+// that refer to them get a number.
+func withoutExpressions(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, child := range m {
+		if _, ok := child.(expression); ok {
+			continue
+		}
+
+		if r, ok := child.(map[string]interface{}); ok && schema.IsUsageRange(r) {
+			if e, ok := rangeExpectedValue(r); ok {
+				result[k] = e
+			}
+			continue
+		}
+
+		result[k] = withoutExpressions(child)
+	}
+
+	return result
+}
+
+// rangeExpectedValue returns the expected estimate of the range. It returns false if
+// any of its estimates are expressions that haven't been evaluated yet.
+func rangeExpectedValue(m map[string]interface{}) (interface{}, bool) {
+	for _, v := range m {
+		if _, ok := v.(expression); ok {
+			return nil, false
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, false
+	}
+	r := gjson.ParseBytes(b)
+
+	expected := r.Get(string(schema.UsageExpected))
+	if !expected.Exists() {
+		expected = schema.ExpectedUsageEstimate(r.Get(string(schema.UsageLow)), r.Get(string(schema.UsageHigh)))
+	}
+
+	return expected.Value(), true
+}
+
+func getValue(v interface{}, path []string) interface{} {
+	for _, p := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[p]
+	}
+
+	return v
+}
+
+func setValue(v interface{}, path []string, val interface{}) {
+	m, ok := getValue(v, path[:len(path)-1]).(map[string]interface{})
+	if ok {
+		m[path[len(path)-1]] = val
+	}
+}
+
+func setNestedValue(m map[string]interface{}, path []string, val interface{}) {
+	for _, p := range path[:len(path)-1] {
+		child, ok := m[p].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[p] = child
+		}
+		m = child
+	}
+
+	key := path[len(path)-1]
+	if existing, ok := m[key].(map[string]interface{}); ok {
+		if v, ok := val.(map[string]interface{}); ok {
+			mergeMaps(existing, v)
+			return
+		}
+	}
+
+	m[key] = val
+}
+
+func toCtyValue(v interface{}) (cty.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	t, err := ctyjson.ImpliedType(b)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	return ctyjson.Unmarshal(b, t)
+}
+
+func fromCtyValue(val cty.Value) (interface{}, error) {
+	if !val.IsWhollyKnown() || val.IsNull() {
+		return nil, errors.New("Expression does not have a value")
+	}
+
+	switch val.Type() {
+	case cty.Number:
+		f := val.AsBigFloat()
+		if i, acc := f.Int64(); acc == big.Exact {
+			return i, nil
+		}
+		v, _ := f.Float64()
+		return v, nil
+	case cty.String:
+		return val.AsString(), nil
+	case cty.Bool:
+		return val.True(), nil
+	}
+
+	b, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
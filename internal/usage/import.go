@@ -0,0 +1,614 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	aggregateSum     = "sum"
+	aggregateAverage = "average"
+)
+
+// bytesToGB converts the bytes in CloudWatch metrics to GB. Cost and Usage Reports are
+// already in GB.
+const bytesToGB = 1.0 / (1024 * 1024 * 1024)
+
+// importMapping maps an exported metric onto a usage key of a resource type. Metrics
+// that are summed are divided by the number of months the export covers, and metrics
+// that are averaged are used as they are, e.g. for storage or durations.
+type importMapping struct {
+	ResourceType string
+	UsageKey     string
+	Aggregate    string
+	Scale        float64
+
+	// CloudWatch only: the dimension that identifies the resource, and any other
+	// dimensions the metric must have
+	Dimension string
+	Filter    map[string]string
+}
+
+// cloudWatchMappings are keyed by the namespace and metric name.
+var cloudWatchMappings = map[string][]importMapping{
+	"AWS/Lambda/Invocations": {
+		{ResourceType: "aws_lambda_function", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1, Dimension: "FunctionName"},
+	},
+	"AWS/Lambda/Duration": {
+		{ResourceType: "aws_lambda_function", UsageKey: "request_duration_ms", Aggregate: aggregateAverage, Scale: 1, Dimension: "FunctionName"},
+	},
+	"AWS/S3/BucketSizeBytes": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard.storage_gb", Aggregate: aggregateAverage, Scale: bytesToGB, Dimension: "BucketName", Filter: map[string]string{"StorageType": "StandardStorage"}},
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard_infrequent_access.storage_gb", Aggregate: aggregateAverage, Scale: bytesToGB, Dimension: "BucketName", Filter: map[string]string{"StorageType": "StandardIAStorage"}},
+		{ResourceType: "aws_s3_bucket", UsageKey: "one_zone_infrequent_access.storage_gb", Aggregate: aggregateAverage, Scale: bytesToGB, Dimension: "BucketName", Filter: map[string]string{"StorageType": "OneZoneIAStorage"}},
+	},
+	"AWS/S3/PutRequests":    {s3CloudWatchRequests("monthly_tier_1_requests")},
+	"AWS/S3/PostRequests":   {s3CloudWatchRequests("monthly_tier_1_requests")},
+	"AWS/S3/ListRequests":   {s3CloudWatchRequests("monthly_tier_1_requests")},
+	"AWS/S3/GetRequests":    {s3CloudWatchRequests("monthly_tier_2_requests")},
+	"AWS/S3/HeadRequests":   {s3CloudWatchRequests("monthly_tier_2_requests")},
+	"AWS/S3/SelectRequests": {s3CloudWatchRequests("monthly_tier_2_requests")},
+	"AWS/DynamoDB/ConsumedReadCapacityUnits": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "monthly_read_request_units", Aggregate: aggregateSum, Scale: 1, Dimension: "TableName"},
+	},
+	"AWS/DynamoDB/ConsumedWriteCapacityUnits": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "monthly_write_request_units", Aggregate: aggregateSum, Scale: 1, Dimension: "TableName"},
+	},
+	"AWS/NATGateway/BytesInFromSource": {
+		{ResourceType: "aws_nat_gateway", UsageKey: "monthly_data_processed_gb", Aggregate: aggregateSum, Scale: bytesToGB, Dimension: "NatGatewayId"},
+	},
+	"AWS/NATGateway/BytesInFromDestination": {
+		{ResourceType: "aws_nat_gateway", UsageKey: "monthly_data_processed_gb", Aggregate: aggregateSum, Scale: bytesToGB, Dimension: "NatGatewayId"},
+	},
+	"AWS/SQS/NumberOfMessagesSent":     {sqsCloudWatchRequests()},
+	"AWS/SQS/NumberOfMessagesReceived": {sqsCloudWatchRequests()},
+	"AWS/SQS/NumberOfMessagesDeleted":  {sqsCloudWatchRequests()},
+	"AWS/SNS/NumberOfMessagesPublished": {
+		{ResourceType: "aws_sns_topic", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1, Dimension: "TopicName"},
+	},
+	"AWS/ApiGateway/Count": {
+		{ResourceType: "aws_api_gateway_rest_api", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1, Dimension: "ApiName"},
+	},
+	"AWS/Logs/IncomingBytes": {
+		{ResourceType: "aws_cloudwatch_log_group", UsageKey: "monthly_data_ingested_gb", Aggregate: aggregateSum, Scale: bytesToGB, Dimension: "LogGroupName"},
+	},
+	"AWS/Firehose/IncomingBytes": {
+		{ResourceType: "aws_kinesis_firehose_delivery_stream", UsageKey: "monthly_data_ingested_gb", Aggregate: aggregateSum, Scale: bytesToGB, Dimension: "DeliveryStreamName"},
+	},
+}
+
+func s3CloudWatchRequests(usageKey string) importMapping {
+	return importMapping{ResourceType: "aws_s3_bucket", UsageKey: "standard." + usageKey, Aggregate: aggregateSum, Scale: 1, Dimension: "BucketName"}
+}
+
+func sqsCloudWatchRequests() importMapping {
+	return importMapping{ResourceType: "aws_sqs_queue", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1, Dimension: "QueueName"}
+}
+
+// curMappings are keyed by the product code and usage type, without the region prefix
+// of the usage type, e.g. `Request` for `USE1-Request`.
+var curMappings = map[string][]importMapping{
+	"AWSLambda/Request": {
+		{ResourceType: "aws_lambda_function", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonS3/TimedStorage-ByteHrs": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard.storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonS3/TimedStorage-SIA-ByteHrs": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard_infrequent_access.storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonS3/TimedStorage-ZIA-ByteHrs": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "one_zone_infrequent_access.storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonS3/Requests-Tier1": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard.monthly_tier_1_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonS3/Requests-Tier2": {
+		{ResourceType: "aws_s3_bucket", UsageKey: "standard.monthly_tier_2_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonDynamoDB/TimedStorage-ByteHrs": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonDynamoDB/TimedPITRStorage-ByteHrs": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "pitr_backup_storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonDynamoDB/TimedBackupStorage-ByteHrs": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "on_demand_backup_storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonDynamoDB/ReadRequestUnits": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "monthly_read_request_units", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonDynamoDB/WriteRequestUnits": {
+		{ResourceType: "aws_dynamodb_table", UsageKey: "monthly_write_request_units", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonEC2/NatGateway-Bytes": {
+		{ResourceType: "aws_nat_gateway", UsageKey: "monthly_data_processed_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AWSQueueService/Requests-Tier1": {
+		{ResourceType: "aws_sqs_queue", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AWSQueueService/Requests-FIFO-Tier1": {
+		{ResourceType: "aws_sqs_queue", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonSNS/Requests-Tier1": {
+		{ResourceType: "aws_sns_topic", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonApiGateway/ApiGatewayRequest": {
+		{ResourceType: "aws_api_gateway_rest_api", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonApiGateway/ApiGatewayHttpRequest": {
+		{ResourceType: "aws_apigatewayv2_api", UsageKey: "monthly_requests", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonCloudWatch/DataProcessing-Bytes": {
+		{ResourceType: "aws_cloudwatch_log_group", UsageKey: "monthly_data_ingested_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+	"AmazonCloudWatch/TimedStorage-ByteHrs": {
+		{ResourceType: "aws_cloudwatch_log_group", UsageKey: "storage_gb", Aggregate: aggregateSum, Scale: 1},
+	},
+}
+
+// curUsageLineItemTypes are the line item types of the Cost and Usage Report that are
+// usage, rather than fees, credits or taxes.
+var curUsageLineItemTypes = []string{"Usage", "DiscountedUsage", "SavingsPlanCoveredUsage"}
+
+// importRecord is a single data point of an exported metric, mapped onto the usage key
+// of the resources it identifies.
+type importRecord struct {
+	ResourceID string
+	Mapping    importMapping
+	Sum        float64
+	Count      float64
+	Start      time.Time
+	End        time.Time
+}
+
+// ImportResult is the monthly usage imported from the exported metrics, keyed by the
+// resource address and then the usage key.
+type ImportResult struct {
+	Usage map[string]map[string]float64
+
+	// UnmatchedIDs are the resource IDs in the exported metrics that don't match any
+	// of the resources.
+	UnmatchedIDs []string
+}
+
+// ImportFromFiles reads usage from CloudWatch metrics or Cost and Usage Reports that
+// have been exported to files, and maps it onto the usage keys of the resources whose
+// identifiers match. Each file is one of:
+//
+//   - a Cost and Usage Report CSV file
+//   - CloudWatch metric stream records in JSON, either one object per line or an array
+//   - a CloudWatch CSV file with namespace, metric_name, dimensions, timestamp, sum and
+//     count columns, where the dimensions are `Name=value` pairs separated by `;`
+//
+// The values of each file are converted to monthly values using the period it covers.
+// Values are only added up within a file. If more than one file has a value for the same
+// usage key of a resource, the value from the last file is used.
+func ImportFromFiles(paths []string, resources []*schema.Resource) (*ImportResult, error) {
+	result := &ImportResult{
+		Usage:        make(map[string]map[string]float64),
+		UnmatchedIDs: make([]string, 0),
+	}
+
+	unmatched := make(map[string]bool)
+
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading %s", path)
+		}
+
+		records, err := parseImportFile(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing %s", path)
+		}
+
+		usage, fileUnmatched := importRecordsUsage(records, resources)
+
+		for addr, values := range usage {
+			if result.Usage[addr] == nil {
+				result.Usage[addr] = make(map[string]float64)
+			}
+			for k, v := range values {
+				result.Usage[addr][k] = v
+			}
+		}
+
+		for id := range fileUnmatched {
+			unmatched[id] = true
+		}
+	}
+
+	for id := range unmatched {
+		result.UnmatchedIDs = append(result.UnmatchedIDs, id)
+	}
+	sort.Strings(result.UnmatchedIDs)
+
+	return result, nil
+}
+
+func parseImportFile(data []byte) ([]importRecord, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return parseCloudWatchJSON(trimmed)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := make(map[string]int, len(rows[0]))
+	for i, h := range rows[0] {
+		header[strings.TrimSpace(h)] = i
+	}
+
+	if _, ok := header["lineItem/ResourceId"]; ok {
+		return parseCURRows(header, rows[1:])
+	}
+
+	if _, ok := header["metric_name"]; ok {
+		return parseCloudWatchRows(header, rows[1:])
+	}
+
+	return nil, errors.New("Unknown file format, expected a Cost and Usage Report or CloudWatch metrics")
+}
+
+// cloudWatchStreamRecord is a record in the JSON output format of CloudWatch metric
+// streams.
+type cloudWatchStreamRecord struct {
+	Namespace  string            `json:"namespace"`
+	MetricName string            `json:"metric_name"`
+	Dimensions map[string]string `json:"dimensions"`
+	Timestamp  int64             `json:"timestamp"`
+	Value      struct {
+		Sum   float64 `json:"sum"`
+		Count float64 `json:"count"`
+	} `json:"value"`
+}
+
+func parseCloudWatchJSON(data []byte) ([]importRecord, error) {
+	var streamRecords []cloudWatchStreamRecord
+
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &streamRecords)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var r cloudWatchStreamRecord
+			err := dec.Decode(&r)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			streamRecords = append(streamRecords, r)
+		}
+	}
+
+	records := make([]importRecord, 0, len(streamRecords))
+	for _, r := range streamRecords {
+		t := time.Unix(0, r.Timestamp*int64(time.Millisecond)).UTC()
+		records = append(records, cloudWatchRecords(r.Namespace, r.MetricName, r.Dimensions, t, r.Value.Sum, r.Value.Count)...)
+	}
+
+	return records, nil
+}
+
+func parseCloudWatchRows(header map[string]int, rows [][]string) ([]importRecord, error) {
+	records := make([]importRecord, 0, len(rows))
+
+	for i, row := range rows {
+		get := csvGetter(header, row)
+
+		dimensions := make(map[string]string)
+		for _, pair := range strings.Split(get("dimensions"), ";") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 2 {
+				dimensions[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			}
+		}
+
+		t, err := parseImportTime(get("timestamp"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid timestamp on row %d", i+2)
+		}
+
+		sum, err := strconv.ParseFloat(get("sum"), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid sum on row %d", i+2)
+		}
+
+		count := 1.0
+		if get("count") != "" {
+			count, err = strconv.ParseFloat(get("count"), 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid count on row %d", i+2)
+			}
+		}
+
+		records = append(records, cloudWatchRecords(get("namespace"), get("metric_name"), dimensions, t, sum, count)...)
+	}
+
+	return records, nil
+}
+
+func cloudWatchRecords(namespace string, metricName string, dimensions map[string]string, t time.Time, sum float64, count float64) []importRecord {
+	records := make([]importRecord, 0)
+
+	for _, m := range cloudWatchMappings[fmt.Sprintf("%s/%s", namespace, metricName)] {
+		id, ok := dimensions[m.Dimension]
+		if !ok || !matchesFilter(dimensions, m.Filter) {
+			continue
+		}
+
+		records = append(records, importRecord{
+			ResourceID: id,
+			Mapping:    m,
+			Sum:        sum,
+			Count:      count,
+			Start:      t,
+			End:        t,
+		})
+	}
+
+	return records
+}
+
+func parseCURRows(header map[string]int, rows [][]string) ([]importRecord, error) {
+	records := make([]importRecord, 0, len(rows))
+
+	for i, row := range rows {
+		get := csvGetter(header, row)
+
+		if t := get("lineItem/LineItemType"); t != "" && !contains(curUsageLineItemTypes, t) {
+			continue
+		}
+
+		mappings := curMappings[fmt.Sprintf("%s/%s", get("lineItem/ProductCode"), stripUsageTypeRegion(get("lineItem/UsageType")))]
+		if len(mappings) == 0 || get("lineItem/ResourceId") == "" {
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(get("lineItem/UsageAmount"), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid usage amount on row %d", i+2)
+		}
+
+		start, err := parseImportTime(get("lineItem/UsageStartDate"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid usage start date on row %d", i+2)
+		}
+
+		end, err := parseImportTime(get("lineItem/UsageEndDate"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid usage end date on row %d", i+2)
+		}
+
+		for _, m := range mappings {
+			records = append(records, importRecord{
+				ResourceID: get("lineItem/ResourceId"),
+				Mapping:    m,
+				Sum:        amount,
+				Count:      1,
+				Start:      start,
+				End:        end,
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// importRecordsUsage returns the monthly usage of the records of a file, keyed by the
+// resource address and then the usage key, and the resource IDs that didn't match any
+// resources. Records for different metrics that map onto the same usage key are added
+// up.
+func importRecordsUsage(records []importRecord, resources []*schema.Resource) (map[string]map[string]float64, map[string]bool) {
+	usage := make(map[string]map[string]float64)
+	unmatched := make(map[string]bool)
+
+	months := importPeriodMonths(records)
+
+	type total struct {
+		mapping importMapping
+		id      string
+		sum     float64
+		count   float64
+	}
+
+	totals := make(map[string]*total)
+	for _, r := range records {
+		k := fmt.Sprintf("%s/%s/%s", r.ResourceID, r.Mapping.ResourceType, r.Mapping.UsageKey)
+		if totals[k] == nil {
+			totals[k] = &total{mapping: r.Mapping, id: r.ResourceID}
+		}
+		totals[k].sum += r.Sum
+		totals[k].count += r.Count
+	}
+
+	for _, t := range totals {
+		matched := matchIdentifier(t.id, t.mapping.ResourceType, resources)
+		if len(matched) == 0 {
+			unmatched[t.id] = true
+			continue
+		}
+
+		value := t.sum / months
+		if t.mapping.Aggregate == aggregateAverage {
+			if t.count == 0 {
+				continue
+			}
+			value = t.sum / t.count
+		}
+		value *= t.mapping.Scale
+
+		for _, r := range matched {
+			if usage[r.Name] == nil {
+				usage[r.Name] = make(map[string]float64)
+			}
+			usage[r.Name][t.mapping.UsageKey] += value
+		}
+	}
+
+	return usage, unmatched
+}
+
+// importPeriodMonths returns the number of months the records cover. CloudWatch data
+// points are at the start of their period, so the period is guessed from the smallest
+// gap between them. A single data point is assumed to cover a month.
+func importPeriodMonths(records []importRecord) float64 {
+	if len(records) == 0 {
+		return 1
+	}
+
+	start, end := records[0].Start, records[0].End
+	times := make([]time.Time, 0, len(records))
+	dataPoints := true
+
+	for _, r := range records {
+		if r.Start.Before(start) {
+			start = r.Start
+		}
+		if r.End.After(end) {
+			end = r.End
+		}
+		if !r.End.Equal(r.Start) {
+			dataPoints = false
+		}
+		times = append(times, r.Start)
+	}
+
+	if dataPoints {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		var period time.Duration
+		for i := 1; i < len(times); i++ {
+			gap := times[i].Sub(times[i-1])
+			if gap > 0 && (period == 0 || gap < period) {
+				period = gap
+			}
+		}
+
+		if period == 0 {
+			return 1
+		}
+
+		end = end.Add(period)
+	}
+
+	return end.Sub(start).Hours() / float64(schema.HourToMonthUnitMultiplier)
+}
+
+// cloudFormationResourceTypes are the resource types of the import mappings for the
+// CloudFormation resources that usage can be imported for. The other CloudFormation
+// resources don't have a usage schema so the usage file can't be synced with them.
+var cloudFormationResourceTypes = map[string]string{
+	"AWS::Lambda::Function": "aws_lambda_function",
+	"AWS::DynamoDB::Table":  "aws_dynamodb_table",
+	"AWS::EC2::NatGateway":  "aws_nat_gateway",
+}
+
+// matchIdentifier returns the resources of the type that have the ID as one of their
+// identifiers or as their address. ARNs also match on the name at the end of the ARN,
+// since the ARN often isn't known until the resource is deployed.
+func matchIdentifier(id string, resourceType string, resources []*schema.Resource) []*schema.Resource {
+	ids := []string{id}
+	if strings.HasPrefix(id, "arn:") {
+		i := strings.LastIndexAny(id, ":/")
+		ids = append(ids, id[i+1:])
+	}
+
+	matched := make([]*schema.Resource, 0)
+
+	for _, r := range resources {
+		if r.ResourceType != resourceType && cloudFormationResourceTypes[r.ResourceType] != resourceType {
+			continue
+		}
+
+		if contains(ids, r.Name) {
+			matched = append(matched, r)
+			continue
+		}
+
+		for _, identifier := range r.Identifiers {
+			if contains(ids, identifier) {
+				matched = append(matched, r)
+				break
+			}
+		}
+	}
+
+	return matched
+}
+
+func matchesFilter(dimensions map[string]string, filter map[string]string) bool {
+	for k, v := range filter {
+		if dimensions[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stripUsageTypeRegion removes the region prefix from a usage type, e.g. `USE1-Request`
+// becomes `Request`. Usage types in us-east-1 often don't have a prefix.
+func stripUsageTypeRegion(usageType string) string {
+	parts := strings.SplitN(usageType, "-", 2)
+	if len(parts) == 2 && parts[0] == strings.ToUpper(parts[0]) && len(parts[0]) <= 5 && strings.ContainsAny(parts[0], "0123456789") {
+		return parts[1]
+	}
+
+	return usageType
+}
+
+func parseImportTime(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func csvGetter(header map[string]int, row []string) func(string) string {
+	return func(col string) string {
+		i, ok := header[col]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+}
+
+func contains(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
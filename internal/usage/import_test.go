@@ -0,0 +1,147 @@
+package usage
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCURFile = `lineItem/LineItemType,lineItem/UsageStartDate,lineItem/UsageEndDate,lineItem/ProductCode,lineItem/UsageType,lineItem/ResourceId,lineItem/UsageAmount
+Usage,2021-06-01T00:00:00Z,2021-06-16T05:00:00Z,AWSLambda,USE1-Request,arn:aws:lambda:us-east-1:123456789012:function:api,1000000
+Usage,2021-06-16T05:00:00Z,2021-07-01T10:00:00Z,AWSLambda,USE1-Request,arn:aws:lambda:us-east-1:123456789012:function:api,1000000
+Usage,2021-06-01T00:00:00Z,2021-07-01T10:00:00Z,AmazonS3,TimedStorage-ByteHrs,my-bucket,250
+Usage,2021-06-01T00:00:00Z,2021-07-01T10:00:00Z,AmazonS3,USE1-Requests-Tier1,my-bucket,4000
+Usage,2021-06-01T00:00:00Z,2021-07-01T10:00:00Z,AmazonEC2,USE1-NatGateway-Bytes,arn:aws:ec2:us-east-1:123456789012:natgateway/nat-0abc,50
+Usage,2021-06-01T00:00:00Z,2021-07-01T10:00:00Z,AWSLambda,USE1-Request,arn:aws:lambda:us-east-1:123456789012:function:unknown,10
+Tax,2021-06-01T00:00:00Z,2021-07-01T10:00:00Z,AWSLambda,USE1-Request,arn:aws:lambda:us-east-1:123456789012:function:api,10
+`
+
+const testCloudWatchJSONFile = `
+{"namespace":"AWS/DynamoDB","metric_name":"ConsumedReadCapacityUnits","dimensions":{"TableName":"orders"},"timestamp":1622505600000,"value":{"sum":1000,"count":60}}
+{"namespace":"AWS/DynamoDB","metric_name":"ConsumedReadCapacityUnits","dimensions":{"TableName":"orders"},"timestamp":1622507400000,"value":{"sum":3000,"count":60}}
+{"namespace":"AWS/Lambda","metric_name":"Duration","dimensions":{"FunctionName":"api"},"timestamp":1622505600000,"value":{"sum":30000,"count":100}}
+{"namespace":"AWS/Lambda","metric_name":"Duration","dimensions":{"FunctionName":"api"},"timestamp":1622507400000,"value":{"sum":10000,"count":100}}
+`
+
+const testCloudWatchCSVFile = `namespace,metric_name,dimensions,timestamp,sum,count
+AWS/S3,BucketSizeBytes,BucketName=my-bucket;StorageType=StandardIAStorage,2021-06-01T00:00:00Z,10737418240,1
+AWS/S3,BucketSizeBytes,BucketName=my-bucket;StorageType=StandardIAStorage,2021-06-02T00:00:00Z,32212254720,1
+AWS/S3,BucketSizeBytes,BucketName=my-bucket;StorageType=StandardStorage,2021-06-02T00:00:00Z,1,1
+`
+
+var testImportResources = []*schema.Resource{
+	{Name: "aws_lambda_function.api", ResourceType: "aws_lambda_function", Identifiers: []string{"api"}},
+	{Name: "aws_s3_bucket.bucket", ResourceType: "aws_s3_bucket", Identifiers: []string{"my-bucket"}},
+	{Name: "aws_nat_gateway.nat", ResourceType: "aws_nat_gateway", Identifiers: []string{"nat-0abc"}},
+	{Name: "aws_dynamodb_table.orders", ResourceType: "aws_dynamodb_table", Identifiers: []string{"orders", "arn:aws:dynamodb:us-east-1:123456789012:table/orders"}},
+}
+
+func writeImportFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	require.NoError(t, err)
+
+	return path
+}
+
+func TestImportFromFilesCUR(t *testing.T) {
+	result, err := ImportFromFiles([]string{writeImportFile(t, "cur.csv", testCURFile)}, testImportResources)
+	require.NoError(t, err)
+
+	// The report covers 730 hours, so is exactly a month
+	assert.Equal(t, map[string]map[string]float64{
+		"aws_lambda_function.api": {"monthly_requests": 2000000},
+		"aws_s3_bucket.bucket":    {"standard.storage_gb": 250, "standard.monthly_tier_1_requests": 4000},
+		"aws_nat_gateway.nat":     {"monthly_data_processed_gb": 50},
+	}, result.Usage)
+	assert.Equal(t, []string{"arn:aws:lambda:us-east-1:123456789012:function:unknown"}, result.UnmatchedIDs)
+}
+
+func TestImportFromFilesCloudWatch(t *testing.T) {
+	result, err := ImportFromFiles([]string{
+		writeImportFile(t, "metrics.json", testCloudWatchJSONFile),
+		writeImportFile(t, "metrics.csv", testCloudWatchCSVFile),
+	}, testImportResources)
+	require.NoError(t, err)
+
+	// The data points are every 30 minutes, so the JSON file covers an hour
+	assert.InDelta(t, 4000*730, result.Usage["aws_dynamodb_table.orders"]["monthly_read_request_units"], 0.001)
+	assert.InDelta(t, 200, result.Usage["aws_lambda_function.api"]["request_duration_ms"], 0.001)
+	assert.InDelta(t, 20, result.Usage["aws_s3_bucket.bucket"]["standard_infrequent_access.storage_gb"], 0.001)
+	assert.Empty(t, result.UnmatchedIDs)
+}
+
+func TestImportFromFilesCloudFormation(t *testing.T) {
+	resources := []*schema.Resource{
+		{Name: "Function", ResourceType: "AWS::Lambda::Function", Identifiers: []string{"api"}},
+		{Name: "Bucket", ResourceType: "AWS::S3::Bucket", Identifiers: []string{"my-bucket"}},
+	}
+
+	result, err := ImportFromFiles([]string{writeImportFile(t, "cur.csv", testCURFile)}, resources)
+	require.NoError(t, err)
+
+	// S3 buckets from CloudFormation don't have a usage schema so they aren't matched
+	assert.Equal(t, map[string]map[string]float64{
+		"Function": {"monthly_requests": 2000000},
+	}, result.Usage)
+	assert.Contains(t, result.UnmatchedIDs, "my-bucket")
+}
+
+func TestImportFromFilesLastFileWins(t *testing.T) {
+	cloudWatch := `namespace,metric_name,dimensions,timestamp,sum,count
+AWS/Lambda,Invocations,FunctionName=api,2021-06-01T00:00:00Z,500000,1
+`
+
+	result, err := ImportFromFiles([]string{
+		writeImportFile(t, "cur.csv", testCURFile),
+		writeImportFile(t, "metrics.csv", cloudWatch),
+	}, testImportResources)
+	require.NoError(t, err)
+
+	// Both files have the Lambda requests, so the values aren't added together
+	assert.InDelta(t, 500000, result.Usage["aws_lambda_function.api"]["monthly_requests"], 0.001)
+	assert.InDelta(t, 250, result.Usage["aws_s3_bucket.bucket"]["standard.storage_gb"], 0.001)
+
+	result, err = ImportFromFiles([]string{
+		writeImportFile(t, "metrics.csv", cloudWatch),
+		writeImportFile(t, "cur.csv", testCURFile),
+	}, testImportResources)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2000000, result.Usage["aws_lambda_function.api"]["monthly_requests"], 0.001)
+}
+
+func TestImportFromFilesUnknownFormat(t *testing.T) {
+	_, err := ImportFromFiles([]string{writeImportFile(t, "other.csv", "a,b\n1,2\n")}, testImportResources)
+	assert.Error(t, err)
+}
+
+func TestImportUsageData(t *testing.T) {
+	path := writeImportFile(t, "infracost-usage.yml", `
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests: 10
+    request_duration_ms: 300
+`)
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.Resources = testImportResources
+
+	err := ImportUsageData(project, path, map[string]map[string]float64{
+		"aws_lambda_function.api": {"monthly_requests": 2000000.4},
+		"aws_nat_gateway.nat":     {"monthly_data_processed_gb": 50},
+	})
+	require.NoError(t, err)
+
+	u, err := LoadFromFile(path, "", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2000000), u["aws_lambda_function.api"].Get("monthly_requests").Int())
+	assert.Equal(t, int64(300), u["aws_lambda_function.api"].Get("request_duration_ms").Int())
+	assert.Equal(t, int64(50), u["aws_nat_gateway.nat"].Get("monthly_data_processed_gb").Int())
+}
@@ -0,0 +1,415 @@
+package usage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SyncOptions are the options for syncing a usage file with the resources of a project.
+type SyncOptions struct {
+	// DryRun works out what would change without writing the usage file.
+	DryRun bool
+	// Prune removes the entries for resources that aren't in the project. Defaults,
+	// patterns and `[*]` entries that match resources are never removed.
+	Prune bool
+}
+
+// SyncResult is what happened to each of the entries in the resource_usage section of
+// the usage file when it was synced.
+type SyncResult struct {
+	Added   []string
+	Updated []string
+	Removed []string
+	Kept    []string
+	// Stale are the entries for resources that aren't in the project. They are kept
+	// unless the sync prunes them, in which case they are in Removed instead.
+	Stale []string
+	// UpgradedFrom is the version of the usage file if the sync upgraded it to the
+	// latest version, otherwise it's empty.
+	UpgradedFrom string
+}
+
+// HasChanges returns true if the sync changed any entries or upgraded the usage file.
+func (r *SyncResult) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0 || len(r.Removed) > 0 || r.UpgradedFrom != ""
+}
+
+// Summary returns a summary of the number of entries in each state, e.g. "2 added, 1
+// updated, 0 removed, 5 kept".
+func (r *SyncResult) Summary() string {
+	return fmt.Sprintf("%d added, %d updated, %d removed, %d kept", len(r.Added), len(r.Updated), len(r.Removed), len(r.Kept)+len(r.Stale))
+}
+
+// SyncUsageData adds any missing resources and usage keys of the project to the usage
+// file. The file is edited in place so its comments, ordering and existing values,
+// including expressions, ranges, defaults and profiles, are kept as they are. If the
+// usage file is shared by other projects SyncProjectsUsageData should be used instead,
+// otherwise their entries are stale and would be pruned.
+func SyncUsageData(project *schema.Project, usageFilePath string, opts SyncOptions) (*SyncResult, error) {
+	return syncUsageFile([]*schema.Project{project}, usageFilePath, nil, opts)
+}
+
+// SyncProjectsUsageData syncs the usage file like SyncUsageData with the resources of
+// all the projects that use it. An entry is only stale, and removed when pruning, if
+// it isn't for a resource in any of the projects.
+func SyncProjectsUsageData(projects []*schema.Project, usageFilePath string, opts SyncOptions) (*SyncResult, error) {
+	return syncUsageFile(projects, usageFilePath, nil, opts)
+}
+
+// ImportUsageData syncs the usage file like SyncUsageData, and sets the values of the
+// imported usage, which is keyed by the resource address and then the usage key.
+func ImportUsageData(project *schema.Project, usageFilePath string, imported map[string]map[string]float64) error {
+	_, err := syncUsageFile([]*schema.Project{project}, usageFilePath, imported, SyncOptions{})
+	return err
+}
+
+func syncUsageFile(projects []*schema.Project, usageFilePath string, imported map[string]map[string]float64, opts SyncOptions) (*SyncResult, error) {
+	result := &SyncResult{}
+
+	if usageFilePath == "" {
+		return result, nil
+	}
+
+	usageSchema, err := loadUsageSchema()
+	if err != nil {
+		return result, err
+	}
+
+	doc, err := readUsageFileNode(usageFilePath)
+	if err != nil {
+		return result, err
+	}
+	root := doc.Content[0]
+
+	if v := mappingValue(root, "version"); v != nil {
+		if v.Value != maxUsageFileVersion {
+			result.UpgradedFrom = v.Value
+			if opts.DryRun {
+				log.Infof("Usage file %s would be upgraded from version %s to %s", usageFilePath, v.Value, maxUsageFileVersion)
+			} else {
+				log.Infof("Upgrading usage file %s from version %s to %s", usageFilePath, v.Value, maxUsageFileVersion)
+			}
+		}
+		v.Tag = "!!str"
+		v.Style = yaml.DoubleQuotedStyle
+		v.Value = maxUsageFileVersion
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: maxUsageFileVersion},
+		}, root.Content...)
+	}
+
+	resourceUsageNode := mappingValue(root, "resource_usage")
+	if resourceUsageNode == nil {
+		resourceUsageNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "resource_usage"}, resourceUsageNode)
+	}
+	if !toMappingNode(resourceUsageNode) {
+		return result, errors.New("Error parsing usage YAML: resource_usage should be a map")
+	}
+
+	var defaults, resourceUsage map[string]interface{}
+	if n := mappingValue(root, "defaults"); n != nil {
+		err = n.Decode(&defaults)
+		if err != nil {
+			return result, errors.Wrap(err, "Error parsing usage YAML")
+		}
+	}
+	err = resourceUsageNode.Decode(&resourceUsage)
+	if err != nil {
+		return result, errors.Wrap(err, "Error parsing usage YAML")
+	}
+
+	resources := make([]*schema.Resource, 0)
+	for _, p := range projects {
+		resources = append(resources, p.Resources...)
+	}
+
+	resourceNames := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		resourceNames[r.Name] = true
+	}
+
+	// Check which entries are stale before adding any, so the new ones aren't counted
+	existing := make(map[string]bool)
+	for i := 0; i < len(resourceUsageNode.Content)-1; i += 2 {
+		addr := resourceUsageNode.Content[i].Value
+		existing[addr] = true
+
+		if !isStaleUsageEntry(addr, resourceNames, resources) {
+			continue
+		}
+
+		if opts.Prune {
+			resourceUsageNode.Content = append(resourceUsageNode.Content[:i], resourceUsageNode.Content[i+2:]...)
+			i -= 2
+			result.Removed = append(result.Removed, addr)
+		} else {
+			result.Stale = append(result.Stale, addr)
+		}
+	}
+
+	synced := syncResourcesUsage(resources, usageSchema, inheritedUsageData(defaults, resourceUsage), imported)
+
+	addrs := make([]string, 0, len(synced))
+	for addr := range synced {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	updated := make(map[string]bool)
+
+	for _, addr := range addrs {
+		values := synced[addr]
+
+		if !existing[addr] {
+			// An entry for an element of an array would replace the `[*]` entry of the
+			// array, so don't add one if there is a `[*]` entry
+			if len(values) == 0 || existing[wildcardAddress(addr)] {
+				continue
+			}
+
+			node := &yaml.Node{}
+			err = node.Encode(values)
+			if err != nil {
+				return result, err
+			}
+			insertMappingValue(resourceUsageNode, addr, node)
+			result.Added = append(result.Added, addr)
+			continue
+		}
+
+		changed, err := mergeUsageNode(mappingValue(resourceUsageNode, addr), values, imported[addr])
+		if err != nil {
+			return result, err
+		}
+		if changed {
+			updated[addr] = true
+			result.Updated = append(result.Updated, addr)
+		}
+	}
+
+	stale := make(map[string]bool, len(result.Stale))
+	for _, addr := range result.Stale {
+		stale[addr] = true
+	}
+
+	for i := 0; i < len(resourceUsageNode.Content)-1; i += 2 {
+		addr := resourceUsageNode.Content[i].Value
+		if existing[addr] && !updated[addr] && !stale[addr] {
+			result.Kept = append(result.Kept, addr)
+		}
+	}
+	sort.Strings(result.Kept)
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	err = enc.Encode(doc)
+	if err != nil {
+		return result, err
+	}
+	err = enc.Close()
+	if err != nil {
+		return result, err
+	}
+
+	err = ioutil.WriteFile(usageFilePath, buf.Bytes(), 0600)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// readUsageFileNode reads the usage file as a YAML node, so it can be edited without
+// losing the comments and ordering. A missing or empty file is read as an empty map.
+func readUsageFileNode(usageFilePath string) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+
+	out, err := ioutil.ReadFile(usageFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return doc, errors.Wrapf(err, "Error reading usage file")
+	}
+
+	if err == nil {
+		err = yaml.Unmarshal(out, doc)
+		if err != nil {
+			return doc, errors.Wrap(err, "Error parsing usage YAML")
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc = &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	if len(doc.Content) == 0 || !toMappingNode(doc.Content[0]) {
+		return doc, errors.New("Error parsing usage YAML: the usage file should be a map")
+	}
+
+	return doc, nil
+}
+
+// inheritedUsageData returns the usage that resources get from the defaults, patterns
+// and `[*]` entries, i.e. everything apart from the entries for specific resources.
+func inheritedUsageData(defaults map[string]interface{}, resourceUsage map[string]interface{}) map[string]*schema.UsageData {
+	inherited := make(map[string]interface{})
+	for k, v := range resourceUsage {
+		if schema.IsUsagePattern(k) || strings.HasSuffix(k, "[*]") {
+			inherited[k] = v
+		}
+	}
+
+	usageData := schema.NewUsageMap(normalizeMap(inherited))
+
+	for resourceType, ud := range schema.NewUsageMap(normalizeMap(defaults)) {
+		ud.Address = schema.UsageDefaultsKey(resourceType)
+		usageData[ud.Address] = ud
+	}
+
+	return usageData
+}
+
+// isStaleUsageEntry returns true if the entry is for a resource that isn't in the
+// project, or is a `[*]` entry that doesn't match any of its resources. Patterns are
+// never stale since they can be shared by projects.
+func isStaleUsageEntry(addr string, resourceNames map[string]bool, resources []*schema.Resource) bool {
+	switch {
+	case schema.IsUsagePattern(addr):
+		return false
+	case strings.HasSuffix(addr, "[*]"):
+		return len(matchWildcard(strings.TrimSuffix(addr, "[*]"), resources)) == 0
+	}
+
+	return !resourceNames[addr]
+}
+
+// wildcardAddress returns the `[*]` address for an element of an array, e.g.
+// aws_instance.web[*] for aws_instance.web[0], or an empty string if it isn't one.
+func wildcardAddress(addr string) string {
+	if !strings.HasSuffix(addr, "]") {
+		return ""
+	}
+
+	return addr[:strings.LastIndex(addr, "[")] + "[*]"
+}
+
+// mergeUsageNode adds the values that are missing from the node. Existing values are
+// kept as they are written, apart from the imported values which replace them.
+func mergeUsageNode(node *yaml.Node, values map[string]interface{}, imported map[string]float64) (bool, error) {
+	if !toMappingNode(node) {
+		return false, nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	changed := false
+
+	for _, k := range keys {
+		v := values[k]
+		existing := mappingValue(node, k)
+
+		if m, ok := v.(map[string]interface{}); ok && existing != nil {
+			c, err := mergeUsageNode(existing, m, nil)
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || c
+			continue
+		}
+
+		if _, ok := imported[k]; existing != nil && !ok {
+			continue
+		}
+
+		n := &yaml.Node{}
+		err := n.Encode(v)
+		if err != nil {
+			return changed, err
+		}
+
+		if existing == nil {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, n)
+			changed = true
+			continue
+		}
+
+		if existing.Kind != n.Kind || existing.Value != n.Value {
+			n.LineComment = existing.LineComment
+			*existing = *n
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// toMappingNode converts a null node to an empty map and returns true if the node is a
+// map. Empty maps are changed to the block style so values can be added to them.
+func toMappingNode(node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		node.Kind = yaml.MappingNode
+		node.Tag = "!!map"
+		node.Value = ""
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	if len(node.Content) == 0 {
+		node.Style = 0
+	}
+
+	return true
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// insertMappingValue inserts the key before the first key that sorts after it, so new
+// entries are added in order if the existing entries are sorted.
+func insertMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	i := 0
+	for ; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value > key {
+			break
+		}
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+
+	content := make([]*yaml.Node, 0, len(node.Content)+2)
+	content = append(content, node.Content[:i]...)
+	content = append(content, keyNode, value)
+	content = append(content, node.Content[i:]...)
+	node.Content = content
+}
@@ -0,0 +1,187 @@
+package usage
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSyncUsageFile = `# Usage for the production account
+version: 0.1
+resource_usage:
+  # The API gets most of the traffic
+  aws_lambda_function.api:
+    monthly_requests: 2000000 # from CloudWatch
+    request_duration_ms: 300
+  aws_lambda_function.workers[*]:
+    monthly_requests: 1000
+  aws_sqs_queue.old:
+    monthly_requests: 10
+  module.*.aws_lambda_function.*:
+    request_duration_ms: 600
+`
+
+func writeSyncFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "infracost-usage.yml")
+	err := ioutil.WriteFile(path, []byte(contents), 0600)
+	require.NoError(t, err)
+
+	return path
+}
+
+func testSyncProject() *schema.Project {
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.Resources = []*schema.Resource{
+		{Name: "aws_lambda_function.api", ResourceType: "aws_lambda_function"},
+		{Name: "aws_lambda_function.workers[0]", ResourceType: "aws_lambda_function"},
+		{Name: "aws_sqs_queue.new", ResourceType: "aws_sqs_queue"},
+		{Name: "module.jobs.aws_lambda_function.this", ResourceType: "aws_lambda_function"},
+	}
+
+	return project
+}
+
+func TestSyncUsageDataKeepsCommentsAndOrder(t *testing.T) {
+	path := writeSyncFile(t, testSyncUsageFile)
+
+	result, err := SyncUsageData(testSyncProject(), path, SyncOptions{})
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Usage for the production account
+version: "0.2"
+resource_usage:
+  # The API gets most of the traffic
+  aws_lambda_function.api:
+    monthly_requests: 2000000 # from CloudWatch
+    request_duration_ms: 300
+  aws_lambda_function.workers[*]:
+    monthly_requests: 1000
+  aws_sqs_queue.new:
+    monthly_requests: 0
+    request_size_kb: 0
+  aws_sqs_queue.old:
+    monthly_requests: 10
+  module.*.aws_lambda_function.*:
+    request_duration_ms: 600
+  module.jobs.aws_lambda_function.this:
+    monthly_requests: 0
+`, string(out))
+
+	assert.Equal(t, []string{"aws_sqs_queue.new", "module.jobs.aws_lambda_function.this"}, result.Added)
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.Removed)
+	assert.Equal(t, []string{"aws_lambda_function.api", "aws_lambda_function.workers[*]", "module.*.aws_lambda_function.*"}, result.Kept)
+	assert.Equal(t, []string{"aws_sqs_queue.old"}, result.Stale)
+
+	// Syncing again doesn't change anything
+	result, err = SyncUsageData(testSyncProject(), path, SyncOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.HasChanges())
+
+	again, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestSyncUsageDataDryRun(t *testing.T) {
+	path := writeSyncFile(t, testSyncUsageFile)
+
+	result, err := SyncUsageData(testSyncProject(), path, SyncOptions{DryRun: true, Prune: true})
+	require.NoError(t, err)
+
+	assert.True(t, result.HasChanges())
+	assert.Equal(t, []string{"aws_sqs_queue.new", "module.jobs.aws_lambda_function.this"}, result.Added)
+	assert.Equal(t, []string{"aws_sqs_queue.old"}, result.Removed)
+	assert.Empty(t, result.Stale)
+	assert.Equal(t, "2 added, 0 updated, 1 removed, 3 kept", result.Summary())
+	assert.Equal(t, "0.1", result.UpgradedFrom)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testSyncUsageFile, string(out))
+}
+
+func TestSyncUsageDataPrune(t *testing.T) {
+	path := writeSyncFile(t, testSyncUsageFile)
+
+	project := testSyncProject()
+	project.Resources = project.Resources[:1]
+
+	result, err := SyncUsageData(project, path, SyncOptions{Prune: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"aws_lambda_function.workers[*]", "aws_sqs_queue.old"}, result.Removed)
+	assert.Equal(t, []string{"aws_lambda_function.api", "module.*.aws_lambda_function.*"}, result.Kept)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(out), "aws_sqs_queue.old")
+	assert.NotContains(t, string(out), "aws_lambda_function.workers")
+	assert.Contains(t, string(out), "module.*.aws_lambda_function.*:")
+	assert.Contains(t, string(out), "# The API gets most of the traffic")
+}
+
+func TestSyncProjectsUsageDataPruneKeepsOtherProjects(t *testing.T) {
+	path := writeSyncFile(t, testSyncUsageFile)
+
+	api := schema.NewProject("api", &schema.ProjectMetadata{})
+	api.Resources = []*schema.Resource{
+		{Name: "aws_lambda_function.api", ResourceType: "aws_lambda_function"},
+	}
+	queues := schema.NewProject("queues", &schema.ProjectMetadata{})
+	queues.Resources = []*schema.Resource{
+		{Name: "aws_sqs_queue.old", ResourceType: "aws_sqs_queue"},
+	}
+
+	result, err := SyncProjectsUsageData([]*schema.Project{api, queues}, path, SyncOptions{Prune: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"aws_lambda_function.workers[*]"}, result.Removed)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "aws_sqs_queue.old:")
+	assert.NotContains(t, string(out), "aws_lambda_function.workers")
+}
+
+func TestSyncUsageDataUpdatesMissingKeys(t *testing.T) {
+	path := writeSyncFile(t, `version: 0.2
+resource_usage: {}
+`)
+
+	project := testSyncProject()
+	project.Resources = project.Resources[2:3]
+
+	result, err := SyncUsageData(project, path, SyncOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws_sqs_queue.new"}, result.Added)
+
+	err = ioutil.WriteFile(path, []byte(`version: 0.2
+resource_usage:
+  aws_sqs_queue.new:
+    monthly_requests: 10 # estimate
+`), 0600)
+	require.NoError(t, err)
+
+	result, err = SyncUsageData(project, path, SyncOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws_sqs_queue.new"}, result.Updated)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `version: "0.2"
+resource_usage:
+  aws_sqs_queue.new:
+    monthly_requests: 10 # estimate
+    request_size_kb: 0
+`, string(out))
+}
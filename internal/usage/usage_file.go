@@ -3,6 +3,7 @@ package usage
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -16,11 +17,23 @@ import (
 )
 
 const minUsageFileVersion = "0.1"
-const maxUsageFileVersion = "0.1"
+const maxUsageFileVersion = "0.2"
 
+// UsageFile is the usage file. Defaults are keyed by resource type and apply to every
+// resource of that type. Resource usage is keyed by resource address or by a wildcard
+// pattern. Profiles override the defaults and resource usage when they are selected.
 type UsageFile struct { // nolint:golint
-	Version       string                 `yaml:"version"`
-	ResourceUsage map[string]interface{} `yaml:"resource_usage"`
+	Version       string                   `yaml:"version"`
+	Defaults      map[string]interface{}   `yaml:"defaults,omitempty"`
+	ResourceUsage map[string]interface{}   `yaml:"resource_usage"`
+	Profiles      map[string]*UsageProfile `yaml:"profiles,omitempty"`
+}
+
+// UsageProfile is a named set of usage, e.g. for a dev or prod environment, that is
+// merged on top of the rest of the usage file when it is selected.
+type UsageProfile struct {
+	Defaults      map[string]interface{} `yaml:"defaults,omitempty"`
+	ResourceUsage map[string]interface{} `yaml:"resource_usage,omitempty"`
 }
 
 type SchemaItem struct {
@@ -29,34 +42,13 @@ type SchemaItem struct {
 	DefaultValue interface{}
 }
 
-func SyncUsageData(project *schema.Project, existingUsageData map[string]*schema.UsageData, usageFilePath string) error {
-	if usageFilePath == "" {
-		return nil
-	}
-	usageSchema, err := loadUsageSchema()
-	if err != nil {
-		return err
-	}
-	syncedResourcesUsage := syncResourcesUsage(project.Resources, usageSchema, existingUsageData)
-	// yaml.MapSlice is used to maintain the order of keys, so re-running
-	// the code won't change the output.
-	syncedUsageData := yaml.MapSlice{
-		{Key: "version", Value: 0.1},
-		{Key: "resource_usage", Value: syncedResourcesUsage},
-	}
-	d, err := yaml.Marshal(syncedUsageData)
-	if err != nil {
-		return err
-	}
-	err = ioutil.WriteFile(usageFilePath, d, 0600)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func syncResourcesUsage(resources []*schema.Resource, usageSchema map[string][]*SchemaItem, existingUsageData map[string]*schema.UsageData) yaml.MapSlice {
-	syncedResourceUsage := make(map[string]interface{})
+// syncResourcesUsage returns the usage values to add for each of the resources, keyed
+// by the resource address. The values are the imported values or the defaults from the
+// usage schema. Values that the resource gets from the defaults, patterns or `[*]`
+// entries in the inherited usage aren't added, so adding them doesn't override those.
+func syncResourcesUsage(resources []*schema.Resource, usageSchema map[string][]*SchemaItem, inheritedUsageData map[string]*schema.UsageData, imported map[string]map[string]float64) map[string]map[string]interface{} {
+	syncedResourceUsage := make(map[string]map[string]interface{})
+	inheritedUsageIndex := schema.NewUsageIndex(inheritedUsageData)
 	for _, resource := range resources {
 		resourceName := resource.Name
 		resourceUSchema := resource.UsageSchema
@@ -85,30 +77,30 @@ func syncResourcesUsage(resources []*schema.Resource, usageSchema map[string][]*
 			}
 		}
 
+		// Defaults only apply by the resource's type, the same as when the usage is
+		// priced, so a resource without one always gets its own usage keys.
+		inheritedUsage := inheritedUsageIndex.Find(resourceName, resource.ResourceType)
+
 		resourceUsage := make(map[string]interface{})
 		for _, usageSchemaItem := range resourceUSchema {
 			usageKey := usageSchemaItem.Key
 			usageValueType := usageSchemaItem.ValueType
-			var usageValue interface{}
-			usageValue = usageSchemaItem.DefaultValue
-			if existingUsage, ok := existingUsageData[resourceName]; ok {
-				switch usageValueType {
-				case schema.Float64:
-					usageValue = existingUsage.Get(usageKey).Float()
-				case schema.Int64:
-					usageValue = existingUsage.Get(usageKey).Int()
-				case schema.String:
-					usageValue = existingUsage.Get(usageKey).String()
+			if importedValue, ok := imported[resourceName][usageKey]; ok && usageValueType != schema.String {
+				if usageValueType == schema.Int64 {
+					resourceUsage[usageKey] = int64(math.Round(importedValue))
+				} else {
+					resourceUsage[usageKey] = math.Round(importedValue*100) / 100
 				}
+				continue
 			}
-			resourceUsage[usageKey] = usageValue
+			if inheritedUsage != nil && inheritedUsage.Get(usageKey).Exists() {
+				continue
+			}
+			resourceUsage[usageKey] = usageSchemaItem.DefaultValue
 		}
 		syncedResourceUsage[resourceName] = unFlattenHelper(resourceUsage)
 	}
-	// yaml.MapSlice is used to maintain the order of keys, so re-running
-	// the code won't change the output.
-	result := mapToSortedMapSlice(syncedResourceUsage)
-	return result
+	return syncedResourceUsage
 }
 
 func loadUsageSchema() (map[string][]*SchemaItem, error) {
@@ -158,36 +150,20 @@ func unFlattenHelper(input map[string]interface{}) map[string]interface{} {
 	return result
 }
 
-func mapToSortedMapSlice(input map[string]interface{}) yaml.MapSlice {
-	result := make(yaml.MapSlice, 0)
-	// sort keys of the input to maintain same output for different runs.
-	keys := make([]string, 0)
-	for k := range input {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	// Iterate over sorted keys
-	for _, k := range keys {
-		v := input[k]
-		if casted, ok := v.(map[string]interface{}); ok {
-			result = append(result, yaml.MapItem{Key: k, Value: mapToSortedMapSlice(casted)})
-		} else {
-			result = append(result, yaml.MapItem{Key: k, Value: v})
-		}
-	}
-	return result
-}
-
 func loadReferenceFile() (map[string]*schema.UsageData, error) {
 	referenceUsageFileContents := infracost.GetReferenceUsageFileContents()
-	usageData, err := parseYAML(*referenceUsageFileContents)
+	usageData, err := parseYAML(*referenceUsageFileContents, "")
 	if err != nil {
 		return usageData, errors.Wrapf(err, "Error parsing usage file")
 	}
 	return usageData, nil
 }
 
-func LoadFromFile(usageFilePath string, createIfNotExisting bool) (map[string]*schema.UsageData, error) {
+// LoadFromFile loads the usage data from the usage file, creating an empty usage file if
+// it doesn't exist and createIfNotExisting is true. If a profile is given then its usage
+// is merged on top of the rest of the file. Older versions of the usage file are
+// migrated when they are loaded.
+func LoadFromFile(usageFilePath string, profile string, createIfNotExisting bool) (map[string]*schema.UsageData, error) {
 	usageData := make(map[string]*schema.UsageData)
 
 	if usageFilePath == "" {
@@ -198,7 +174,7 @@ func LoadFromFile(usageFilePath string, createIfNotExisting bool) (map[string]*s
 		if _, err := os.Stat(usageFilePath); os.IsNotExist(err) {
 			log.Debug("Specified usage file does not exist. It will be created")
 			fileContent := yaml.MapSlice{
-				{Key: "version", Value: "0.1"},
+				{Key: "version", Value: maxUsageFileVersion},
 				{Key: "resource_usage", Value: make(map[string]interface{})},
 			}
 			d, err := yaml.Marshal(fileContent)
@@ -219,7 +195,7 @@ func LoadFromFile(usageFilePath string, createIfNotExisting bool) (map[string]*s
 		return usageData, errors.Wrapf(err, "Error reading usage file")
 	}
 
-	usageData, err = parseYAML(out)
+	usageData, err = parseYAML(out, profile)
 	if err != nil {
 		return usageData, errors.Wrapf(err, "Error parsing usage file")
 	}
@@ -227,7 +203,7 @@ func LoadFromFile(usageFilePath string, createIfNotExisting bool) (map[string]*s
 	return usageData, nil
 }
 
-func parseYAML(y []byte) (map[string]*schema.UsageData, error) {
+func parseYAML(y []byte, profile string) (map[string]*schema.UsageData, error) {
 	var usageFile UsageFile
 
 	err := yaml.Unmarshal(y, &usageFile)
@@ -239,11 +215,104 @@ func parseYAML(y []byte) (map[string]*schema.UsageData, error) {
 		return map[string]*schema.UsageData{}, fmt.Errorf("Invalid usage file version. Supported versions are %s ≤ x ≤ %s", minUsageFileVersion, maxUsageFileVersion)
 	}
 
-	usageMap := schema.NewUsageMap(usageFile.ResourceUsage)
+	migrateUsageFile(&usageFile)
+
+	defaults := normalizeMap(usageFile.Defaults)
+	resourceUsage := normalizeMap(usageFile.ResourceUsage)
+
+	if profile != "" {
+		p, ok := usageFile.Profiles[profile]
+		if !ok {
+			return map[string]*schema.UsageData{}, fmt.Errorf("Usage profile %s not found. Available profiles are: %s", profile, strings.Join(profileNames(usageFile.Profiles), ", "))
+		}
+
+		if p != nil {
+			mergeMaps(defaults, normalizeMap(p.Defaults))
+			mergeMaps(resourceUsage, normalizeMap(p.ResourceUsage))
+		}
+	}
+
+	err = evaluateExpressions(defaults, resourceUsage)
+	if err != nil {
+		return map[string]*schema.UsageData{}, err
+	}
+
+	usageMap := schema.NewUsageMap(resourceUsage)
+
+	for resourceType, ud := range schema.NewUsageMap(defaults) {
+		ud.Address = schema.UsageDefaultsKey(resourceType)
+		usageMap[ud.Address] = ud
+	}
 
 	return usageMap, nil
 }
 
+// migrateUsageFile upgrades the usage file to the latest version. Version 0.2 only added
+// the defaults and profiles, so a 0.1 file is the same as a 0.2 file without them.
+func migrateUsageFile(usageFile *UsageFile) {
+	if usageFile.Version == "0.1" {
+		usageFile.Version = "0.2"
+	}
+}
+
+func profileNames(profiles map[string]*UsageProfile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// normalizeMap converts the nested maps that the YAML parser returns to maps with
+// string keys, so they can be merged and converted to JSON.
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = normalizeValue(v)
+	}
+
+	return result
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[fmt.Sprintf("%v", k)] = normalizeValue(child)
+		}
+		return m
+	case map[string]interface{}:
+		return normalizeMap(t)
+	case []interface{}:
+		l := make([]interface{}, 0, len(t))
+		for _, child := range t {
+			l = append(l, normalizeValue(child))
+		}
+		return l
+	}
+
+	return v
+}
+
+// mergeMaps merges src into dst. Nested maps are merged and any other values in src
+// replace the values in dst.
+func mergeMaps(dst map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := dst[k].(map[string]interface{})
+
+		if srcOK && dstOK {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+
+		dst[k] = v
+	}
+}
+
 func checkVersion(v string) bool {
 	if !strings.HasPrefix(v, "v") {
 		v = "v" + v
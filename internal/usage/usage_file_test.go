@@ -0,0 +1,333 @@
+package usage
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProfilesUsageFile = `
+version: 0.2
+defaults:
+  aws_lambda_function:
+    request_duration_ms: 300
+    monthly_requests: 1000
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests: 2000000
+  module.*.aws_lambda_function.*:
+    request_duration_ms: 600
+  module.jobs.aws_lambda_function.*:
+    request_duration_ms: 900
+  aws_sqs_queue.queue:
+    monthly_requests: ${ceil(aws_lambda_function.api.monthly_requests * 1.5)}
+  aws_dynamodb_table.table:
+    storage_gb: 10
+    monthly_read_request_units: ${monthly_write_request_units * 4}
+    monthly_write_request_units: ${storage_gb * 100}
+profiles:
+  prod:
+    defaults:
+      aws_lambda_function:
+        monthly_requests: 50000
+    resource_usage:
+      aws_lambda_function.api:
+        monthly_requests: 90000000
+`
+
+func TestParseYAMLDefaultsAndPatterns(t *testing.T) {
+	u, err := parseYAML([]byte(testProfilesUsageFile), "")
+	require.NoError(t, err)
+
+	api := schema.FindUsageData(u, "aws_lambda_function.api", "aws_lambda_function")
+	require.NotNil(t, api)
+	assert.Equal(t, int64(2000000), api.Get("monthly_requests").Int())
+	assert.Equal(t, int64(300), api.Get("request_duration_ms").Int())
+
+	other := schema.FindUsageData(u, "aws_lambda_function.other", "aws_lambda_function")
+	require.NotNil(t, other)
+	assert.Equal(t, int64(1000), other.Get("monthly_requests").Int())
+
+	inModule := schema.FindUsageData(u, "module.api.aws_lambda_function.this[0]", "aws_lambda_function")
+	require.NotNil(t, inModule)
+	assert.Equal(t, int64(600), inModule.Get("request_duration_ms").Int())
+
+	// The more specific pattern overrides the less specific one
+	inJobsModule := schema.FindUsageData(u, "module.jobs.aws_lambda_function.this", "aws_lambda_function")
+	require.NotNil(t, inJobsModule)
+	assert.Equal(t, int64(900), inJobsModule.Get("request_duration_ms").Int())
+
+	assert.Nil(t, schema.FindUsageData(u, "aws_s3_bucket.bucket", "aws_s3_bucket"))
+}
+
+func TestUsageIndex(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  module.*.aws_lambda_function.*:
+    monthly_requests: 1000
+  module.*.*:
+    request_duration_ms: 600
+  aws_instance.web["a.b*"]:
+    operating_system: windows
+`), "")
+	require.NoError(t, err)
+
+	idx := schema.NewUsageIndex(u)
+
+	lambda := idx.Find("module.api.aws_lambda_function.this", "aws_lambda_function")
+	require.NotNil(t, lambda)
+	assert.Equal(t, int64(1000), lambda.Get("monthly_requests").Int())
+	assert.Equal(t, int64(600), lambda.Get("request_duration_ms").Int())
+
+	// Patterns for other resource types are not checked, but ones for any type are
+	queue := idx.Find("module.api.aws_sqs_queue.this", "aws_sqs_queue")
+	require.NotNil(t, queue)
+	assert.False(t, queue.Get("monthly_requests").Exists())
+	assert.Equal(t, int64(600), queue.Get("request_duration_ms").Int())
+
+	// Dots in the index don't change the resource type of the pattern
+	instance := idx.Find(`aws_instance.web["a.b.c"]`, "aws_instance")
+	require.NotNil(t, instance)
+	assert.Equal(t, "windows", instance.Get("operating_system").String())
+}
+
+func TestParseYAMLExpressions(t *testing.T) {
+	u, err := parseYAML([]byte(testProfilesUsageFile), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3000000), u["aws_sqs_queue.queue"].Get("monthly_requests").Int())
+	assert.Equal(t, int64(1000), u["aws_dynamodb_table.table"].Get("monthly_write_request_units").Int())
+	assert.Equal(t, int64(4000), u["aws_dynamodb_table.table"].Get("monthly_read_request_units").Int())
+}
+
+func TestParseYAMLProfile(t *testing.T) {
+	u, err := parseYAML([]byte(testProfilesUsageFile), "prod")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(90000000), u["aws_lambda_function.api"].Get("monthly_requests").Int())
+	assert.Equal(t, int64(50000), u[schema.UsageDefaultsKey("aws_lambda_function")].Get("monthly_requests").Int())
+	assert.Equal(t, int64(300), u[schema.UsageDefaultsKey("aws_lambda_function")].Get("request_duration_ms").Int())
+
+	// Expressions are evaluated after the profile is merged
+	assert.Equal(t, int64(135000000), u["aws_sqs_queue.queue"].Get("monthly_requests").Int())
+
+	_, err = parseYAML([]byte(testProfilesUsageFile), "staging")
+	assert.EqualError(t, err, "Usage profile staging not found. Available profiles are: prod")
+}
+
+func TestParseYAMLInvalidExpression(t *testing.T) {
+	_, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests: ${missing * 2}
+`), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Error evaluating aws_lambda_function.api.monthly_requests")
+
+	_, err = parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests: ${request_duration_ms}
+    request_duration_ms: ${monthly_requests}
+`), "")
+	require.Error(t, err)
+}
+
+func TestLoadFromFileV01(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost-usage.yml")
+	err := ioutil.WriteFile(path, []byte(`
+version: 0.1
+resource_usage:
+  aws_lambda_function.api[*]:
+    monthly_requests: 1000
+  aws_lambda_function.api[1]:
+    monthly_requests: 2000
+`), 0600)
+	require.NoError(t, err)
+
+	u, err := LoadFromFile(path, "", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000), schema.FindUsageData(u, "aws_lambda_function.api[0]", "aws_lambda_function").Get("monthly_requests").Int())
+	assert.Equal(t, int64(2000), schema.FindUsageData(u, "aws_lambda_function.api[1]", "aws_lambda_function").Get("monthly_requests").Int())
+}
+
+func TestSyncUsageDataKeepsExpressionsAndProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost-usage.yml")
+	err := ioutil.WriteFile(path, []byte(testProfilesUsageFile), 0600)
+	require.NoError(t, err)
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.Resources = []*schema.Resource{
+		{Name: "aws_sqs_queue.queue"},
+		{Name: "aws_lambda_function.new"},
+	}
+
+	_, err = SyncUsageData(project, path, SyncOptions{})
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "version: \"0.2\"")
+	assert.Contains(t, string(out), "monthly_requests: ${ceil(aws_lambda_function.api.monthly_requests * 1.5)}")
+	assert.Contains(t, string(out), "module.*.aws_lambda_function.*:")
+	assert.Contains(t, string(out), "aws_lambda_function.new:")
+	assert.Contains(t, string(out), "prod:")
+
+	_, err = parseYAML(out, "prod")
+	require.NoError(t, err)
+}
+
+func TestSyncUsageDataSkipsResourcesCoveredByDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost-usage.yml")
+	err := ioutil.WriteFile(path, []byte(testProfilesUsageFile), 0600)
+	require.NoError(t, err)
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.Resources = []*schema.Resource{
+		{Name: "aws_lambda_function.covered", ResourceType: "aws_lambda_function"},
+		{Name: "aws_sqs_queue.new", ResourceType: "aws_sqs_queue"},
+	}
+
+	_, err = SyncUsageData(project, path, SyncOptions{})
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(out), "aws_lambda_function.covered:")
+	assert.Contains(t, string(out), "aws_sqs_queue.new:")
+}
+
+const testRangesUsageFile = `
+version: 0.2
+defaults:
+  aws_lambda_function:
+    request_duration_ms:
+      expected: 300
+      high: 600
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests:
+      low: 1000
+      expected: 10000
+      high: 100000
+`
+
+func TestParseYAMLRanges(t *testing.T) {
+	u, err := parseYAML([]byte(testRangesUsageFile), "")
+	require.NoError(t, err)
+
+	api := schema.FindUsageData(u, "aws_lambda_function.api", "aws_lambda_function")
+	require.NotNil(t, api)
+	assert.True(t, api.HasRanges())
+	assert.Equal(t, int64(10000), api.Get("monthly_requests").Int())
+	assert.Equal(t, int64(300), api.Get("request_duration_ms").Int())
+
+	low := api.Scenario(schema.UsageLow)
+	assert.False(t, low.HasRanges())
+	assert.Equal(t, int64(1000), low.Get("monthly_requests").Int())
+	assert.Equal(t, int64(300), low.Get("request_duration_ms").Int())
+
+	high := api.Scenario(schema.UsageHigh)
+	assert.Equal(t, int64(100000), high.Get("monthly_requests").Int())
+	assert.Equal(t, int64(600), high.Get("request_duration_ms").Int())
+}
+
+func TestParseYAMLRangesWithoutExpected(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests:
+      low: 1000
+      high: 3000
+    request_duration_ms:
+      high: 600
+`), "")
+	require.NoError(t, err)
+
+	api := u["aws_lambda_function.api"]
+	assert.Equal(t, int64(2000), api.Get("monthly_requests").Int())
+	assert.Equal(t, int64(1000), api.Scenario(schema.UsageLow).Get("monthly_requests").Int())
+	assert.Equal(t, int64(3000), api.Scenario(schema.UsageHigh).Get("monthly_requests").Int())
+
+	assert.Equal(t, int64(600), api.Get("request_duration_ms").Int())
+	assert.Equal(t, int64(600), api.Scenario(schema.UsageLow).Get("request_duration_ms").Int())
+}
+
+func TestParseYAMLNestedKeysAreNotRanges(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_s3_bucket.bucket:
+    standard:
+      storage_gb: 100
+      high: 10
+    intelligent_tiering:
+      high:
+        storage_gb: 50
+`), "")
+	require.NoError(t, err)
+
+	// Only maps with just low, expected and high estimates are ranges
+	bucket := u["aws_s3_bucket.bucket"]
+	assert.False(t, bucket.HasRanges())
+	assert.Equal(t, int64(10), bucket.Get("standard.high").Int())
+	assert.Equal(t, int64(100), bucket.Get("standard.storage_gb").Int())
+	assert.Equal(t, int64(50), bucket.Get("intelligent_tiering.high.storage_gb").Int())
+}
+
+func TestParseYAMLExpressionsReferToRanges(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests:
+      low: 1000
+      expected: 10000
+      high: 100000
+  aws_sqs_queue.queue:
+    monthly_requests: ${aws_lambda_function.api.monthly_requests * 2}
+  aws_lambda_function.worker:
+    monthly_requests:
+      low: 100
+      high: 300
+    request_duration_ms: ${monthly_requests / 2}
+`), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(20000), u["aws_sqs_queue.queue"].Get("monthly_requests").Int())
+	assert.Equal(t, int64(100), u["aws_lambda_function.worker"].Get("request_duration_ms").Int())
+}
+
+func TestSyncUsageDataKeepsRanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infracost-usage.yml")
+	err := ioutil.WriteFile(path, []byte(testRangesUsageFile), 0600)
+	require.NoError(t, err)
+
+	project := schema.NewProject("test", &schema.ProjectMetadata{})
+	project.Resources = []*schema.Resource{
+		{Name: "aws_lambda_function.api"},
+	}
+
+	_, err = SyncUsageData(project, path, SyncOptions{})
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "monthly_requests:\n      low: 1000\n      expected: 10000\n      high: 100000\n")
+
+	u, err := parseYAML(out, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100000), u["aws_lambda_function.api"].Scenario(schema.UsageHigh).Get("monthly_requests").Int())
+}
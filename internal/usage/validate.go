@@ -0,0 +1,371 @@
+package usage
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/tidwall/gjson"
+)
+
+var usageKeyIndexRegex = regexp.MustCompile(`\[\d+\]`)
+
+// maxSuggestionDistance is how different an unknown key can be from a valid key for
+// the valid key to be suggested as a typo fix.
+const maxSuggestionDistance = 3
+
+// Issue is a problem with an entry in the usage file that would cause its usage to be
+// ignored, so the resource would be priced without it.
+type Issue struct {
+	Address string
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s: %s", i.Address, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", i.Address, i.Key, i.Message)
+}
+
+// Validate checks each entry in the usage data against the usage schema of the
+// resource, or the reference usage file if the resource doesn't define a schema. If
+// resources are given then the addresses are also checked against them, otherwise
+// only the keys and values are checked.
+func Validate(usageData map[string]*schema.UsageData, resources []*schema.Resource) ([]Issue, error) {
+	referenceSchema, err := loadUsageSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceMap := make(map[string]*schema.Resource, len(resources))
+	for _, r := range resources {
+		resourceMap[r.Name] = r
+	}
+
+	addrs := make([]string, 0, len(usageData))
+	for addr := range usageData {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	issues := make([]Issue, 0)
+
+	for _, addr := range addrs {
+		var matched []*schema.Resource
+
+		resourceType, isDefaults := schema.IsUsageDefaultsKey(addr)
+		if !isDefaults {
+			resourceType = resourceTypeFromAddress(addr)
+		}
+
+		if resources != nil {
+			switch {
+			case isDefaults:
+				matched = matchType(resourceType, resources)
+				if len(matched) == 0 {
+					issues = append(issues, Issue{Address: addr, Message: "no resources of this type found"})
+					continue
+				}
+			case schema.IsUsagePattern(addr):
+				matched = matchPattern(addr, resources)
+				if len(matched) == 0 {
+					issues = append(issues, Issue{Address: addr, Message: "pattern does not match any resources"})
+					continue
+				}
+			case strings.HasSuffix(addr, "[*]"):
+				matched = matchWildcard(strings.TrimSuffix(addr, "[*]"), resources)
+				if len(matched) == 0 {
+					issues = append(issues, Issue{Address: addr, Message: "wildcard does not match any resources"})
+					continue
+				}
+			default:
+				r, ok := resourceMap[addr]
+				if !ok {
+					issues = append(issues, Issue{Address: addr, Message: "resource not found"})
+					continue
+				}
+				matched = []*schema.Resource{r}
+			}
+		}
+
+		// The type of a pattern like `module.*.*` isn't known unless it matched resources
+		if strings.Contains(resourceType, "*") {
+			if len(matched) == 0 {
+				continue
+			}
+			resourceType = matched[0].ResourceType
+		}
+
+		var usageSchema []*schema.UsageSchemaItem
+		if len(matched) > 0 && matched[0].UsageSchema != nil {
+			usageSchema = matched[0].UsageSchema
+		} else if items, ok := referenceSchema[resourceType]; ok {
+			usageSchema = make([]*schema.UsageSchemaItem, 0, len(items))
+			for _, item := range items {
+				usageSchema = append(usageSchema, &schema.UsageSchemaItem{Key: item.Key, ValueType: item.ValueType})
+			}
+		}
+
+		if usageSchema == nil {
+			issues = append(issues, Issue{Address: addr, Message: "resource type does not have any usage parameters"})
+			continue
+		}
+
+		issues = append(issues, validateAttributes(addr, usageData[addr].Attributes, usageSchema)...)
+		issues = append(issues, validateRanges(addr, usageData[addr].Ranges)...)
+	}
+
+	return issues, nil
+}
+
+// ValidationSet groups the usage data and resources of projects by their usage file,
+// so a usage file that is shared by projects is validated against the resources of
+// all of them. Otherwise the entries for the other projects would be reported as not
+// found.
+type ValidationSet struct {
+	files []*validationFile
+}
+
+type validationFile struct {
+	path           string
+	profile        string
+	usageData      map[string]*schema.UsageData
+	resources      []*schema.Resource
+	checkAddresses bool
+}
+
+// FileIssues are the issues found in a usage file.
+type FileIssues struct {
+	Path   string
+	Issues []Issue
+}
+
+// Add adds the project's resources to the resources that its usage file is validated
+// against. If resources is nil, e.g. because the project doesn't have a path, the
+// addresses in the usage file are not checked.
+func (s *ValidationSet) Add(path string, profile string, usageData map[string]*schema.UsageData, resources []*schema.Resource) {
+	for _, f := range s.files {
+		if filepath.Clean(f.path) == filepath.Clean(path) && f.profile == profile {
+			f.resources = append(f.resources, resources...)
+			f.checkAddresses = f.checkAddresses && resources != nil
+			return
+		}
+	}
+
+	s.files = append(s.files, &validationFile{
+		path:           path,
+		profile:        profile,
+		usageData:      usageData,
+		resources:      resources,
+		checkAddresses: resources != nil,
+	})
+}
+
+// Validate validates each usage file once, in the order they were added.
+func (s *ValidationSet) Validate() ([]FileIssues, error) {
+	result := make([]FileIssues, 0, len(s.files))
+
+	for _, f := range s.files {
+		var resources []*schema.Resource
+		if f.checkAddresses {
+			resources = append(make([]*schema.Resource, 0, len(f.resources)), f.resources...)
+		}
+
+		issues, err := Validate(f.usageData, resources)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, FileIssues{Path: f.path, Issues: issues})
+	}
+
+	return result, nil
+}
+
+func validateAttributes(addr string, attributes map[string]gjson.Result, usageSchema []*schema.UsageSchemaItem) []Issue {
+	items := make(map[string]*schema.UsageSchemaItem, len(usageSchema))
+	for _, item := range usageSchema {
+		items[normalizeUsageKey(item.Key)] = item
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	issues := make([]Issue, 0)
+
+	for _, k := range keys {
+		item, ok := items[normalizeUsageKey(k)]
+		if !ok {
+			msg := "unknown key"
+			if s := suggestKey(k, usageSchema); s != "" {
+				msg = fmt.Sprintf("unknown key, did you mean %s?", s)
+			}
+
+			issues = append(issues, Issue{Address: addr, Key: k, Message: msg})
+			continue
+		}
+
+		v := attributes[k]
+		if v.Type == gjson.Null {
+			continue
+		}
+
+		if item.ValueType == schema.String && v.Type != gjson.String {
+			issues = append(issues, Issue{Address: addr, Key: k, Message: fmt.Sprintf("expected a string but got %s", v.Raw)})
+		} else if item.ValueType != schema.String && v.Type != gjson.Number {
+			issues = append(issues, Issue{Address: addr, Key: k, Message: fmt.Sprintf("expected a number but got %s", v.Raw)})
+		}
+	}
+
+	return issues
+}
+
+// validateRanges checks that the low and high estimates of the ranges are numbers and
+// that the low estimate isn't greater than the high estimate.
+func validateRanges(addr string, ranges map[string]schema.UsageRange) []Issue {
+	keys := make([]string, 0, len(ranges))
+	for k := range ranges {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	issues := make([]Issue, 0)
+
+	for _, k := range keys {
+		r := ranges[k]
+
+		if r.Low.Type != gjson.Number || r.High.Type != gjson.Number {
+			issues = append(issues, Issue{Address: addr, Key: k, Message: "expected the low and high estimates to be numbers"})
+			continue
+		}
+
+		if r.Low.Float() > r.High.Float() {
+			issues = append(issues, Issue{Address: addr, Key: k, Message: fmt.Sprintf("low estimate %s is greater than the high estimate %s", r.Low.Raw, r.High.Raw)})
+		}
+	}
+
+	return issues
+}
+
+// matchWildcard returns the resources that are elements of the array, e.g.
+// `aws_instance.web[0]` and `aws_instance.web["a"]` for `aws_instance.web`.
+func matchWildcard(prefix string, resources []*schema.Resource) []*schema.Resource {
+	matched := make([]*schema.Resource, 0)
+
+	for _, r := range resources {
+		if !strings.HasPrefix(r.Name, prefix+"[") {
+			continue
+		}
+
+		index := strings.TrimPrefix(r.Name, prefix)
+		if strings.Index(index, "]") == len(index)-1 {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
+
+// matchType returns the resources of the resource type.
+func matchType(resourceType string, resources []*schema.Resource) []*schema.Resource {
+	matched := make([]*schema.Resource, 0)
+
+	for _, r := range resources {
+		if r.ResourceType == resourceType {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
+
+// matchPattern returns the resources whose addresses match the wildcard pattern.
+func matchPattern(pattern string, resources []*schema.Resource) []*schema.Resource {
+	matched := make([]*schema.Resource, 0)
+	re := schema.CompileUsagePattern(pattern)
+
+	for _, r := range resources {
+		if re.MatchString(r.Name) {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
+
+// resourceTypeFromAddress returns the resource type from the address, which can include
+// module names and indexes.
+func resourceTypeFromAddress(addr string) string {
+	parts := strings.Split(strings.TrimSuffix(addr, "[*]"), ".")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[len(parts)-2]
+}
+
+// normalizeUsageKey replaces any indexes in the key with wildcards so keys for array
+// elements match their schema item, e.g. `node_pool[0].nodes` and `node_pool[*].nodes`.
+func normalizeUsageKey(key string) string {
+	return usageKeyIndexRegex.ReplaceAllString(key, "[*]")
+}
+
+func suggestKey(key string, usageSchema []*schema.UsageSchemaItem) string {
+	suggestion := ""
+	best := maxSuggestionDistance + 1
+
+	for _, item := range usageSchema {
+		d := levenshtein(normalizeUsageKey(key), normalizeUsageKey(item.Key))
+		if d < best {
+			best = d
+			suggestion = item.Key
+		}
+	}
+
+	return suggestion
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,162 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/infracost/infracost/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUsageFile = `
+version: 0.1
+resource_usage:
+  aws_lambda_function.api:
+    monthly_request: 1000000
+    request_duration_ms: fast
+  aws_lambda_function.workers[*]:
+    monthly_requests: 1000
+  aws_lambda_function.missing:
+    monthly_requests: 1000
+  aws_lambda_function.jobs[*]:
+    monthly_requests: 1000
+  google_container_cluster.cluster:
+    node_pool[1]:
+      nodes: 2
+  aws_instance.web:
+    operating_system: linux
+    reserved_instance_type: 5
+`
+
+func TestValidate(t *testing.T) {
+	u, err := parseYAML([]byte(testUsageFile), "")
+	require.NoError(t, err)
+
+	resources := []*schema.Resource{
+		{Name: "aws_lambda_function.api"},
+		{Name: "aws_lambda_function.workers[0]"},
+		{Name: "aws_lambda_function.workers[1]"},
+		{Name: "google_container_cluster.cluster"},
+		{Name: "aws_instance.web", UsageSchema: []*schema.UsageSchemaItem{
+			{Key: "operating_system", ValueType: schema.String},
+			{Key: "reserved_instance_type", ValueType: schema.String},
+		}},
+	}
+
+	issues, err := Validate(u, resources)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Issue{
+		{Address: "aws_instance.web", Key: "reserved_instance_type", Message: "expected a string but got 5"},
+		{Address: "aws_lambda_function.api", Key: "monthly_request", Message: "unknown key, did you mean monthly_requests?"},
+		{Address: "aws_lambda_function.api", Key: "request_duration_ms", Message: `expected a number but got "fast"`},
+		{Address: "aws_lambda_function.jobs[*]", Message: "wildcard does not match any resources"},
+		{Address: "aws_lambda_function.missing", Message: "resource not found"},
+	}, issues)
+}
+
+func TestValidateWithoutResources(t *testing.T) {
+	u, err := parseYAML([]byte(testUsageFile), "")
+	require.NoError(t, err)
+
+	issues, err := Validate(u, nil)
+	require.NoError(t, err)
+
+	addrs := make([]string, 0, len(issues))
+	for _, i := range issues {
+		addrs = append(addrs, i.Address)
+	}
+
+	// Addresses aren't checked without resources, only the keys and values
+	assert.NotContains(t, addrs, "aws_lambda_function.missing")
+	assert.Contains(t, addrs, "aws_lambda_function.api")
+}
+
+func TestValidatePatternsAndDefaults(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+defaults:
+  aws_lambda_function:
+    request_duration_ms: 300
+  aws_sqs_queue:
+    monthly_requests: 1000
+resource_usage:
+  module.*.aws_lambda_function.*:
+    monthly_request: 1000
+  module.*.aws_dynamodb_table.*:
+    storage_gb: 10
+`), "")
+	require.NoError(t, err)
+
+	resources := []*schema.Resource{
+		{Name: "module.api.aws_lambda_function.this", ResourceType: "aws_lambda_function"},
+	}
+
+	issues, err := Validate(u, resources)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Issue{
+		{Address: "defaults.aws_sqs_queue", Message: "no resources of this type found"},
+		{Address: "module.*.aws_dynamodb_table.*", Message: "pattern does not match any resources"},
+		{Address: "module.*.aws_lambda_function.*", Key: "monthly_request", Message: "unknown key, did you mean monthly_requests?"},
+	}, issues)
+}
+
+func TestValidateRanges(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.2
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests:
+      low: 5000
+      expected: 1000
+      high: 2000
+    request_duration_ms:
+      low: fast
+      expected: 300
+`), "")
+	require.NoError(t, err)
+
+	issues, err := Validate(u, []*schema.Resource{{Name: "aws_lambda_function.api"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Issue{
+		{Address: "aws_lambda_function.api", Key: "monthly_requests", Message: "low estimate 5000 is greater than the high estimate 2000"},
+		{Address: "aws_lambda_function.api", Key: "request_duration_ms", Message: "expected the low and high estimates to be numbers"},
+	}, issues)
+}
+
+func TestValidationSetSharedUsageFile(t *testing.T) {
+	u, err := parseYAML([]byte(`
+version: 0.1
+resource_usage:
+  aws_lambda_function.api:
+    monthly_requests: 1000
+  aws_lambda_function.worker:
+    monthly_requests: 1000
+  aws_lambda_function.missing:
+    monthly_requests: 1000
+`), "")
+	require.NoError(t, err)
+
+	var s ValidationSet
+	s.Add("infracost-usage.yml", "", u, []*schema.Resource{{Name: "aws_lambda_function.api"}})
+	s.Add("./infracost-usage.yml", "", u, []*schema.Resource{{Name: "aws_lambda_function.worker"}})
+
+	result, err := s.Validate()
+	require.NoError(t, err)
+
+	// The usage file is only validated once, against the resources of both projects
+	assert.Equal(t, []FileIssues{
+		{Path: "infracost-usage.yml", Issues: []Issue{
+			{Address: "aws_lambda_function.missing", Message: "resource not found"},
+		}},
+	}, result)
+
+	// The addresses can't be checked if one of the projects doesn't have resources
+	s.Add("infracost-usage.yml", "", u, nil)
+
+	result, err = s.Validate()
+	require.NoError(t, err)
+	assert.Equal(t, []FileIssues{{Path: "infracost-usage.yml", Issues: []Issue{}}}, result)
+}
@@ -0,0 +1,21 @@
+// Command outputschema writes the JSON Schema of the Infracost JSON output, e.g.
+//
+//	go run ./scripts/outputschema > schema/infracost.schema.json
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/infracost/infracost/internal/output"
+)
+
+func main() {
+	b, err := output.JSONSchema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	_, _ = os.Stdout.Write(b)
+}